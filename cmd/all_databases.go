@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+// backupAllDatabases discovers the databases of the server, keeps the ones matching the
+// include and exclude patterns and backs each of them up as a separate backup. A failed
+// database does not stop the run, the outcome of every database is printed as a summary.
+// With globals, the PostgresSQL roles and tablespaces are backed up first.
+//
+// Returns an error if the databases cannot be listed or a backup failed.
+func backupAllDatabases(cmd *cobra.Command, params *storage.Params, tlsOptions backup.TLSOptions, filters backup.Filters, globals bool) error {
+	var databases []string
+	var err error
+
+	if dbType == "mongodb" {
+		databases, err = mongo.ListDatabases(uri, &tlsOptions)
+	} else {
+		databases, err = sql.ListDatabases(dbType, &sql.Connection{
+			Host:     host,
+			Port:     port,
+			User:     user,
+			Password: password,
+			Database: database,
+			TLS:      &tlsOptions,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	include, _ := cmd.Flags().GetString("include-db")
+	exclude, _ := cmd.Flags().GetString("exclude-db")
+
+	databases, err = backup.FilterDatabases(dbType, databases, backup.ParsePatterns(include), backup.ParsePatterns(exclude))
+	if err != nil {
+		return err
+	}
+
+	if len(databases) == 0 {
+		return fmt.Errorf("no database matches the include and exclude patterns")
+	}
+
+	// every backup of the run shares the same timestamp, the name tells the databases apart
+	now := time.Now()
+	template := storage.PerDatabaseOutName(params.OutName)
+
+	summary := &backup.RunSummary{}
+	if globals {
+		start := time.Now()
+		err := backupGlobals(params, params.OutName, tlsOptions, now)
+		if err != nil {
+			cmd.PrintErrln(fmt.Errorf("failed to back up %s - %w", globalsName, err))
+		}
+		summary.Add(globalsName, time.Since(start), err)
+	}
+	for _, db := range databases {
+		dbParams := *params
+		dbParams.Database = db
+		dbParams.OutName = template
+
+		start := time.Now()
+		err := backupDatabase(cmd, &dbParams, tlsOptions, filters, now)
+		if err != nil {
+			cmd.PrintErrln(fmt.Errorf("failed to back up %s - %w", db, err))
+		}
+		summary.Add(db, time.Since(start), err)
+	}
+
+	if err := summary.Print(cmd.OutOrStdout()); err != nil {
+		return err
+	}
+
+	if failed := summary.Failed(); failed > 0 {
+		return fmt.Errorf("%d of %d database backups failed", failed, len(summary.Results))
+	}
+
+	return nil
+}
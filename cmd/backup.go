@@ -1,23 +1,35 @@
 package cmd
 
 import (
+	"fmt"
 	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/credentials"
 	"github.com/denisakp/sentinel/internal/storage"
 	"github.com/denisakp/sentinel/pkg/backup/mariadb_dump"
 	"github.com/denisakp/sentinel/pkg/backup/mongo_dump"
 	"github.com/denisakp/sentinel/pkg/backup/mysql_dump"
+	"github.com/denisakp/sentinel/pkg/backup/mysql_native"
+	"github.com/denisakp/sentinel/pkg/backup/pg_basebackup"
 	"github.com/denisakp/sentinel/pkg/backup/pg_dump"
+	"github.com/denisakp/sentinel/pkg/backup/redis_backup"
+	"github.com/denisakp/sentinel/pkg/backup/search_snapshot"
+	"github.com/denisakp/sentinel/pkg/backup/sqlite_backup"
 	"github.com/spf13/cobra"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
-var dbType, host, port, user, password, database,
+var dbType, host, port, user, password, passwordFile, database,
 	pgOutFormat, pgCompressionAlgo, uri,
 	output, storageType, localPath, gDriveSaFile, gDriveFolderId,
+	gDriveOAuthClient, gDriveOAuthToken, gDriveSharedDriveId,
 	awsSecretAccessKey, awsAccessKeyID, awsRegion, awsBucket, awsBucketEndpoint,
-	additionalArgs string
+	awsSSE, awsSSEKMSKeyId, awsSSECustomerKey, awsStorageClass, awsObjectLockMode,
+	jobName, envName, layout, onCollision, compressionAlgo, additionalArgs string
 var compress bool
-var pgCompressionLevel int
+var pgCompressionLevel, compressionLevel, awsObjectLockDays, gDriveChunkSize int
 var err error
 
 var BackupCmd = &cobra.Command{
@@ -39,157 +51,407 @@ var BackupCmd = &cobra.Command{
 		password, _ = cmd.Flags().GetString("password")   // get the password flag value
 		database, _ = cmd.Flags().GetString("database")   // get the database flag value
 		additionalArgs, _ = cmd.Flags().GetString("args") // get the args flag value
+		passwordFile, _ = cmd.Flags().GetString("password-file")
+
+		// resolve the password from the flag, the password file or the environment
+		if cmd.Flags().Changed("password") {
+			cmd.PrintErrln("warning: --password is visible in the shell history, prefer --password-file or " + credentials.PasswordEnv)
+		}
+		if password, err = credentials.ResolvePassword(password, passwordFile); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// resolve the MongoDB URI, which may hold the password
+		uri, _ = cmd.Flags().GetString("uri") // get the uri flag value
+		if uri, err = credentials.Resolve(uri); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// read the TLS options of the database connection
+		tlsOptions, err := readTLSFlags(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// read the table, schema and collection filters
+		filters, err := readFilterFlags(cmd, dbType)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
 
 		// storage
-		storageType, _ = cmd.Flags().GetString("storage")  // get the storage flag value
-		localPath, _ = cmd.Flags().GetString("local-path") // get the local-path flag value
-		output, _ = cmd.Flags().GetString("output")        // get the output flag value
-		// google drive
-		gDriveFolderId, _ = cmd.Flags().GetString("gdrive-folder-id")
-		gDriveSaFile, _ = cmd.Flags().GetString("gdrive-sa-file")
-		//aws s3 storage
-		awsBucket, _ = cmd.Flags().GetString("aws-bucket")
-		awsRegion, _ = cmd.Flags().GetString("aws-region")
-		awsBucketEndpoint, _ = cmd.Flags().GetString("aws-bucket-endpoint")
-		awsAccessKeyID, _ = cmd.Flags().GetString("aws-access-key-id")
-		awsSecretAccessKey, _ = cmd.Flags().GetString("aws-secret")
-
-		params := &storage.Params{
-			StorageType:          storageType,
-			LocalPath:            localPath,
-			OutName:              output,
-			GoogleServiceAccount: gDriveSaFile,
-			GoogleDriveFolderId:  gDriveFolderId,
-			AWSBucket:            awsBucket,
-			AWSRegion:            awsRegion,
-			AWSBucketEndpoint:    awsBucketEndpoint,
-			AWSAccessKeyID:       awsAccessKeyID,
-			AWSSecretAccessKey:   awsSecretAccessKey,
+		params, err := readStorageParams(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		output, _ = cmd.Flags().GetString("output") // get the output flag value
+		jobName, _ = cmd.Flags().GetString("job")
+		compressionAlgo, _ = cmd.Flags().GetString("compression")
+		compressionLevel, _ = cmd.Flags().GetInt("compression-level")
+		params.OutName = output
+		params.Job = jobName
+		params.Engine = dbType
+		params.Database = database
+		if dbType == "sqlite" {
+			params.Database = strings.TrimSuffix(filepath.Base(database), filepath.Ext(database)) // name the backup after the file
 		}
+		params.Host = host
+		params.Compression = compressionAlgo
+		params.CompressionLevel = compressionLevel
 
+		// validate the storage parameters
 		if err = storage.ValidateStorage(params); err != nil {
 			cmd.PrintErrln(err)
 			return
 		}
 
-		// validate the storage parameters
+		// render the output name template, the all databases mode renders it for each database
+		if _, err = storage.RenderOutName(params, time.Now()); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
 
-		if dbType == "postgres" {
-			compress, _ = cmd.Flags().GetBool("compress")                       // get the compress flag value
-			pgOutFormat, _ = cmd.Flags().GetString("pg-out-format")             // get the pg-out-format flag value
-			pgCompressionAlgo, _ = cmd.Flags().GetString("pg-compression-algo") // get the pg-compression-algo flag value
-			pgCompressionLevel, _ = cmd.Flags().GetInt("pg-compression-level")  // get the pg-compression-level flag value
-
-			pda := &pg_dump.PgDumpArgs{
-				Host:                 host,
-				Port:                 port,
-				Username:             user,
-				Password:             password,
-				Database:             database,
-				PgOutFormat:          pgOutFormat,
-				Compress:             compress,
-				CompressionAlgorithm: pgCompressionAlgo,
-				CompressionLevel:     pgCompressionLevel,
-				AdditionalArgs:       additionalArgs,
-				Storage:              params,
-			}
+		// parallel dumps are only supported by pg_dump and mongodump
+		if jobs, _ := cmd.Flags().GetInt("jobs"); jobs != 0 && dbType != "postgres" && dbType != "mongodb" {
+			cmd.PrintErrln("--jobs is only supported with postgres and mongodb")
+			return
+		}
 
-			err = pg_dump.Backup(pda)
-			if err != nil {
-				cmd.PrintErrln(err)
-				os.Exit(1)
-			}
+		// the native dumper replaces mysqldump and mariadb-dump
+		dumpEngine, _ := cmd.Flags().GetString("mysql-dump-engine")
+		if dumpEngine != "tool" && dumpEngine != "native" {
+			cmd.PrintErrln(fmt.Sprintf("invalid dump engine: %s, expected tool or native", dumpEngine))
+			return
+		}
+		if dumpEngine == "native" && dbType != "mysql" && dbType != "mariadb" {
+			cmd.PrintErrln("--mysql-dump-engine native is only supported with mysql and mariadb")
+			return
 		}
 
-		if dbType == "mysql" {
-			mda := &mysql_dump.MySqlDumpArgs{
-				Host:           host,
-				Port:           port,
-				Username:       user,
-				Password:       password,
-				Database:       database,
-				AdditionalArgs: additionalArgs,
-				Storage:        params,
-			}
+		// reach the database through the SSH bastion if provided, the backup keeps the original host in its name
+		closeTunnel, err := openSSHTunnel(cmd, dbType, &host, &port, &uri, &tlsOptions)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		pgGlobals, _ := cmd.Flags().GetBool("pg-globals")
+		if pgGlobals && dbType != "postgres" {
+			closeTunnel()
+			cmd.PrintErrln("--pg-globals is only supported with postgres")
+			return
+		}
+
+		// physical backups copy the whole cluster, the globals included
+		pgPhysical, _ := cmd.Flags().GetBool("pg-physical")
+		if pgPhysical && (dbType != "postgres" || pgGlobals || cmd.Flags().Changed("all-databases")) {
+			closeTunnel()
+			cmd.PrintErrln("--pg-physical is only supported with postgres, without --pg-globals and --all-databases")
+			return
+		}
+
+		allDatabases, _ := cmd.Flags().GetBool("all-databases")
+		if allDatabases && (dbType == "sqlite" || dbType == "redis" || dbType == "elasticsearch" || dbType == "opensearch") {
+			closeTunnel()
+			cmd.PrintErrln(fmt.Sprintf("--all-databases is not supported with %s", dbType))
+			return
+		}
 
-			err = mysql_dump.Backup(mda)
-			if err != nil {
-				cmd.PrintErrln(err)
-				os.Exit(1)
+		if allDatabases {
+			err = backupAllDatabases(cmd, params, tlsOptions, filters, pgGlobals)
+		} else {
+			now := time.Now()
+			// the globals are backed up first, they must be restored before the database
+			if pgGlobals {
+				err = backupGlobals(params, params.OutName, tlsOptions, now)
+			}
+			if err == nil {
+				err = backupDatabase(cmd, params, tlsOptions, filters, now)
 			}
 		}
 
-		if dbType == "mariadb" {
-			mda := &mariadb_dump.MariaDBDumpArgs{
+		// tear the tunnel down before exiting
+		closeTunnel()
+
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// backupDatabase renders the backup name and backs up the database of the storage
+// parameters with the dump tool of the database type
+func backupDatabase(cmd *cobra.Command, params *storage.Params, tlsOptions backup.TLSOptions, filters backup.Filters, now time.Time) error {
+	var err error
+	if params.OutName, err = storage.RenderOutName(params, now); err != nil {
+		return err
+	}
+
+	switch dbType {
+	case "postgres":
+		compress, _ = cmd.Flags().GetBool("compress")                       // get the compress flag value
+		pgOutFormat, _ = cmd.Flags().GetString("pg-out-format")             // get the pg-out-format flag value
+		pgCompressionAlgo, _ = cmd.Flags().GetString("pg-compression-algo") // get the pg-compression-algo flag value
+		pgCompressionLevel, _ = cmd.Flags().GetInt("pg-compression-level")  // get the pg-compression-level flag value
+		jobs, _ := cmd.Flags().GetInt("jobs")                               // get the jobs flag value
+		crdbBackupURI, _ := cmd.Flags().GetString("crdb-backup-uri")        // get the crdb-backup-uri flag value
+		if crdbBackupURI, err = credentials.Resolve(crdbBackupURI); err != nil {
+			return err
+		}
+
+		if pgPhysical, _ := cmd.Flags().GetBool("pg-physical"); pgPhysical {
+			return pg_basebackup.Backup(&pg_basebackup.PgBaseBackupArgs{
 				Host:           host,
 				Port:           port,
 				Username:       user,
 				Password:       password,
-				Database:       database,
+				TLS:            tlsOptions,
 				AdditionalArgs: additionalArgs,
 				Storage:        params,
-			}
+			})
+		}
 
-			err = mariadb_dump.Backup(mda)
-			if err != nil {
-				cmd.PrintErrln(err)
-				os.Exit(1)
-			}
+		return pg_dump.Backup(&pg_dump.PgDumpArgs{
+			Host:                 host,
+			Port:                 port,
+			Username:             user,
+			Password:             password,
+			Database:             params.Database,
+			TLS:                  tlsOptions,
+			PgOutFormat:          pgOutFormat,
+			Compress:             compress,
+			CompressionAlgorithm: pgCompressionAlgo,
+			CompressionLevel:     pgCompressionLevel,
+			Jobs:                 jobs,
+			Filters:              filters,
+			CrdbBackupURI:        crdbBackupURI,
+			AdditionalArgs:       additionalArgs,
+			Storage:              params,
+		})
+	case "mysql":
+		if dumpEngine, _ := cmd.Flags().GetString("mysql-dump-engine"); dumpEngine == "native" {
+			return backupMySQLNative(cmd, params, tlsOptions, filters)
 		}
 
-		if dbType == "mongodb" {
-			compress, _ = cmd.Flags().GetBool("compress") // get the compress flag value
-			uri, _ := cmd.Flags().GetString("uri")        // get the uri flag value
+		skipSnapshot, _ := cmd.Flags().GetBool("mysql-skip-snapshot")      // get the mysql-skip-snapshot flag value
+		skipBinlog, _ := cmd.Flags().GetBool("mysql-skip-binlog-position") // get the mysql-skip-binlog-position flag value
 
-			da := &mongo_dump.DumpMongoArgs{
-				Compress:       compress,
-				AdditionalArgs: additionalArgs,
-				Uri:            uri,
-				Storage:        params,
-			}
+		return mysql_dump.Backup(&mysql_dump.MySqlDumpArgs{
+			Host:           host,
+			Port:           port,
+			Username:       user,
+			Password:       password,
+			Database:       params.Database,
+			TLS:            tlsOptions,
+			Filters:        filters,
+			SkipSnapshot:   skipSnapshot,
+			SkipBinlog:     skipBinlog,
+			AdditionalArgs: additionalArgs,
+			Storage:        params,
+		})
+	case "mariadb":
+		if dumpEngine, _ := cmd.Flags().GetString("mysql-dump-engine"); dumpEngine == "native" {
+			return backupMySQLNative(cmd, params, tlsOptions, filters)
+		}
 
-			err = mongo_dump.Backup(da)
-			if err != nil {
-				cmd.PrintErrln(err)
-				os.Exit(1)
-			}
+		skipSnapshot, _ := cmd.Flags().GetBool("mysql-skip-snapshot")      // get the mysql-skip-snapshot flag value
+		skipBinlog, _ := cmd.Flags().GetBool("mysql-skip-binlog-position") // get the mysql-skip-binlog-position flag value
+
+		return mariadb_dump.Backup(&mariadb_dump.MariaDBDumpArgs{
+			Host:           host,
+			Port:           port,
+			Username:       user,
+			Password:       password,
+			Database:       params.Database,
+			TLS:            tlsOptions,
+			Filters:        filters,
+			SkipSnapshot:   skipSnapshot,
+			SkipBinlog:     skipBinlog,
+			AdditionalArgs: additionalArgs,
+			Storage:        params,
+		})
+	case "mongodb":
+		compress, _ = cmd.Flags().GetBool("compress")           // get the compress flag value
+		jobs, _ := cmd.Flags().GetInt("jobs")                   // get the jobs flag value
+		skipOplog, _ := cmd.Flags().GetBool("mongo-skip-oplog") // get the mongo-skip-oplog flag value
+		archive, _ := cmd.Flags().GetBool("mongo-archive")      // get the mongo-archive flag value
+
+		return mongo_dump.Backup(&mongo_dump.DumpMongoArgs{
+			Compress:       compress,
+			Archive:        archive,
+			Parallel:       jobs,
+			Filters:        filters,
+			SkipOplog:      skipOplog,
+			AdditionalArgs: additionalArgs,
+			Uri:            uri,
+			Database:       params.Database,
+			TLS:            tlsOptions,
+			Storage:        params,
+		})
+	case "sqlite":
+		vacuum, _ := cmd.Flags().GetBool("sqlite-vacuum") // get the sqlite-vacuum flag value
+		dump, _ := cmd.Flags().GetBool("sqlite-dump")     // get the sqlite-dump flag value
+
+		return sqlite_backup.Backup(&sqlite_backup.SqliteBackupArgs{
+			Database:       database,
+			Vacuum:         vacuum,
+			Dump:           dump,
+			AdditionalArgs: additionalArgs,
+			Storage:        params,
+		})
+	case "redis":
+		if additionalArgs != "" {
+			return fmt.Errorf("--args is not supported with redis")
 		}
-	},
+
+		bgSave, _ := cmd.Flags().GetBool("redis-bgsave")      // get the redis-bgsave flag value
+		rdbPath, _ := cmd.Flags().GetString("redis-rdb-path") // get the redis-rdb-path flag value
+		saveTimeout, _ := cmd.Flags().GetDuration("redis-bgsave-timeout")
+		redisUser := ""
+		if cmd.Flags().Changed("user") {
+			redisUser = user // the default user of redis is not root
+		}
+
+		return redis_backup.Backup(&redis_backup.RedisBackupArgs{
+			Host:        host,
+			Port:        port,
+			Username:    redisUser,
+			Password:    password,
+			TLS:         tlsOptions,
+			BgSave:      bgSave,
+			RdbPath:     rdbPath,
+			SaveTimeout: saveTimeout,
+			Storage:     params,
+		})
+	case "elasticsearch", "opensearch":
+		if additionalArgs != "" {
+			return fmt.Errorf("--args is not supported with %s", dbType)
+		}
+
+		repository, _ := cmd.Flags().GetString("snapshot-repository")                  // get the snapshot-repository flag value
+		repositoryType, _ := cmd.Flags().GetString("snapshot-repository-type")         // get the snapshot-repository-type flag value
+		repositorySettings, _ := cmd.Flags().GetString("snapshot-repository-settings") // get the snapshot-repository-settings flag value
+		indices, _ := cmd.Flags().GetString("snapshot-indices")                        // get the snapshot-indices flag value
+		keep, _ := cmd.Flags().GetInt("snapshot-keep")                                 // get the snapshot-keep flag value
+		snapshotTimeout, _ := cmd.Flags().GetDuration("snapshot-timeout")              // get the snapshot-timeout flag value
+		searchUser := ""
+		if cmd.Flags().Changed("user") {
+			searchUser = user // the security of the cluster may be disabled
+		}
+
+		return search_snapshot.Backup(&search_snapshot.SearchSnapshotArgs{
+			Type:               dbType,
+			Host:               host,
+			Port:               port,
+			Username:           searchUser,
+			Password:           password,
+			TLS:                tlsOptions,
+			Repository:         repository,
+			RepositoryType:     repositoryType,
+			RepositorySettings: repositorySettings,
+			Indices:            indices,
+			Keep:               keep,
+			SnapshotTimeout:    snapshotTimeout,
+			Storage:            params,
+		})
+	default:
+		return fmt.Errorf("invalid database type: %s", dbType)
+	}
+}
+
+// backupMySQLNative backs up the MySQL or MariaDB database with the built-in dumper, which has no
+// command line to pass arguments to and always reads the database within a consistent snapshot
+func backupMySQLNative(cmd *cobra.Command, params *storage.Params, tlsOptions backup.TLSOptions, filters backup.Filters) error {
+	if additionalArgs != "" {
+		return fmt.Errorf("--args is not supported with the native dump engine")
+	}
+	if skipSnapshot, _ := cmd.Flags().GetBool("mysql-skip-snapshot"); skipSnapshot {
+		return fmt.Errorf("--mysql-skip-snapshot is not supported with the native dump engine")
+	}
+	if skipBinlog, _ := cmd.Flags().GetBool("mysql-skip-binlog-position"); skipBinlog {
+		return fmt.Errorf("--mysql-skip-binlog-position is not supported with the native dump engine")
+	}
+
+	return mysql_native.Backup(&mysql_native.MySqlNativeArgs{
+		Type:     dbType,
+		Host:     host,
+		Port:     port,
+		Username: user,
+		Password: password,
+		Database: params.Database,
+		TLS:      tlsOptions,
+		Filters:  filters,
+		Storage:  params,
+	})
 }
 
 func init() {
-	BackupCmd.Flags().StringVarP(&dbType, "type", "t", "", "Database type (mysql, postgres, mariadb, mongodb)")
+	BackupCmd.Flags().StringVarP(&dbType, "type", "t", "", "Database type (mysql, postgres, mariadb, mongodb, sqlite, redis, elasticsearch, opensearch)")
 
-	BackupCmd.Flags().StringVarP(&host, "host", "H", "127.0.0.1", "Database host")
+	BackupCmd.Flags().StringVarP(&host, "host", "H", "127.0.0.1", "Database host, IPv6 address or unix socket path (socket directory for PostgresSQL)")
 	BackupCmd.Flags().StringVarP(&port, "port", "P", "", "Database port")
 	BackupCmd.Flags().StringVarP(&user, "user", "u", "root", "Database user")
-	BackupCmd.Flags().StringVarP(&password, "password", "p", "", "Database password")
-	BackupCmd.Flags().StringVarP(&database, "database", "d", "", "Database name")
+	BackupCmd.Flags().StringVarP(&password, "password", "p", "", "Database password (prefer --password-file or "+credentials.PasswordEnv+")")
+	BackupCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the database password")
+	BackupCmd.Flags().StringVarP(&database, "database", "d", "", "Database name, or path of the SQLite database file")
+	BackupCmd.Flags().Bool("all-databases", false, "Back up every database of the server, one backup per database")
+	BackupCmd.Flags().String("include-db", "", "Comma separated glob patterns of the databases to back up in the all databases mode")
+	BackupCmd.Flags().String("exclude-db", "", "Comma separated glob patterns of the databases to skip in the all databases mode")
+	addTLSFlags(BackupCmd)
+	addSSHFlags(BackupCmd)
+	addFilterFlags(BackupCmd)
 
 	BackupCmd.Flags().BoolVarP(&compress, "compress", "c", false, "Compress the backup")
 	BackupCmd.Flags().StringVar(&additionalArgs, "args", "", "Additional arguments you want to pass to the dump command")
+	BackupCmd.Flags().StringVar(&compressionAlgo, "compression", "", "Compress single-file dumps with Sentinel [gzip, zstd, lz4, none]")
+	BackupCmd.Flags().IntVar(&compressionLevel, "compression-level", 0, "Sentinel compression level (gzip 1-9, zstd 1-22, lz4 1-9, 0 for the default)")
+	BackupCmd.Flags().StringVar(&jobName, "job", "", "Backup job name")
+	BackupCmd.Flags().IntP("jobs", "j", 0, "Parallel dump workers: pg_dump --jobs (directory format only) or mongodump --numParallelCollections")
 
 	// postgresql flags
 	BackupCmd.Flags().StringVar(&pgOutFormat, "pg-out-format", "", "PostgresSQL output format [p (plain), c (custom), d (directory), t (tar)] ")
 	BackupCmd.Flags().StringVar(&pgCompressionAlgo, "pg-compression-algo", "", "PostgresSQL compression algorithm [gzip, lz4, zstd, none]")
 	BackupCmd.Flags().IntVar(&pgCompressionLevel, "pg-compression-level", 1, "PostgresSQL compression level [1-9]")
+	BackupCmd.Flags().Bool("pg-globals", false, "Also back up the roles and tablespaces with pg_dumpall --globals-only, as a separate backup")
+	BackupCmd.Flags().Bool("pg-physical", false, "Take a physical backup of the cluster with pg_basebackup, for a point-in-time restore with wal-fetch")
+	BackupCmd.Flags().String("crdb-backup-uri", "", "CockroachDB: backup collection URI (e.g. s3://bucket/path?AUTH=implicit, or a secret reference) the cluster backs up into")
+
+	// mysql and mariadb flags
+	BackupCmd.Flags().Bool("mysql-skip-snapshot", false, "MySQL/MariaDB: keep the dump tool defaults instead of the consistent snapshot profile")
+	BackupCmd.Flags().Bool("mysql-skip-binlog-position", false, "MySQL/MariaDB: do not capture the binary log coordinates, which need the RELOAD and REPLICATION CLIENT privileges")
+	BackupCmd.Flags().String("mysql-dump-engine", "tool", "MySQL/MariaDB: dump with the client tools (tool) or with the built-in dumper, without any client installed (native)")
 
 	// mongodb flags
 	BackupCmd.Flags().StringVarP(&uri, "uri", "", "mongodb://localhost:27017", "MongoDB URI")
+	BackupCmd.Flags().Bool("mongo-archive", false, "MongoDB: dump as a single archive streamed to the storage, compressed with --compress (gzip) or --compression")
+	BackupCmd.Flags().Bool("mongo-skip-oplog", false, "MongoDB: do not capture the oplog of replica sets with mongodump --oplog")
+
+	// sqlite flags
+	BackupCmd.Flags().Bool("sqlite-vacuum", false, "SQLite: copy the database with VACUUM INTO, compacting it, instead of the backup API")
+	BackupCmd.Flags().Bool("sqlite-dump", false, "SQLite: also store a .sql script of the copy, made with the .dump command")
+
+	// redis flags
+	BackupCmd.Flags().Bool("redis-bgsave", false, "Redis: trigger BGSAVE and read the RDB file of the server, instead of transferring the snapshot like a replica")
+	BackupCmd.Flags().String("redis-rdb-path", "", "Redis: path of the RDB file written by BGSAVE, when the data directory of the server is mounted elsewhere")
+	BackupCmd.Flags().Duration("redis-bgsave-timeout", time.Hour, "Redis: how long BGSAVE is waited for before the backup fails")
+
+	// elasticsearch and opensearch flags
+	BackupCmd.Flags().String("snapshot-repository", "", "Elasticsearch/OpenSearch: snapshot repository the snapshot is taken into")
+	BackupCmd.Flags().String("snapshot-repository-type", "", "Elasticsearch/OpenSearch: type of the repository to register (fs, s3, gcs, azure...), when it is not registered yet")
+	BackupCmd.Flags().String("snapshot-repository-settings", "", "Elasticsearch/OpenSearch: comma separated key=value settings of the repository to register, e.g. location=/mnt/snapshots")
+	BackupCmd.Flags().String("snapshot-indices", "", "Elasticsearch/OpenSearch: comma separated indices or patterns to snapshot (default: every index)")
+	BackupCmd.Flags().Int("snapshot-keep", 0, "Elasticsearch/OpenSearch: number of successful snapshots taken by Sentinel kept in the repository, 0 to keep them all")
+	BackupCmd.Flags().Duration("snapshot-timeout", 6*time.Hour, "Elasticsearch/OpenSearch: how long the snapshot is waited for before it is recorded as failed")
 
 	// storage flags
-	BackupCmd.Flags().StringVarP(&storageType, "storage", "s", "local", "storage type (local, s3, google-drive)")
-	BackupCmd.Flags().StringVarP(&localPath, "local-path", "", "", "Local path to store the backup")
-	BackupCmd.Flags().StringVarP(&output, "output", "o", "", "Output name")
-	//google drive
-	BackupCmd.Flags().StringVarP(&gDriveFolderId, "gdrive-folder-id", "", "", "Google Drive folder ID")
-	BackupCmd.Flags().StringVarP(&gDriveSaFile, "gdrive-sa-file", "", "", "Google Drive service account file")
-	//aws s3 storage
-	BackupCmd.Flags().StringVarP(&awsBucket, "aws-bucket", "", "", "AWS S3 bucket name")
-	BackupCmd.Flags().StringVarP(&awsRegion, "aws-region", "", "us-east-1", "AWS region")
-	BackupCmd.Flags().StringVarP(&awsBucketEndpoint, "aws-bucket-endpoint", "", "", "AWS S3 bucket endpoint")
-	BackupCmd.Flags().StringVarP(&awsAccessKeyID, "aws-access-key-id", "", "", "AWS access key ID")
-	BackupCmd.Flags().StringVarP(&awsSecretAccessKey, "aws-secret", "", "", "AWS secret")
+	BackupCmd.Flags().StringVarP(&output, "output", "o", "", "Output name template, e.g. {engine}_{db}_{timestamp:20060102} (placeholders: {db}, {host}, {engine}, {job}, {env}, {timestamp})")
+	addStorageFlags(BackupCmd)
 
 	// required args
 	err := BackupCmd.MarkFlagRequired("type")
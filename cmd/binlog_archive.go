@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/pkg/backup/binlog_archive"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+// binlogDatabase is the database name the binary log archives are stored under in the layout
+const binlogDatabase = "binlog"
+
+var BinlogArchiveCmd = &cobra.Command{
+	Use:   "binlog-archive",
+	Short: "Archive the binary logs of a MySQL or MariaDB server",
+	Long: "Stream the binary logs of a MySQL or MariaDB server into the storage with mysqlbinlog, from the binary log " +
+		"of the last dump on, for a point-in-time restore. Runs until interrupted.",
+	Run: func(cmd *cobra.Command, args []string) {
+		dbType, _ = cmd.Flags().GetString("type")
+		if dbType != "mysql" && dbType != "mariadb" {
+			cmd.PrintErrln("binary log archiving is only supported with mysql and mariadb")
+			return
+		}
+
+		host, _ = cmd.Flags().GetString("host")           // get the host flag value
+		port, _ = cmd.Flags().GetString("port")           // get the port flag value
+		user, _ = cmd.Flags().GetString("user")           // get the user flag value
+		password, _ = cmd.Flags().GetString("password")   // get the password flag value
+		additionalArgs, _ = cmd.Flags().GetString("args") // get the args flag value
+		passwordFile, _ = cmd.Flags().GetString("password-file")
+		serverID, _ := cmd.Flags().GetInt("binlog-server-id")
+		interval, _ := cmd.Flags().GetDuration("binlog-upload-interval")
+
+		// resolve the password from the flag, the password file or the environment
+		if cmd.Flags().Changed("password") {
+			cmd.PrintErrln("warning: --password is visible in the shell history, prefer --password-file or " + credentials.PasswordEnv)
+		}
+		if password, err = credentials.ResolvePassword(password, passwordFile); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// start from the binary log of the last dump, or from the given one
+		startFile, err := readStartBinlog(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// read the TLS options of the database connection
+		tlsOptions, err := readTLSFlags(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// storage
+		params, err := readStorageParams(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		params.Engine = dbType
+		params.Database = binlogDatabase
+		params.Host = host
+
+		// validate the storage parameters
+		if err = storage.ValidateStorage(params); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// reach the database through the SSH bastion if provided
+		closeTunnel, err := openSSHTunnel(cmd, dbType, &host, &port, &uri, &tlsOptions)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		err = binlog_archive.Archive(&binlog_archive.BinlogArchiveArgs{
+			Type:           dbType,
+			Host:           host,
+			Port:           port,
+			Username:       user,
+			Password:       password,
+			TLS:            tlsOptions,
+			StartFile:      startFile,
+			ServerID:       serverID,
+			Interval:       interval,
+			AdditionalArgs: additionalArgs,
+			Storage:        params,
+		})
+
+		// tear the tunnel down before exiting
+		closeTunnel()
+
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// readStartBinlog reads the first binary log to archive, either from the metadata of a dump
+// or from the --start-binlog flag
+func readStartBinlog(c *cobra.Command) (string, error) {
+	metadataFile, _ := c.Flags().GetString("from-metadata")
+	startFile, _ := c.Flags().GetString("start-binlog")
+
+	if (metadataFile == "") == (startFile == "") {
+		return "", fmt.Errorf("exactly one of --from-metadata and --start-binlog is required")
+	}
+
+	if startFile != "" {
+		return startFile, nil
+	}
+
+	metadata, err := readMetadata(metadataFile)
+	if err != nil {
+		return "", err
+	}
+
+	if metadata.Binlog == nil {
+		return "", fmt.Errorf("%s holds no binary log position, the backup was taken without the binary log enabled", metadataFile)
+	}
+
+	return metadata.Binlog.File, nil
+}
+
+func init() {
+	BinlogArchiveCmd.Flags().StringVarP(&dbType, "type", "t", "", "Database type (mysql, mariadb)")
+
+	BinlogArchiveCmd.Flags().StringVarP(&host, "host", "H", "127.0.0.1", "Database host or IPv6 address")
+	BinlogArchiveCmd.Flags().StringVarP(&port, "port", "P", "", "Database port")
+	BinlogArchiveCmd.Flags().StringVarP(&user, "user", "u", "root", "Database user, with the REPLICATION SLAVE privilege")
+	BinlogArchiveCmd.Flags().StringVarP(&password, "password", "p", "", "Database password (prefer --password-file or "+credentials.PasswordEnv+")")
+	BinlogArchiveCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the database password")
+	addTLSFlags(BinlogArchiveCmd)
+	addSSHFlags(BinlogArchiveCmd)
+
+	BinlogArchiveCmd.Flags().String("from-metadata", "", "Metadata file (.meta.json) of the dump to archive the binary logs from")
+	BinlogArchiveCmd.Flags().String("start-binlog", "", "First binary log to archive, e.g. binlog.000042")
+	BinlogArchiveCmd.Flags().Int("binlog-server-id", 0, "Server ID the archiver registers with, must be unique among the replicas")
+	BinlogArchiveCmd.Flags().Duration("binlog-upload-interval", time.Minute, "How often the binary log being written is uploaded")
+	BinlogArchiveCmd.Flags().StringVar(&additionalArgs, "args", "", "Additional arguments you want to pass to mysqlbinlog")
+
+	// storage flags
+	addStorageFlags(BinlogArchiveCmd)
+
+	// required args
+	if err := BinlogArchiveCmd.MarkFlagRequired("type"); err != nil {
+		return
+	}
+
+	// add the binlog-archive command to the root command
+	RootCmd.AddCommand(BinlogArchiveCmd)
+}
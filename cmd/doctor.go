@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/doctor"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment before backing up",
+	Long: "Check the client binaries of each engine and their version, the version of the database server against " +
+		"the version of its dump tool, the storage with a probe object and the free space of the temporary directory, " +
+		"and report them in one table",
+	Run: func(cmd *cobra.Command, args []string) {
+		dbType, _ = cmd.Flags().GetString("type")
+
+		// the database type is optional, every engine is checked without it
+		if dbType != "" {
+			if err = backup.ValidateDbType(dbType); err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+		}
+
+		host, _ = cmd.Flags().GetString("host")         // get the host flag value
+		port, _ = cmd.Flags().GetString("port")         // get the port flag value
+		user, _ = cmd.Flags().GetString("user")         // get the user flag value
+		password, _ = cmd.Flags().GetString("password") // get the password flag value
+		database, _ = cmd.Flags().GetString("database") // get the database flag value
+		passwordFile, _ = cmd.Flags().GetString("password-file")
+		minTempSpace, _ := cmd.Flags().GetInt("min-temp-space")
+
+		// resolve the password from the flag, the password file or the environment
+		if password, err = credentials.ResolvePassword(password, passwordFile); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// resolve the MongoDB URI, which may hold the password
+		uri, _ = cmd.Flags().GetString("uri") // get the uri flag value
+		if uri, err = credentials.Resolve(uri); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// read the TLS options of the database connection
+		tlsOptions, err := readTLSFlags(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// storage
+		params, err := readStorageParams(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		params.Engine = dbType
+		params.Database = database
+		params.Host = host
+
+		report := &doctor.Report{}
+
+		// the client binaries of the engine, or of every engine
+		engines := doctor.Engines
+		if dbType != "" {
+			engines = []string{dbType}
+		}
+		for _, engine := range engines {
+			checks := doctor.CheckClients(engine, dbType != "")
+			if len(checks) == 0 {
+				checks = append(checks, doctor.Check{Name: engine, Status: doctor.StatusOK, Detail: "backed up without any client binary"})
+			}
+			for _, check := range checks {
+				report.Add(check)
+			}
+		}
+
+		// the version of the server against the version of its dump tool
+		if dbType != "" {
+			report.Add(checkServer(cmd, tlsOptions))
+		}
+
+		// the storage, with a probe object
+		if err := storage.ValidateStorage(params); err != nil {
+			report.Add(doctor.Check{Name: "storage", Status: doctor.StatusFailed, Detail: err.Error()})
+		} else {
+			report.Add(doctor.CheckStorage(params))
+		}
+
+		// the temporary directory the backups are staged in
+		report.Add(doctor.CheckTempSpace(uint64(minTempSpace) * 1024 * 1024))
+
+		if err := report.Print(cmd.OutOrStdout()); err != nil {
+			cmd.PrintErrln(err)
+		}
+
+		if report.Count(doctor.StatusFailed) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// checkServer connects to the database server, through the SSH bastion if provided, and compares
+// its version with the version of the dump tool
+func checkServer(cmd *cobra.Command, tlsOptions backup.TLSOptions) doctor.Check {
+	closeTunnel, err := openSSHTunnel(cmd, dbType, &host, &port, &uri, &tlsOptions)
+	if err != nil {
+		return doctor.Check{Name: "server", Status: doctor.StatusFailed, Detail: err.Error()}
+	}
+	defer closeTunnel()
+
+	conn := &sql.Connection{
+		Host:     host,
+		Port:     utils.DefaultValue(port, defaultPorts[dbType]),
+		User:     user,
+		Password: password,
+		Database: database,
+		TLS:      &tlsOptions,
+	}
+	if dbType == "postgres" {
+		conn.Database = utils.DefaultValue(database, "postgres") // PostgresSQL requires a database to connect to
+	}
+
+	return doctor.CheckServer(dbType, conn, uri)
+}
+
+func init() {
+	DoctorCmd.Flags().StringVarP(&dbType, "type", "t", "", "Database type to check (mysql, postgres, mariadb, mongodb, sqlite, redis, elasticsearch, opensearch), every engine when not provided")
+
+	DoctorCmd.Flags().StringVarP(&host, "host", "H", "127.0.0.1", "Database host, IPv6 address or unix socket path (socket directory for PostgresSQL)")
+	DoctorCmd.Flags().StringVarP(&port, "port", "P", "", "Database port")
+	DoctorCmd.Flags().StringVarP(&user, "user", "u", "root", "Database user")
+	DoctorCmd.Flags().StringVarP(&password, "password", "p", "", "Database password (prefer --password-file or "+credentials.PasswordEnv+")")
+	DoctorCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the database password")
+	DoctorCmd.Flags().StringVarP(&database, "database", "d", "", "Database name")
+	DoctorCmd.Flags().StringVarP(&uri, "uri", "", "mongodb://localhost:27017", "MongoDB URI")
+	addTLSFlags(DoctorCmd)
+	addSSHFlags(DoctorCmd)
+
+	DoctorCmd.Flags().Int("min-temp-space", 1024, "Free space in MiB below which the temporary directory is reported")
+
+	// storage flags
+	addStorageFlags(DoctorCmd)
+
+	// add the doctor command to the root command
+	RootCmd.AddCommand(DoctorCmd)
+}
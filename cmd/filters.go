@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags adds the table, schema and collection filter flags to the command
+func addFilterFlags(c *cobra.Command) {
+	c.Flags().String("include-table", "", "Comma separated glob patterns of the tables to back up (schema.table for PostgresSQL)")
+	c.Flags().String("exclude-table", "", "Comma separated glob patterns of the tables to skip")
+	c.Flags().String("exclude-table-data", "", "Comma separated glob patterns of the tables backed up without their data")
+	c.Flags().String("include-schema", "", "Comma separated glob patterns of the PostgresSQL schemas to back up")
+	c.Flags().String("exclude-schema", "", "Comma separated glob patterns of the PostgresSQL schemas to skip")
+	c.Flags().String("include-collection", "", "Comma separated glob patterns of the MongoDB collections to back up")
+	c.Flags().String("exclude-collection", "", "Comma separated glob patterns of the MongoDB collections to skip")
+}
+
+// readFilterFlags reads and validates the filter flags for the database type
+func readFilterFlags(c *cobra.Command, dbType string) (backup.Filters, error) {
+	patterns := func(name string) []string {
+		list, _ := c.Flags().GetString(name)
+		return backup.ParsePatterns(list)
+	}
+
+	filters := backup.Filters{
+		IncludeTables:      patterns("include-table"),
+		ExcludeTables:      patterns("exclude-table"),
+		ExcludeTableData:   patterns("exclude-table-data"),
+		IncludeSchemas:     patterns("include-schema"),
+		ExcludeSchemas:     patterns("exclude-schema"),
+		IncludeCollections: patterns("include-collection"),
+		ExcludeCollections: patterns("exclude-collection"),
+	}
+
+	return filters, backup.ValidateFilters(dbType, &filters)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/denisakp/sentinel/internal/storage/gdrive"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var gDriveAuthClientFile, gDriveAuthTokenFile string
+
+var GDriveAuthCmd = &cobra.Command{
+	Use:   "gdrive-auth",
+	Short: "Authorize Sentinel to upload backups to your Google Drive",
+	Long: "Authorize Sentinel to act as your Google user and store the refresh token, " +
+		"so backups can be uploaded to personal Drives where service accounts have no quota",
+	Run: func(cmd *cobra.Command, args []string) {
+		gDriveAuthClientFile, _ = cmd.Flags().GetString("gdrive-oauth-client-file") // get the OAuth client file flag value
+		gDriveAuthTokenFile, _ = cmd.Flags().GetString("gdrive-token-file")         // get the token file flag value
+
+		err := gdrive.Authorize(cmd.Context(), gDriveAuthClientFile, gDriveAuthTokenFile, func(url string) {
+			cmd.Printf("Open the following URL in your browser to authorize Sentinel:\n\n%s\n\n", url)
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+
+		cmd.Printf("Token saved to %s\n", gDriveAuthTokenFile)
+	},
+}
+
+func init() {
+	GDriveAuthCmd.Flags().StringVar(&gDriveAuthClientFile, "gdrive-oauth-client-file", "", "Google OAuth client file (Desktop app)")
+	GDriveAuthCmd.Flags().StringVar(&gDriveAuthTokenFile, "gdrive-token-file", "gdrive-token.json", "File to store the OAuth token in")
+
+	// required args
+	err := GDriveAuthCmd.MarkFlagRequired("gdrive-oauth-client-file")
+	if err != nil {
+		return
+	}
+
+	// add the gdrive-auth command to the root command
+	RootCmd.AddCommand(GDriveAuthCmd)
+}
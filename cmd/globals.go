@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/pkg/backup/pg_dump"
+	"time"
+)
+
+// globalsName is the name the PostgresSQL globals backup is reported with
+const globalsName = "globals"
+
+// globalsSuffix is added to the name of the PostgresSQL globals backup, before its extension.
+// The backups of the databases never end with it: their names end with the database name,
+// added after an underscore when the template lacks the {db} placeholder.
+const globalsSuffix = ".globals"
+
+// backupGlobals backs up the roles and tablespaces of the PostgresSQL cluster as a separate
+// backup of the run, named after the output template with the ".globals" suffix.
+func backupGlobals(params *storage.Params, template string, tlsOptions backup.TLSOptions, now time.Time) error {
+	globalsParams := *params
+	globalsParams.OutName = storage.SuffixedOutName(template, globalsSuffix)
+
+	var err error
+	if globalsParams.OutName, err = storage.RenderOutName(&globalsParams, now); err != nil {
+		return err
+	}
+
+	return pg_dump.BackupGlobals(&pg_dump.PgDumpArgs{
+		Host:     host,
+		Port:     port,
+		Username: user,
+		Password: password,
+		Database: database,
+		TLS:      tlsOptions,
+		Storage:  &globalsParams,
+	})
+}
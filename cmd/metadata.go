@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"os"
+)
+
+// readMetadata reads the metadata file stored next to a backup
+func readMetadata(path string) (*backup.Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup metadata - %w", err)
+	}
+
+	return backup.ParseMetadata(data)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/pkg/backup/oplog_archive"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+// oplogDatabase is the database name the oplog slices are stored under in the layout
+const oplogDatabase = "oplog"
+
+var OplogArchiveCmd = &cobra.Command{
+	Use:   "oplog-archive",
+	Short: "Archive the oplog of a MongoDB replica set between full dumps",
+	Long: "Tail the oplog of a MongoDB replica set from the position of the last dump on, and store the entries as " +
+		"slices replayable with mongorestore --oplogReplay, for a point-in-time restore. Runs until interrupted.",
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("oplog-upload-interval")
+
+		// resolve the MongoDB URI, which may hold the password
+		uri, _ = cmd.Flags().GetString("uri") // get the uri flag value
+		if uri, err = credentials.Resolve(uri); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// start from the oplog position of the last dump, or from the last entry of the oplog
+		start, err := readStartOplog(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// read the TLS options of the database connection
+		tlsOptions, err := readTLSFlags(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// storage
+		params, err := readStorageParams(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		params.Compression, _ = cmd.Flags().GetString("compression")
+		params.CompressionLevel, _ = cmd.Flags().GetInt("compression-level")
+		params.Engine = "mongodb"
+		params.Database = oplogDatabase
+
+		// validate the storage parameters
+		if err = storage.ValidateStorage(params); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// reach the database through the SSH bastion if provided
+		closeTunnel, err := openSSHTunnel(cmd, "mongodb", &host, &port, &uri, &tlsOptions)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		err = oplog_archive.Archive(&oplog_archive.OplogArchiveArgs{
+			Uri:      uri,
+			TLS:      tlsOptions,
+			Start:    start,
+			Interval: interval,
+			Storage:  params,
+		})
+
+		// tear the tunnel down before exiting
+		closeTunnel()
+
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// readStartOplog reads the oplog position to archive from in the metadata of a dump.
+// Without metadata, the archive starts from the last entry of the oplog.
+func readStartOplog(c *cobra.Command) (*backup.OplogPosition, error) {
+	metadataFile, _ := c.Flags().GetString("from-metadata")
+	if metadataFile == "" {
+		return nil, nil
+	}
+
+	metadata, err := readMetadata(metadataFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata.Oplog == nil {
+		return nil, fmt.Errorf("%s holds no oplog position, the dump was not taken from a replica set with its oplog", metadataFile)
+	}
+
+	return metadata.Oplog, nil
+}
+
+func init() {
+	OplogArchiveCmd.Flags().StringVarP(&uri, "uri", "", "mongodb://localhost:27017", "MongoDB URI of the replica set")
+	addTLSFlags(OplogArchiveCmd)
+	addSSHFlags(OplogArchiveCmd)
+
+	OplogArchiveCmd.Flags().String("from-metadata", "", "Metadata file (.meta.json) of the dump to archive the oplog from, the last entry when omitted")
+	OplogArchiveCmd.Flags().Duration("oplog-upload-interval", time.Minute, "How often the oplog entries are stored as a slice")
+	OplogArchiveCmd.Flags().String("compression", "gzip", "Compression of the oplog slices [gzip, zstd, lz4, none]")
+	OplogArchiveCmd.Flags().Int("compression-level", 0, "Compression level (gzip 1-9, zstd 1-22, lz4 1-9, 0 for the default)")
+
+	// storage flags
+	addStorageFlags(OplogArchiveCmd)
+
+	// add the oplog-archive command to the root command
+	RootCmd.AddCommand(OplogArchiveCmd)
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/utils"
+	"github.com/denisakp/sentinel/pkg/restore/binlog_replay"
+	"github.com/denisakp/sentinel/pkg/restore/mariadb_restore"
+	"github.com/denisakp/sentinel/pkg/restore/mongo_restore"
+	"github.com/denisakp/sentinel/pkg/restore/mysql_restore"
+	"github.com/denisakp/sentinel/pkg/restore/pg_restore"
+	"github.com/denisakp/sentinel/pkg/restore/sqlite_restore"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var backupFile string
+
+var RestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore your database",
+	Long:  "Restore your database from a backup made by Sentinel, compressed backups are decompressed on the fly",
+	Run: func(cmd *cobra.Command, args []string) {
+		dbType, _ = cmd.Flags().GetString("type")
+
+		// validate the database type
+		if err = backup.ValidateDbType(dbType); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// redis reads its snapshot when it starts, there is no restore tool to run
+		if dbType == "redis" {
+			cmd.PrintErrln("redis backups cannot be restored by sentinel, copy the RDB file to the data directory of the stopped server")
+			os.Exit(1)
+		}
+
+		// snapshots stay in the repository of the cluster
+		if dbType == "elasticsearch" || dbType == "opensearch" {
+			cmd.PrintErrln(dbType + " snapshots cannot be restored by sentinel, use the _snapshot/<repository>/<snapshot>/_restore API of the cluster")
+			os.Exit(1)
+		}
+
+		host, _ = cmd.Flags().GetString("host")           // get the host flag value
+		port, _ = cmd.Flags().GetString("port")           // get the port flag value
+		user, _ = cmd.Flags().GetString("user")           // get the user flag value
+		password, _ = cmd.Flags().GetString("password")   // get the password flag value
+		database, _ = cmd.Flags().GetString("database")   // get the database flag value
+		backupFile, _ = cmd.Flags().GetString("file")     // get the file flag value
+		additionalArgs, _ = cmd.Flags().GetString("args") // get the args flag value
+		passwordFile, _ = cmd.Flags().GetString("password-file")
+		globalsFile, _ := cmd.Flags().GetString("pg-globals-file")
+		if globalsFile != "" && dbType != "postgres" {
+			cmd.PrintErrln("--pg-globals-file is only supported with postgres")
+			return
+		}
+		crdbBackupURI, _ := cmd.Flags().GetString("crdb-backup-uri")
+		if crdbBackupURI, err = credentials.Resolve(crdbBackupURI); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+		binlogDir, _ := cmd.Flags().GetString("binlog-dir")
+		oplogDir, _ := cmd.Flags().GetString("oplog-dir")
+		stopDatetime, _ := cmd.Flags().GetString("stop-datetime")
+		if binlogDir != "" && dbType != "mysql" && dbType != "mariadb" {
+			cmd.PrintErrln("--binlog-dir is only supported with mysql and mariadb")
+			return
+		}
+		if oplogDir != "" && dbType != "mongodb" {
+			cmd.PrintErrln("--oplog-dir is only supported with mongodb")
+			return
+		}
+		if stopDatetime != "" && binlogDir == "" && oplogDir == "" {
+			cmd.PrintErrln("--stop-datetime requires --binlog-dir or --oplog-dir")
+			return
+		}
+
+		// resolve the password from the flag, the password file or the environment
+		if cmd.Flags().Changed("password") {
+			cmd.PrintErrln("warning: --password is visible in the shell history, prefer --password-file or " + credentials.PasswordEnv)
+		}
+		if password, err = credentials.ResolvePassword(password, passwordFile); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// resolve the MongoDB URI, which may hold the password
+		uri, _ = cmd.Flags().GetString("uri") // get the uri flag value
+		if uri, err = credentials.Resolve(uri); err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// read the TLS options of the database connection
+		tlsOptions, err := readTLSFlags(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		// reach the database through the SSH bastion if provided
+		closeTunnel, err := openSSHTunnel(cmd, dbType, &host, &port, &uri, &tlsOptions)
+		if err != nil {
+			cmd.PrintErrln(err)
+			return
+		}
+
+		switch dbType {
+		case "postgres":
+			err = pg_restore.Restore(&pg_restore.PgRestoreArgs{
+				Host:           host,
+				Port:           port,
+				Username:       user,
+				Password:       password,
+				Database:       database,
+				TLS:            tlsOptions,
+				File:           backupFile,
+				GlobalsFile:    globalsFile,
+				CrdbBackupURI:  crdbBackupURI,
+				AdditionalArgs: additionalArgs,
+			})
+		case "mysql":
+			err = mysql_restore.Restore(&mysql_restore.MySqlRestoreArgs{
+				Host:           host,
+				Port:           port,
+				Username:       user,
+				Password:       password,
+				Database:       database,
+				TLS:            tlsOptions,
+				File:           backupFile,
+				AdditionalArgs: additionalArgs,
+			})
+		case "mariadb":
+			err = mariadb_restore.Restore(&mariadb_restore.MariaDBRestoreArgs{
+				Host:           host,
+				Port:           port,
+				Username:       user,
+				Password:       password,
+				Database:       database,
+				TLS:            tlsOptions,
+				File:           backupFile,
+				AdditionalArgs: additionalArgs,
+			})
+		case "mongodb":
+			err = mongo_restore.Restore(&mongo_restore.MongoRestoreArgs{
+				Uri:            uri,
+				TLS:            tlsOptions,
+				File:           backupFile,
+				OplogDir:       oplogDir,
+				StopDatetime:   stopDatetime,
+				AdditionalArgs: additionalArgs,
+			})
+		case "sqlite":
+			err = sqlite_restore.Restore(&sqlite_restore.SqliteRestoreArgs{
+				Database:       database,
+				File:           backupFile,
+				AdditionalArgs: additionalArgs,
+			})
+		}
+
+		// replay the archived binary logs on top of the dump, up to the recovery target
+		if err == nil && binlogDir != "" {
+			err = replayBinlogs(cmd, binlogDir, stopDatetime, tlsOptions)
+		}
+
+		// tear the tunnel down before exiting
+		closeTunnel()
+
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// replayBinlogs replays the archived binary logs from the position recorded in the metadata of the backup
+func replayBinlogs(cmd *cobra.Command, binlogDir, stopDatetime string, tlsOptions backup.TLSOptions) error {
+	metadataFile, _ := cmd.Flags().GetString("metadata")
+	metadataFile = utils.DefaultValue(metadataFile, backupFile+backup.MetadataSuffix)
+
+	metadata, err := readMetadata(metadataFile)
+	if err != nil {
+		return err
+	}
+
+	return binlog_replay.Replay(&binlog_replay.BinlogReplayArgs{
+		Type:         dbType,
+		Host:         host,
+		Port:         port,
+		Username:     user,
+		Password:     password,
+		TLS:          tlsOptions,
+		Database:     metadata.Database,
+		Target:       utils.DefaultValue(database, metadata.Database),
+		Dir:          binlogDir,
+		Position:     metadata.Binlog,
+		StopDatetime: stopDatetime,
+	})
+}
+
+func init() {
+	RestoreCmd.Flags().StringVarP(&dbType, "type", "t", "", "Database type (mysql, postgres, mariadb, mongodb, sqlite)")
+
+	RestoreCmd.Flags().StringVarP(&host, "host", "H", "127.0.0.1", "Database host, IPv6 address or unix socket path (socket directory for PostgresSQL)")
+	RestoreCmd.Flags().StringVarP(&port, "port", "P", "", "Database port")
+	RestoreCmd.Flags().StringVarP(&user, "user", "u", "root", "Database user")
+	RestoreCmd.Flags().StringVarP(&password, "password", "p", "", "Database password (prefer --password-file or "+credentials.PasswordEnv+")")
+	RestoreCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the database password")
+	RestoreCmd.Flags().StringVarP(&database, "database", "d", "", "Database name, or path of the SQLite database file")
+	addTLSFlags(RestoreCmd)
+	addSSHFlags(RestoreCmd)
+
+	RestoreCmd.Flags().StringVarP(&backupFile, "file", "f", "", "Backup file, directory, MongoDB archive, SQLite copy or CockroachDB metadata file to restore")
+	RestoreCmd.Flags().StringVar(&additionalArgs, "args", "", "Additional arguments you want to pass to the restore command")
+	RestoreCmd.Flags().String("pg-globals-file", "", "PostgresSQL globals backup (roles, tablespaces) restored before the database")
+	RestoreCmd.Flags().String("crdb-backup-uri", "", "CockroachDB: backup collection URI (or a secret reference), its credentials being redacted from the metadata")
+
+	// mysql and mariadb point-in-time restore flags
+	RestoreCmd.Flags().String("binlog-dir", "", "MySQL/MariaDB: directory of the archived binary logs replayed after the dump")
+	RestoreCmd.Flags().String("stop-datetime", "", "Replay the binary logs or the oplog until this time (RFC 3339 or \"2006-01-02 15:04:05\" local time)")
+	RestoreCmd.Flags().String("metadata", "", "MySQL/MariaDB: metadata file of the backup holding its binary log position (default: <file>.meta.json)")
+
+	// mongodb flags
+	RestoreCmd.Flags().StringVarP(&uri, "uri", "", "mongodb://localhost:27017", "MongoDB URI")
+	RestoreCmd.Flags().String("oplog-dir", "", "MongoDB: directory of the oplog slices replayed after the dump")
+
+	// required args
+	if err := RestoreCmd.MarkFlagRequired("type"); err != nil {
+		return
+	}
+	if err := RestoreCmd.MarkFlagRequired("file"); err != nil {
+		return
+	}
+
+	// add the restore command to the root command
+	RootCmd.AddCommand(RestoreCmd)
+}
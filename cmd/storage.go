@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// addStorageFlags adds the flags of the storage backends and of the folder layout to the command
+func addStorageFlags(c *cobra.Command) {
+	c.Flags().StringVarP(&storageType, "storage", "s", "local", "storage type (local, s3, google-drive)")
+	c.Flags().StringVarP(&localPath, "local-path", "", "", "Local path to store the backup")
+	c.Flags().StringVar(&onCollision, "on-collision", "suffix", "Policy when a backup with the same name exists (suffix, overwrite, fail)")
+	c.Flags().StringVar(&envName, "env", "", "Environment the database belongs to (used by the layout)")
+	c.Flags().StringVar(&layout, "layout", "", "Folder layout template, e.g. {env}/{engine}/{database}/{yyyy}/{mm}/{name}")
+	//google drive
+	c.Flags().StringVarP(&gDriveFolderId, "gdrive-folder-id", "", "", "Google Drive folder ID")
+	c.Flags().StringVarP(&gDriveSaFile, "gdrive-sa-file", "", "", "Google Drive service account file (or a secret reference to the key)")
+	c.Flags().StringVar(&gDriveOAuthClient, "gdrive-oauth-client-file", "", "Google OAuth client file, used instead of a service account")
+	c.Flags().StringVar(&gDriveOAuthToken, "gdrive-token-file", "", "Google OAuth token file created by `sentinel gdrive-auth`")
+	c.Flags().StringVar(&gDriveSharedDriveId, "gdrive-shared-drive-id", "", "Google Shared Drive ID")
+	c.Flags().IntVar(&gDriveChunkSize, "gdrive-chunk-size", 16, "Google Drive resumable upload chunk size in MiB")
+	//aws s3 storage
+	c.Flags().StringVarP(&awsBucket, "aws-bucket", "", "", "AWS S3 bucket name")
+	c.Flags().StringVarP(&awsRegion, "aws-region", "", "us-east-1", "AWS region")
+	c.Flags().StringVarP(&awsBucketEndpoint, "aws-bucket-endpoint", "", "", "AWS S3 bucket endpoint")
+	c.Flags().StringVarP(&awsAccessKeyID, "aws-access-key-id", "", "", "AWS access key ID")
+	c.Flags().StringVarP(&awsSecretAccessKey, "aws-secret", "", "", "AWS secret (or a secret reference, e.g. vault:kv/sentinel#aws-secret)")
+	c.Flags().StringVar(&awsSSE, "aws-sse", "", "AWS S3 server-side encryption (SSE-S3, SSE-KMS, SSE-C)")
+	c.Flags().StringVar(&awsSSEKMSKeyId, "aws-sse-kms-key-id", "", "AWS KMS key ID used with SSE-KMS")
+	c.Flags().StringVar(&awsSSECustomerKey, "aws-sse-c-key", "", "Base64 encoded 256-bit key used with SSE-C")
+	c.Flags().StringVar(&awsStorageClass, "aws-storage-class", "", "AWS S3 storage class (STANDARD, STANDARD_IA, GLACIER_IR, DEEP_ARCHIVE, ...)")
+	c.Flags().StringVar(&awsObjectLockMode, "aws-object-lock-mode", "", "AWS S3 object lock retention mode (GOVERNANCE, COMPLIANCE)")
+	c.Flags().IntVar(&awsObjectLockDays, "aws-object-lock-days", 0, "AWS S3 object lock retention period in days")
+}
+
+// readStorageParams reads the storage flags and resolves their secret references. The backup name,
+// the job and the database the backups belong to are left to the caller, which validates the parameters.
+func readStorageParams(c *cobra.Command) (*storage.Params, error) {
+	storageType, _ = c.Flags().GetString("storage")  // get the storage flag value
+	localPath, _ = c.Flags().GetString("local-path") // get the local-path flag value
+	// google drive
+	gDriveFolderId, _ = c.Flags().GetString("gdrive-folder-id")
+	gDriveSaFile, _ = c.Flags().GetString("gdrive-sa-file")
+	gDriveOAuthClient, _ = c.Flags().GetString("gdrive-oauth-client-file")
+	gDriveOAuthToken, _ = c.Flags().GetString("gdrive-token-file")
+	gDriveSharedDriveId, _ = c.Flags().GetString("gdrive-shared-drive-id")
+	gDriveChunkSize, _ = c.Flags().GetInt("gdrive-chunk-size")
+	//aws s3 storage
+	awsBucket, _ = c.Flags().GetString("aws-bucket")
+	awsRegion, _ = c.Flags().GetString("aws-region")
+	awsBucketEndpoint, _ = c.Flags().GetString("aws-bucket-endpoint")
+	awsAccessKeyID, _ = c.Flags().GetString("aws-access-key-id")
+	awsSecretAccessKey, _ = c.Flags().GetString("aws-secret")
+	awsSSE, _ = c.Flags().GetString("aws-sse")
+	awsSSEKMSKeyId, _ = c.Flags().GetString("aws-sse-kms-key-id")
+	awsSSECustomerKey, _ = c.Flags().GetString("aws-sse-c-key")
+	awsStorageClass, _ = c.Flags().GetString("aws-storage-class")
+	awsObjectLockMode, _ = c.Flags().GetString("aws-object-lock-mode")
+	awsObjectLockDays, _ = c.Flags().GetInt("aws-object-lock-days")
+	envName, _ = c.Flags().GetString("env")
+	layout, _ = c.Flags().GetString("layout")
+	onCollision, _ = c.Flags().GetString("on-collision")
+
+	params := &storage.Params{
+		StorageType:          storageType,
+		LocalPath:            localPath,
+		GoogleServiceAccount: gDriveSaFile,
+		GoogleDriveFolderId:  gDriveFolderId,
+		GoogleOAuthClient:    gDriveOAuthClient,
+		GoogleOAuthToken:     gDriveOAuthToken,
+		GoogleSharedDriveId:  gDriveSharedDriveId,
+		GoogleChunkSizeMiB:   gDriveChunkSize,
+		AWSBucket:            awsBucket,
+		AWSRegion:            awsRegion,
+		AWSBucketEndpoint:    awsBucketEndpoint,
+		AWSAccessKeyID:       awsAccessKeyID,
+		AWSSecretAccessKey:   awsSecretAccessKey,
+		AWSSSE:               awsSSE,
+		AWSSSEKMSKeyId:       awsSSEKMSKeyId,
+		AWSSSECustomerKey:    awsSSECustomerKey,
+		AWSStorageClass:      awsStorageClass,
+		AWSObjectLockMode:    awsObjectLockMode,
+		AWSObjectLockDays:    awsObjectLockDays,
+		Env:                  envName,
+		Layout:               layout,
+		OnCollision:          onCollision,
+	}
+
+	// resolve the secret references of the storage parameters
+	if err := storage.ResolveSecrets(params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
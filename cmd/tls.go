@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/spf13/cobra"
+)
+
+// addTLSFlags adds the TLS flags of the database connection to the command
+func addTLSFlags(c *cobra.Command) {
+	c.Flags().String("ssl-mode", "", "Database TLS mode (disable, prefer, require, verify-ca, verify-full)")
+	c.Flags().String("ssl-ca", "", "CA certificate used to verify the database server certificate")
+	c.Flags().String("ssl-cert", "", "Client certificate (for MongoDB, a PEM file holding the certificate and its key)")
+	c.Flags().String("ssl-key", "", "Client private key")
+}
+
+// readTLSFlags reads and validates the TLS flags of the database connection
+func readTLSFlags(c *cobra.Command) (backup.TLSOptions, error) {
+	options := backup.TLSOptions{}
+	options.Mode, _ = c.Flags().GetString("ssl-mode")
+	options.CAFile, _ = c.Flags().GetString("ssl-ca")
+	options.CertFile, _ = c.Flags().GetString("ssl-cert")
+	options.KeyFile, _ = c.Flags().GetString("ssl-key")
+
+	return options, backup.ValidateTLSOptions(&options)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/tunnel"
+	"github.com/denisakp/sentinel/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// defaultPorts maps the database types, MongoDB aside, to the port their server listens on by default
+var defaultPorts = map[string]string{
+	"postgres":      "5432",
+	"mysql":         "3306",
+	"mariadb":       "3306",
+	"redis":         "6379",
+	"elasticsearch": "9200",
+	"opensearch":    "9200",
+}
+
+// addSSHFlags adds the SSH tunnel flags to the command
+func addSSHFlags(c *cobra.Command) {
+	c.Flags().String("ssh-host", "", "SSH bastion host[:port] the database is reached through")
+	c.Flags().String("ssh-user", "", "SSH bastion user")
+	c.Flags().String("ssh-key", "", "SSH private key file, the SSH agent is used when not provided")
+	c.Flags().String("ssh-key-passphrase", "", "SSH private key passphrase (prefer --ssh-key-passphrase-file or "+credentials.SSHKeyPassphraseEnv+")")
+	c.Flags().String("ssh-key-passphrase-file", "", "File containing the SSH private key passphrase")
+	c.Flags().String("ssh-known-hosts", "", "known_hosts file used to verify the bastion (default ~/.ssh/known_hosts)")
+}
+
+// openSSHTunnel opens a local port-forward through the SSH bastion when one is provided,
+// and points the database host and port, or the MongoDB URI, at its local end. The TLS
+// certificate is still verified against the database host, see backup.TLSOptions.ThroughTunnel.
+//
+// Returns the function tearing the tunnel down, which does nothing without a bastion.
+func openSSHTunnel(c *cobra.Command, dbType string, host, port, uri *string, tlsOptions *backup.TLSOptions) (func(), error) {
+	options := &tunnel.Options{}
+	options.Host, _ = c.Flags().GetString("ssh-host")
+	options.User, _ = c.Flags().GetString("ssh-user")
+	options.KeyFile, _ = c.Flags().GetString("ssh-key")
+	options.KnownHostsFile, _ = c.Flags().GetString("ssh-known-hosts")
+	passphrase, _ := c.Flags().GetString("ssh-key-passphrase")
+	passphraseFile, _ := c.Flags().GetString("ssh-key-passphrase-file")
+
+	if options.Host == "" {
+		return func() {}, nil
+	}
+
+	// SQLite databases are files of the local host
+	if dbType == "sqlite" {
+		return nil, fmt.Errorf("sqlite databases cannot be reached through an SSH tunnel")
+	}
+
+	if c.Flags().Changed("ssh-key-passphrase") {
+		c.PrintErrln("warning: --ssh-key-passphrase is visible in the shell history, prefer --ssh-key-passphrase-file or " + credentials.SSHKeyPassphraseEnv)
+	}
+
+	// resolve the passphrase from the flag, the passphrase file or the environment
+	var err error
+	if options.KeyPassphrase, err = credentials.ResolveSSHKeyPassphrase(passphrase, passphraseFile); err != nil {
+		return nil, err
+	}
+
+	// the remote address is resolved by the bastion
+	var remoteHost, remotePort string
+	if dbType == "mongodb" {
+		if remoteHost, remotePort, err = mongo.URIHost(*uri); err != nil {
+			return nil, err
+		}
+	} else {
+		if backup.IsSocket(*host) {
+			return nil, fmt.Errorf("unix sockets cannot be reached through an SSH tunnel")
+		}
+		remoteHost, remotePort = backup.TrimBrackets(*host), utils.DefaultValue(*port, defaultPorts[dbType])
+	}
+
+	if err := tlsOptions.ThroughTunnel(dbType, remoteHost); err != nil {
+		return nil, err
+	}
+
+	t, err := tunnel.Open(options, remoteHost, remotePort)
+	if err != nil {
+		return nil, err
+	}
+
+	localHost, localPort := t.Addr()
+	if dbType == "mongodb" {
+		if *uri, err = mongo.WithHost(*uri, localHost, localPort); err != nil {
+			_ = t.Close()
+			return nil, err
+		}
+	} else {
+		*host, *port = localHost, localPort
+	}
+
+	return func() {
+		if err := t.Close(); err != nil {
+			c.PrintErrln(fmt.Errorf("failed to close the SSH tunnel: %w", err))
+		}
+	}, nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/encryption"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/pkg/backup/wal_push"
+	"github.com/denisakp/sentinel/pkg/restore/wal_fetch"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// walDatabase is the database name the archived WAL is stored under in the layout
+const walDatabase = "wal"
+
+var WalPushCmd = &cobra.Command{
+	Use:   "wal-push <path>",
+	Short: "Archive a PostgresSQL WAL file, to be used as archive_command",
+	Long: "Compress a PostgresSQL WAL file, encrypt it with --encryption-key, and store it in the storage, for a point-in-time restore of a physical backup. " +
+		"Use it as archive_command = 'sentinel wal-push %p <storage flags>'",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// any failure must exit with an error, PostgresSQL removes the WAL files it considers archived
+		params, err := readWalStorageParams(cmd)
+		var key []byte
+		if err == nil {
+			key, err = readWalEncryptionKey(cmd)
+		}
+		if err == nil {
+			err = wal_push.Push(&wal_push.WalPushArgs{Path: args[0], EncryptionKey: key, Storage: params})
+		}
+
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+	},
+}
+
+var WalFetchCmd = &cobra.Command{
+	Use:   "wal-fetch <name> <path>",
+	Short: "Fetch an archived PostgresSQL WAL file, to be used as restore_command",
+	Long: "Fetch a PostgresSQL WAL file archived by wal-push from the storage, decrypt and decompress it. " +
+		"Use it as restore_command = 'sentinel wal-fetch %f %p <storage flags>'",
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		params, err := readWalStorageParams(cmd)
+		var key []byte
+		if err == nil {
+			key, err = readWalEncryptionKey(cmd)
+		}
+		if err == nil {
+			err = wal_fetch.Fetch(&wal_fetch.WalFetchArgs{Name: args[0], Destination: args[1], EncryptionKey: key, Storage: params})
+		}
+
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// readWalStorageParams reads and validates the storage parameters of the archived WAL
+func readWalStorageParams(c *cobra.Command) (*storage.Params, error) {
+	params, err := readStorageParams(c)
+	if err != nil {
+		return nil, err
+	}
+
+	params.Compression, _ = c.Flags().GetString("compression")
+	params.CompressionLevel, _ = c.Flags().GetInt("compression-level")
+	params.Job, _ = c.Flags().GetString("job")
+	params.Engine = "postgres"
+	params.Database = walDatabase
+
+	// validate the storage parameters
+	if err := storage.ValidateStorage(params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// readWalEncryptionKey reads the key the archived WAL is encrypted with, resolving secret references.
+//
+// Returns nil when no key is provided, the WAL files being stored as is.
+func readWalEncryptionKey(c *cobra.Command) ([]byte, error) {
+	value, _ := c.Flags().GetString("encryption-key")
+	value, err := credentials.Resolve(value)
+	if err != nil || value == "" {
+		return nil, err
+	}
+
+	return encryption.ParseKey(value)
+}
+
+// addWalFlags adds the storage and compression flags of the archived WAL to the command.
+// wal-push and wal-fetch must be given the same values.
+func addWalFlags(c *cobra.Command) {
+	c.Flags().String("compression", "gzip", "Compression of the archived WAL files [gzip, zstd, lz4, none]")
+	c.Flags().Int("compression-level", 0, "Compression level (gzip 1-9, zstd 1-22, lz4 1-9, 0 for the default)")
+	c.Flags().String("job", "", "Backup job name (used by the layout)")
+	c.Flags().String("encryption-key", "", "Base64 encoded 256-bit key the WAL files are encrypted with before they leave the host (or a secret reference)")
+	addStorageFlags(c)
+}
+
+func init() {
+	addWalFlags(WalPushCmd)
+	addWalFlags(WalFetchCmd)
+
+	// add the WAL commands to the root command
+	RootCmd.AddCommand(WalPushCmd)
+	RootCmd.AddCommand(WalFetchCmd)
+}
@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SystemDatabases maps the database types to the databases managed by the server itself,
+// which are skipped by the all databases mode unless explicitly included by name
+var SystemDatabases = map[string][]string{
+	"postgres": {"postgres"},
+	"mysql":    {"information_schema", "performance_schema", "sys", "mysql"},
+	"mariadb":  {"information_schema", "performance_schema", "sys", "mysql"},
+	"mongodb":  {"admin", "config", "local"},
+}
+
+// ParsePatterns splits a comma separated list of glob patterns, e.g. "app_*,billing"
+func ParsePatterns(list string) []string {
+	var patterns []string
+
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns
+}
+
+// FilterDatabases keeps the databases matching one of the include patterns (all when there is
+// none) and none of the exclude patterns. The system databases of the type are skipped unless
+// an include pattern is their exact name.
+//
+// Returns the selected databases, or an error if a pattern is malformed.
+func FilterDatabases(dbType string, databases, include, exclude []string) ([]string, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid database pattern %q: %w", pattern, err)
+		}
+	}
+
+	system := map[string]bool{}
+	for _, name := range SystemDatabases[dbType] {
+		system[name] = true
+	}
+
+	var selected []string
+	for _, database := range databases {
+		if system[database] && !containsString(include, database) {
+			continue
+		}
+
+		if len(include) > 0 && !matchAny(include, database) {
+			continue
+		}
+
+		if matchAny(exclude, database) {
+			continue
+		}
+
+		selected = append(selected, database)
+	}
+
+	return selected, nil
+}
+
+// matchAny reports whether the name matches one of the glob patterns
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsString reports whether the list contains the value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
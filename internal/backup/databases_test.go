@@ -0,0 +1,62 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterDatabases(t *testing.T) {
+	databases := []string{"app", "app_archive", "billing", "information_schema", "mysql", "sys"}
+
+	tests := []struct {
+		name    string
+		dbType  string
+		include string
+		exclude string
+		want    []string
+		wantErr bool
+	}{
+		{name: "All user databases", dbType: "mysql", want: []string{"app", "app_archive", "billing"}},
+		{name: "Include pattern", dbType: "mysql", include: "app*", want: []string{"app", "app_archive"}},
+		{name: "Exclude pattern", dbType: "mysql", exclude: "*_archive", want: []string{"app", "billing"}},
+		{name: "Include and exclude", dbType: "mysql", include: "app*, billing", exclude: "*_archive", want: []string{"app", "billing"}},
+		{name: "System database included by name", dbType: "mysql", include: "mysql,app", want: []string{"app", "mysql"}},
+		{name: "System database not included by pattern", dbType: "mysql", include: "*", want: []string{"app", "app_archive", "billing"}},
+		{name: "Other engine system databases", dbType: "mongodb", want: []string{"app", "app_archive", "billing", "information_schema", "mysql", "sys"}},
+		{name: "Invalid pattern", dbType: "mysql", include: "app[", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FilterDatabases(tt.dbType, databases, ParsePatterns(tt.include), ParsePatterns(tt.exclude))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FilterDatabases() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterDatabases() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSummary_Print(t *testing.T) {
+	summary := &RunSummary{}
+	summary.Add("app", 1500*time.Millisecond, nil)
+	summary.Add("billing", 250*time.Millisecond, fmt.Errorf("access denied"))
+
+	var out bytes.Buffer
+	if err := summary.Print(&out); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := "DATABASE  STATUS  DURATION  ERROR\n" +
+		"app       ok      1.5s      \n" +
+		"billing   failed  250ms     access denied\n" +
+		"1 database(s) backed up, 1 failed, in 1.75s\n"
+	if out.String() != want {
+		t.Errorf("Print() got =\n%s\nwant =\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"fmt"
+	"path"
+)
+
+// Filters holds the engine-neutral filters of the objects to back up, as glob patterns
+// translated by each dump tool into its native flags
+type Filters struct {
+	IncludeTables      []string // tables to back up, all the tables when empty
+	ExcludeTables      []string // tables to skip
+	ExcludeTableData   []string // tables backed up without their data, e.g. large log tables
+	IncludeSchemas     []string // PostgresSQL schemas to back up, all the schemas when empty
+	ExcludeSchemas     []string // PostgresSQL schemas to skip
+	IncludeCollections []string // MongoDB collections to back up, all the collections when empty
+	ExcludeCollections []string // MongoDB collections to skip
+}
+
+// ValidateFilters validates the filter patterns and checks the database type supports them:
+// tables for the SQL server engines, schemas for PostgresSQL and collections for MongoDB.
+func ValidateFilters(dbType string, f *Filters) error {
+	for _, pattern := range f.patterns() {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+		}
+	}
+
+	if f.HasTables() && dbType == "mongodb" {
+		return fmt.Errorf("table filters are not supported by %s, use the collection filters instead", dbType)
+	}
+
+	if f.HasTables() && dbType != "postgres" && dbType != "mysql" && dbType != "mariadb" {
+		return fmt.Errorf("table filters are not supported by %s", dbType)
+	}
+
+	if len(f.IncludeSchemas)+len(f.ExcludeSchemas) > 0 && dbType != "postgres" {
+		return fmt.Errorf("schema filters are only supported by postgres")
+	}
+
+	if f.HasCollections() && dbType != "mongodb" {
+		return fmt.Errorf("collection filters are only supported by mongodb")
+	}
+
+	return nil
+}
+
+// HasTables reports whether table filters are set
+func (f *Filters) HasTables() bool {
+	return len(f.IncludeTables)+len(f.ExcludeTables)+len(f.ExcludeTableData) > 0
+}
+
+// HasCollections reports whether collection filters are set
+func (f *Filters) HasCollections() bool {
+	return len(f.IncludeCollections)+len(f.ExcludeCollections) > 0
+}
+
+// PgArgs translates the filters into pg_dump arguments. pg_dump matches the patterns
+// itself, * and ? having the same meaning as in the glob patterns.
+func (f *Filters) PgArgs() []string {
+	var args []string
+
+	for _, flag := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"--schema", f.IncludeSchemas},
+		{"--exclude-schema", f.ExcludeSchemas},
+		{"--table", f.IncludeTables},
+		{"--exclude-table", f.ExcludeTables},
+		{"--exclude-table-data", f.ExcludeTableData},
+	} {
+		for _, pattern := range flag.patterns {
+			args = append(args, fmt.Sprintf("%s=%s", flag.name, pattern))
+		}
+	}
+
+	return args
+}
+
+// SelectTables applies the table filters to the tables of the database, for the dump
+// tools without pattern support.
+//
+// Returns the tables backed up with their data and the tables backed up without.
+func (f *Filters) SelectTables(tables []string) ([]string, []string) {
+	var withData, withoutData []string
+
+	for _, table := range selectNames(tables, f.IncludeTables, f.ExcludeTables) {
+		if matchAny(f.ExcludeTableData, table) {
+			withoutData = append(withoutData, table)
+		} else {
+			withData = append(withData, table)
+		}
+	}
+
+	return withData, withoutData
+}
+
+// MySQLTableArgs translates the table filters into the --ignore-table arguments of mysqldump
+// and mariadb-dump, applying them to the tables of the database. The tables backed up
+// without their data are ignored too, their schema being dumped by a second --no-data pass.
+//
+// Returns the arguments and the tables to back up without their data, or an error if no table is selected.
+func (f *Filters) MySQLTableArgs(database string, tables []string) ([]string, []string, error) {
+	withData, withoutData := f.SelectTables(tables)
+	if len(withData)+len(withoutData) == 0 {
+		return nil, nil, fmt.Errorf("no table of %s matches the table filters", database)
+	}
+
+	var args []string
+	for _, table := range tables {
+		if !containsString(withData, table) {
+			args = append(args, fmt.Sprintf("--ignore-table=%s.%s", database, table))
+		}
+	}
+
+	return args, withoutData, nil
+}
+
+// SelectCollections applies the collection filters to the collections of the database.
+//
+// Returns the collections to back up.
+func (f *Filters) SelectCollections(collections []string) []string {
+	return selectNames(collections, f.IncludeCollections, f.ExcludeCollections)
+}
+
+// patterns returns every pattern of the filters
+func (f *Filters) patterns() []string {
+	var patterns []string
+	for _, list := range [][]string{f.IncludeTables, f.ExcludeTables, f.ExcludeTableData, f.IncludeSchemas, f.ExcludeSchemas, f.IncludeCollections, f.ExcludeCollections} {
+		patterns = append(patterns, list...)
+	}
+
+	return patterns
+}
+
+// selectNames keeps the names matching one of the include patterns (all when there is none)
+// and none of the exclude patterns
+func selectNames(names, include, exclude []string) []string {
+	var selected []string
+	for _, name := range names {
+		if len(include) > 0 && !matchAny(include, name) {
+			continue
+		}
+
+		if matchAny(exclude, name) {
+			continue
+		}
+
+		selected = append(selected, name)
+	}
+
+	return selected
+}
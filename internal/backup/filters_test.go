@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  string
+		filters Filters
+		wantErr bool
+	}{
+		{name: "No filters", dbType: "mysql"},
+		{name: "Tables with postgres", dbType: "postgres", filters: Filters{IncludeTables: []string{"public.*"}, ExcludeTableData: []string{"*.logs"}}},
+		{name: "Tables with mariadb", dbType: "mariadb", filters: Filters{ExcludeTables: []string{"tmp_*"}}},
+		{name: "Schemas with postgres", dbType: "postgres", filters: Filters{ExcludeSchemas: []string{"audit"}}},
+		{name: "Collections with mongodb", dbType: "mongodb", filters: Filters{ExcludeCollections: []string{"sessions_*"}}},
+		{name: "Tables with mongodb", dbType: "mongodb", filters: Filters{IncludeTables: []string{"users"}}, wantErr: true},
+		{name: "Tables with elasticsearch", dbType: "elasticsearch", filters: Filters{IncludeTables: []string{"logs"}}, wantErr: true},
+		{name: "Tables with redis", dbType: "redis", filters: Filters{ExcludeTables: []string{"cache"}}, wantErr: true},
+		{name: "Tables with sqlite", dbType: "sqlite", filters: Filters{IncludeTables: []string{"users"}}, wantErr: true},
+		{name: "Schemas with mysql", dbType: "mysql", filters: Filters{IncludeSchemas: []string{"app"}}, wantErr: true},
+		{name: "Collections with postgres", dbType: "postgres", filters: Filters{IncludeCollections: []string{"users"}}, wantErr: true},
+		{name: "Invalid pattern", dbType: "postgres", filters: Filters{ExcludeTables: []string{"logs["}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFilters(tt.dbType, &tt.filters); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFilters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilters_PgArgs(t *testing.T) {
+	filters := &Filters{
+		IncludeTables:    []string{"public.*"},
+		ExcludeTables:    []string{"public.tmp_*"},
+		ExcludeTableData: []string{"public.audit_logs"},
+		IncludeSchemas:   []string{"public"},
+		ExcludeSchemas:   []string{"pg_temp*"},
+	}
+
+	want := []string{"--schema=public", "--exclude-schema=pg_temp*", "--table=public.*", "--exclude-table=public.tmp_*", "--exclude-table-data=public.audit_logs"}
+	if got := filters.PgArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PgArgs() got = %v, want %v", got, want)
+	}
+}
+
+func TestFilters_SelectTables(t *testing.T) {
+	tables := []string{"users", "orders", "audit_logs", "tmp_import", "tmp_export"}
+
+	tests := []struct {
+		name            string
+		filters         Filters
+		wantWithData    []string
+		wantWithoutData []string
+	}{
+		{name: "No filters", wantWithData: tables},
+		{name: "Include pattern", filters: Filters{IncludeTables: []string{"u*", "orders"}}, wantWithData: []string{"users", "orders"}},
+		{name: "Exclude pattern", filters: Filters{ExcludeTables: []string{"tmp_*"}}, wantWithData: []string{"users", "orders", "audit_logs"}},
+		{
+			name:            "Data-only exclusion",
+			filters:         Filters{ExcludeTables: []string{"tmp_*"}, ExcludeTableData: []string{"*_logs"}},
+			wantWithData:    []string{"users", "orders"},
+			wantWithoutData: []string{"audit_logs"},
+		},
+		{name: "Nothing matches", filters: Filters{IncludeTables: []string{"missing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withData, withoutData := tt.filters.SelectTables(tables)
+			if !reflect.DeepEqual(withData, tt.wantWithData) {
+				t.Errorf("SelectTables() withData = %v, want %v", withData, tt.wantWithData)
+			}
+			if !reflect.DeepEqual(withoutData, tt.wantWithoutData) {
+				t.Errorf("SelectTables() withoutData = %v, want %v", withoutData, tt.wantWithoutData)
+			}
+		})
+	}
+}
+
+func TestFilters_MySQLTableArgs(t *testing.T) {
+	tables := []string{"users", "audit_logs", "tmp_import"}
+
+	tests := []struct {
+		name            string
+		filters         Filters
+		want            []string
+		wantWithoutData []string
+		wantErr         bool
+	}{
+		{name: "Exclude pattern", filters: Filters{ExcludeTables: []string{"tmp_*"}}, want: []string{"--ignore-table=app.tmp_import"}},
+		{
+			name:            "Data-only exclusion",
+			filters:         Filters{IncludeTables: []string{"users", "audit_logs"}, ExcludeTableData: []string{"*_logs"}},
+			want:            []string{"--ignore-table=app.audit_logs", "--ignore-table=app.tmp_import"},
+			wantWithoutData: []string{"audit_logs"},
+		},
+		{name: "Nothing matches", filters: Filters{IncludeTables: []string{"missing"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, withoutData, err := tt.filters.MySQLTableArgs("app", tables)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MySQLTableArgs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MySQLTableArgs() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(withoutData, tt.wantWithoutData) {
+				t.Errorf("MySQLTableArgs() withoutData = %v, want %v", withoutData, tt.wantWithoutData)
+			}
+		})
+	}
+}
+
+func TestFilters_SelectCollections(t *testing.T) {
+	filters := &Filters{IncludeCollections: []string{"app_*"}, ExcludeCollections: []string{"*_cache"}}
+
+	want := []string{"app_users", "app_orders"}
+	if got := filters.SelectCollections([]string{"app_users", "app_orders", "app_cache", "system.views"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectCollections() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package backup
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// IsSocket reports whether the host is a unix socket path rather than a network host.
+// As with libpq, a host starting with a slash is a socket: the socket directory for
+// PostgresSQL, e.g. /var/run/postgresql, and the socket file for MySQL and MariaDB,
+// e.g. /var/run/mysqld/mysqld.sock.
+func IsSocket(host string) bool {
+	return strings.HasPrefix(host, "/")
+}
+
+// TrimBrackets removes the brackets of an IPv6 literal, e.g. [::1] becomes ::1,
+// since the dump tools expect the bare address in their --host argument
+func TrimBrackets(host string) string {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		return host[1 : len(host)-1]
+	}
+
+	return host
+}
+
+// HostPort joins the host and the port into a network address, bracketing IPv6 literals
+func HostPort(host, port string) string {
+	return net.JoinHostPort(TrimBrackets(host), port)
+}
+
+// MySQLHostArgs returns the connection arguments of the mysql and mariadb clients,
+// --socket for a unix socket, --host and --port otherwise
+func MySQLHostArgs(host, port string) []string {
+	if IsSocket(host) {
+		return []string{fmt.Sprintf("--socket=%s", host)}
+	}
+
+	return []string{
+		fmt.Sprintf("--host=%s", TrimBrackets(host)),
+		fmt.Sprintf("--port=%s", port),
+	}
+}
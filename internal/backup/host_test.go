@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHostPort(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		port string
+		want string
+	}{
+		{name: "Host name", host: "db.internal", port: "5432", want: "db.internal:5432"},
+		{name: "IPv4", host: "10.0.0.2", port: "3306", want: "10.0.0.2:3306"},
+		{name: "IPv6", host: "::1", port: "5432", want: "[::1]:5432"},
+		{name: "Bracketed IPv6", host: "[2001:db8::1]", port: "3306", want: "[2001:db8::1]:3306"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HostPort(tt.host, tt.port); got != tt.want {
+				t.Errorf("HostPort() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMySQLHostArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		port string
+		want []string
+	}{
+		{name: "Host name", host: "db.internal", port: "3306", want: []string{"--host=db.internal", "--port=3306"}},
+		{name: "Bracketed IPv6", host: "[::1]", port: "3306", want: []string{"--host=::1", "--port=3306"}},
+		{name: "Unix socket", host: "/var/run/mysqld/mysqld.sock", port: "3306", want: []string{"--socket=/var/run/mysqld/mysqld.sock"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MySQLHostArgs(tt.host, tt.port); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MySQLHostArgs() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
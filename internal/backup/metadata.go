@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MetadataSuffix is appended to the backup name to store its metadata next to it
+const MetadataSuffix = ".meta.json"
+
+// OplogSlicePrefix starts the names of the MongoDB oplog slices archived between full dumps
+const OplogSlicePrefix = "oplog_"
+
+// Metadata describes a backup, it is stored next to the backup as JSON
+type Metadata struct {
+	Engine    string           `json:"engine"`              // database engine
+	Flavor    string           `json:"flavor,omitempty"`    // flavor of a server speaking the PostgresSQL protocol
+	Database  string           `json:"database"`            // database name
+	Backup    string           `json:"backup"`              // backup name
+	CreatedAt time.Time        `json:"created_at"`          // time the backup was taken
+	Binlog    *BinlogPosition  `json:"binlog,omitempty"`    // binary log position of a MySQL or MariaDB backup
+	Oplog     *OplogPosition   `json:"oplog,omitempty"`     // oplog position of a MongoDB backup
+	Redis     *RedisDataset    `json:"redis,omitempty"`     // dataset of a Redis backup
+	Snapshot  *SearchSnapshot  `json:"snapshot,omitempty"`  // Elasticsearch or OpenSearch snapshot
+	Cockroach *CockroachBackup `json:"cockroach,omitempty"` // CockroachDB backup
+}
+
+// BinlogPosition holds the binary log coordinates a MySQL or MariaDB backup is consistent with
+type BinlogPosition struct {
+	File     string `json:"file"`               // binary log file
+	Position uint64 `json:"position"`           // position in the binary log file
+	GTIDSet  string `json:"gtid_set,omitempty"` // GTID set, when GTIDs are enabled
+}
+
+// OplogPosition holds the timestamp of the last oplog entry a MongoDB backup is consistent with
+type OplogPosition struct {
+	T uint32 `json:"t"` // seconds since the Unix epoch
+	I uint32 `json:"i"` // ordinal of the operation within the second
+}
+
+// After reports whether the position comes after the other one
+func (p OplogPosition) After(other OplogPosition) bool {
+	return p.T > other.T || (p.T == other.T && p.I > other.I)
+}
+
+// RedisDataset describes the dataset of a Redis backup, which holds every database of the server
+type RedisDataset struct {
+	Version   string           `json:"version"`             // version of the Redis server
+	Databases int              `json:"databases,omitempty"` // number of databases of the server, unknown when CONFIG is disabled
+	Keys      map[string]int64 `json:"keys,omitempty"`      // number of keys of each non-empty database
+}
+
+// SearchSnapshot describes an Elasticsearch or OpenSearch snapshot, the data staying in the snapshot repository of the cluster
+type SearchSnapshot struct {
+	Cluster      string   `json:"cluster"`                 // name of the cluster
+	Version      string   `json:"version"`                 // version of the cluster
+	Repository   string   `json:"repository"`              // snapshot repository
+	Snapshot     string   `json:"snapshot"`                // snapshot name
+	State        string   `json:"state"`                   // state of the completed snapshot
+	Indices      []string `json:"indices"`                 // indices of the snapshot
+	Shards       int      `json:"shards"`                  // number of shards of the snapshot
+	FailedShards int      `json:"failed_shards,omitempty"` // number of shards which could not be snapshotted
+}
+
+// Marshal encodes the metadata as indented JSON
+func (m *Metadata) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup metadata: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// ParseMetadata decodes the metadata of a backup
+func ParseMetadata(data []byte) (*Metadata, error) {
+	var m Metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode backup metadata: %w", err)
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,34 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadata_Marshal(t *testing.T) {
+	metadata := &Metadata{
+		Engine:    "mysql",
+		Database:  "app",
+		Backup:    "app.sql.gz",
+		CreatedAt: time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC),
+		Binlog:    &BinlogPosition{File: "binlog.000012", Position: 2971},
+	}
+
+	data, err := metadata.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	got, err := ParseMetadata(data)
+	if err != nil {
+		t.Fatalf("ParseMetadata() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, metadata) {
+		t.Errorf("ParseMetadata() got = %+v, want %+v", got, metadata)
+	}
+
+	if _, err := ParseMetadata([]byte("not json")); err == nil {
+		t.Errorf("ParseMetadata() expected an error")
+	}
+}
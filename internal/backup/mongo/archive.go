@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/compression"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// ArchiveExtension ends the names of the archives written by mongodump --archive
+	ArchiveExtension = ".archive"
+	// GzipArchiveExtension ends the names of the archives written by mongodump --archive --gzip,
+	// which compresses the collections inside the archive: the archive itself is not a gzip stream
+	GzipArchiveExtension = ".gz.archive"
+)
+
+// archiveMagic starts every archive written by mongodump, the 0x8199e26d magic number in little endian
+var archiveMagic = []byte{0x6d, 0xe2, 0x99, 0x81}
+
+// ArchiveName returns the name of an archive, with the extensions of the archive and of its
+// compression: by mongodump when gzip is set, by Sentinel with the algorithm otherwise. The
+// extensions the name already ends with are not repeated.
+func ArchiveName(name string, gzip bool, algorithm string) string {
+	name = compression.TrimExtension(name)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, GzipArchiveExtension), ArchiveExtension)
+
+	if gzip {
+		return name + GzipArchiveExtension
+	}
+
+	return compression.AppendExtension(name+ArchiveExtension, algorithm)
+}
+
+// IsGzipArchive reports whether the collections of the archive were compressed by mongodump --gzip:
+// the archives named with GzipArchiveExtension, and those named like gzip files although they are
+// archives, as older versions of Sentinel named them
+func IsGzipArchive(file string) bool {
+	if strings.HasSuffix(file, GzipArchiveExtension) {
+		return true
+	}
+
+	if compression.FromExtension(file) != "gzip" {
+		return false
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, len(archiveMagic))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return false
+	}
+
+	return bytes.Equal(head, archiveMagic)
+}
+
+// OpenArchive opens an archive for mongorestore --archive, decompressing it on the fly when
+// Sentinel compressed it. The archives compressed by mongodump are read as is, mongorestore
+// decompresses their collections with --gzip.
+//
+// Returns a reader of the archive, which closes the file when closed.
+func OpenArchive(file string) (io.ReadCloser, error) {
+	if IsGzipArchive(file) {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open backup file: %w", err)
+		}
+		return f, nil
+	}
+
+	return compression.OpenFile(file)
+}
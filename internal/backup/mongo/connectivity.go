@@ -3,32 +3,113 @@ package mongo
 import (
 	"context"
 	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
 	"log"
 )
 
-// CheckConnectivity checks the connectivity to the MongoDB instance
-func CheckConnectivity(uri string) error {
+// Server describes the MongoDB instance Sentinel is connected to
+type Server struct {
+	ReplicaSet string // name of the replica set, empty for standalone instances and mongos routers
+}
+
+// CheckConnectivity checks the connectivity to the MongoDB instance,
+// enforcing TLS when the options require it.
+//
+// Returns the description of the instance, or an error if it cannot be reached.
+func CheckConnectivity(uri string, tlsOptions *backup.TLSOptions) (*Server, error) {
+	client, err := connect(uri, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect(client)
+
+	return describe(client)
+}
+
+// describe runs the hello command to tell replica set members from other instances
+func describe(client *mongo.Client) (*Server, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+	}
+	if err := client.Database("admin").RunCommand(context.TODO(), bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return nil, fmt.Errorf("failed to describe the MongoDB instance - %w", err)
+	}
+
+	return &Server{ReplicaSet: hello.SetName}, nil
+}
+
+// ListDatabases lists the databases of the MongoDB instance.
+//
+// Returns the database names, or an error if the instance cannot be reached or queried.
+func ListDatabases(uri string, tlsOptions *backup.TLSOptions) ([]string, error) {
+	client, err := connect(uri, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect(client)
+
+	databases, err := client.ListDatabaseNames(context.TODO(), bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases - %w", err)
+	}
+
+	return databases, nil
+}
+
+// ListCollections lists the collections of a database of the MongoDB instance.
+//
+// Returns the collection names, or an error if the instance cannot be reached or queried.
+func ListCollections(uri string, tlsOptions *backup.TLSOptions, database string) ([]string, error) {
+	client, err := connect(uri, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect(client)
+
+	collections, err := client.Database(database).ListCollectionNames(context.TODO(), bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections - %w", err)
+	}
+
+	return collections, nil
+}
+
+// connect connects to the MongoDB instance and pings it
+func connect(uri string, tlsOptions *backup.TLSOptions) (*mongo.Client, error) {
 	serverAPI := options.ServerAPI(options.ServerAPIVersion1)             // enable the server API version 1
 	opts := options.Client().ApplyURI(uri).SetServerAPIOptions(serverAPI) // set the server API options
 
+	if tlsOptions.MongoTLS() {
+		// the driver verifies the certificate against the host of each member
+		tlsConfig, err := tlsOptions.Config("")
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+
 	// create a new client and connect to the MongoDB instance
 	client, err := mongo.Connect(opts)
 	if err != nil {
-		return fmt.Errorf("failed to connect to the MongoDB instance - %w", err)
+		return nil, fmt.Errorf("failed to connect to the MongoDB instance - %w", err)
 	}
-	defer func() {
-		if err = client.Disconnect(context.TODO()); err != nil {
-			log.Panic(err)
-		}
-	}()
 
 	// ping the MongoDB instance
 	if err = client.Ping(context.TODO(), readpref.Primary()); err != nil {
-		return fmt.Errorf("failed to ping the MongoDB instance - %w", err)
+		disconnect(client)
+		return nil, fmt.Errorf("failed to ping the MongoDB instance - %w", err)
 	}
 
-	return nil
+	return client, nil
+}
+
+// disconnect closes the connection to the MongoDB instance
+func disconnect(client *mongo.Client) {
+	if err := client.Disconnect(context.TODO()); err != nil {
+		log.Panic(err)
+	}
 }
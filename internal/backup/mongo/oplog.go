@@ -0,0 +1,171 @@
+package mongo
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+// Oplog tails the oplog of a replica set member
+type Oplog struct {
+	client *mongo.Client
+	cursor *mongo.Cursor
+}
+
+// TailOplog opens a tailable cursor on the oplog of the replica set, returning the entries
+// written after the position. When the position is nil, the tailing starts from the last entry.
+//
+// Returns an error if the instance is not a replica set member, or if the oplog was truncated
+// past the position, in which case a new full dump is required.
+func TailOplog(uri string, tlsOptions *backup.TLSOptions, after *backup.OplogPosition) (*Oplog, error) {
+	client, err := connect(uri, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	oplog, err := tailOplog(client, after)
+	if err != nil {
+		disconnect(client)
+		return nil, err
+	}
+
+	return oplog, nil
+}
+
+func tailOplog(client *mongo.Client, after *backup.OplogPosition) (*Oplog, error) {
+	server, err := describe(client)
+	if err != nil {
+		return nil, err
+	}
+	if server.ReplicaSet == "" {
+		return nil, fmt.Errorf("the oplog is only available on replica set members")
+	}
+
+	ctx := context.TODO()
+	collection := client.Database("local").Collection("oplog.rs")
+
+	// the oplog is a capped collection, its natural order is the order of the entries
+	first, err := oplogEdge(ctx, collection, 1)
+	if err != nil {
+		return nil, err
+	}
+	last, err := oplogEdge(ctx, collection, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	if after == nil {
+		after = &last
+	} else if first.After(*after) {
+		return nil, fmt.Errorf("the oplog was truncated after position %d:%d, take a new full dump", after.T, after.I)
+	}
+
+	filter := bson.D{{Key: "ts", Value: bson.D{{Key: "$gt", Value: bson.Timestamp{T: after.T, I: after.I}}}}}
+	opts := options.Find().SetCursorType(options.TailableAwait).SetMaxAwaitTime(time.Second)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tail the oplog - %w", err)
+	}
+
+	return &Oplog{client: client, cursor: cursor}, nil
+}
+
+// oplogEdge returns the position of the first (1) or last (-1) entry of the oplog
+func oplogEdge(ctx context.Context, collection *mongo.Collection, order int) (backup.OplogPosition, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "$natural", Value: order}})
+
+	raw, err := collection.FindOne(ctx, bson.D{}, opts).Raw()
+	if err != nil {
+		return backup.OplogPosition{}, fmt.Errorf("failed to read the oplog - %w", err)
+	}
+
+	return EntryPosition(raw)
+}
+
+// Next waits up to a second for the next oplog entry.
+//
+// Returns the entry, nil when none was written in the meantime, or an error if the cursor died.
+func (o *Oplog) Next(ctx context.Context) (bson.Raw, error) {
+	if o.cursor.TryNext(ctx) {
+		return o.cursor.Current, nil
+	}
+
+	if err := o.cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to tail the oplog - %w", err)
+	}
+
+	if o.cursor.ID() == 0 {
+		return nil, fmt.Errorf("the oplog cursor was closed by the server")
+	}
+
+	return nil, nil
+}
+
+// Close closes the cursor and the connection to the MongoDB instance
+func (o *Oplog) Close() {
+	_ = o.cursor.Close(context.TODO())
+	disconnect(o.client)
+}
+
+// EntryPosition returns the position of an oplog entry, from its ts field
+func EntryPosition(entry bson.Raw) (backup.OplogPosition, error) {
+	t, i, ok := entry.Lookup("ts").TimestampOK()
+	if !ok {
+		return backup.OplogPosition{}, fmt.Errorf("oplog entry without timestamp")
+	}
+
+	return backup.OplogPosition{T: t, I: i}, nil
+}
+
+// LastOplogPosition returns the position of the last entry of an oplog dump, the BSON
+// documents of the entries being written one after the other as in mongodump oplog.bson.
+//
+// Returns nil when the dump holds no entry, or an error if it is malformed.
+func LastOplogPosition(data []byte) (*backup.OplogPosition, error) {
+	var last bson.Raw
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("truncated oplog dump")
+		}
+
+		size := int(binary.LittleEndian.Uint32(data))
+		if size < 5 || size > len(data) {
+			return nil, fmt.Errorf("truncated oplog dump")
+		}
+
+		last, data = data[:size], data[size:]
+	}
+
+	if last == nil {
+		return nil, nil
+	}
+
+	position, err := EntryPosition(last)
+	if err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// LastOplogEntry returns the position of the last entry of the oplog of the replica set
+func LastOplogEntry(uri string, tlsOptions *backup.TLSOptions) (*backup.OplogPosition, error) {
+	client, err := connect(uri, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect(client)
+
+	last, err := oplogEdge(context.TODO(), client.Database("local").Collection("oplog.rs"), -1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &last, nil
+}
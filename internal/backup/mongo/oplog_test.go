@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"reflect"
+	"testing"
+)
+
+func TestLastOplogPosition(t *testing.T) {
+	entry := func(ts bson.Timestamp) []byte {
+		data, err := bson.Marshal(bson.D{{Key: "ts", Value: ts}, {Key: "op", Value: "i"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	first, second := entry(bson.Timestamp{T: 1714521600, I: 1}), entry(bson.Timestamp{T: 1714521600, I: 2})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    *backup.OplogPosition
+		wantErr bool
+	}{
+		{name: "Empty dump", data: nil, want: nil},
+		{name: "Last entry", data: append(append([]byte{}, first...), second...), want: &backup.OplogPosition{T: 1714521600, I: 2}},
+		{name: "Truncated dump", data: append(append([]byte{}, first...), second[:10]...), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LastOplogPosition(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LastOplogPosition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LastOplogPosition() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
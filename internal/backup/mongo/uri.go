@@ -0,0 +1,46 @@
+package mongo
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// URIHost returns the host and port of a single host MongoDB URI, the port defaulting to 27017.
+//
+// Returns an error for SRV URIs and URIs listing several hosts, which cannot be reached through a single address.
+func URIHost(uri string) (string, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid MongoDB URI: %w", err)
+	}
+
+	if u.Scheme != "mongodb" || strings.Contains(u.Host, ",") {
+		return "", "", fmt.Errorf("only single host mongodb:// URIs can be reached through a single address")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "27017"
+	}
+
+	return u.Hostname(), port, nil
+}
+
+// WithHost returns the MongoDB URI pointing at another host and port. The connection is made
+// direct so that the driver does not try to reach the replica set members by their own names.
+func WithHost(uri, host, port string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid MongoDB URI: %w", err)
+	}
+
+	u.Host = net.JoinHostPort(host, port)
+
+	query := u.Query()
+	query.Set("directConnection", "true")
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// mysqlStringEscaper escapes the characters of a MySQL string literal, like mysqldump
+var mysqlStringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x1a", `\Z`,
+)
+
+// MySQLIdentifier quotes a MySQL identifier with backticks
+func MySQLIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// MySQLValue renders a column value as a MySQL literal, according to the database type name of
+// the column reported by the driver: numbers as they are, binary values as hexadecimal and
+// everything else as an escaped string.
+//
+// Returns NULL for a nil value.
+func MySQLValue(typeName string, value []byte) string {
+	if value == nil {
+		return "NULL"
+	}
+
+	switch strings.TrimPrefix(typeName, "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "DECIMAL", "FLOAT", "DOUBLE", "YEAR":
+		return string(value)
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BIT", "GEOMETRY":
+		if len(value) == 0 {
+			return "''"
+		}
+		return "0x" + strings.ToUpper(hex.EncodeToString(value))
+	default:
+		return "'" + mysqlStringEscaper.Replace(string(value)) + "'"
+	}
+}
@@ -0,0 +1,49 @@
+package backup
+
+import "testing"
+
+func TestMySQLIdentifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		ident string
+		want  string
+	}{
+		{name: "Plain name", ident: "orders", want: "`orders`"},
+		{name: "Backtick in the name", ident: "odd`name", want: "`odd``name`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MySQLIdentifier(tt.ident); got != tt.want {
+				t.Errorf("MySQLIdentifier() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMySQLValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeName string
+		value    []byte
+		want     string
+	}{
+		{name: "NULL", typeName: "VARCHAR", value: nil, want: "NULL"},
+		{name: "Integer", typeName: "INT", value: []byte("42"), want: "42"},
+		{name: "Unsigned integer", typeName: "UNSIGNED BIGINT", value: []byte("18446744073709551615"), want: "18446744073709551615"},
+		{name: "Decimal", typeName: "DECIMAL", value: []byte("-12.50"), want: "-12.50"},
+		{name: "Empty string", typeName: "VARCHAR", value: []byte{}, want: "''"},
+		{name: "Escaped string", typeName: "TEXT", value: []byte("it's a \\ test\n\x00\x1a"), want: `'it\'s a \\ test\n\0\Z'`},
+		{name: "Date time", typeName: "DATETIME", value: []byte("2024-05-01 02:00:00"), want: "'2024-05-01 02:00:00'"},
+		{name: "JSON", typeName: "JSON", value: []byte(`{"a": "b"}`), want: `'{"a": "b"}'`},
+		{name: "Binary", typeName: "BLOB", value: []byte{0x00, 0xff, 0x10}, want: "0x00FF10"},
+		{name: "Empty binary", typeName: "VARBINARY", value: []byte{}, want: "''"},
+		{name: "Bit", typeName: "BIT", value: []byte{0x01}, want: "0x01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MySQLValue(tt.typeName, tt.value); got != tt.want {
+				t.Errorf("MySQLValue() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package backup
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flavors of the servers speaking the PostgresSQL protocol
+const (
+	FlavorPostgres    = "postgres"    // PostgresSQL
+	FlavorCockroachDB = "cockroachdb" // CockroachDB, backed up with BACKUP INTO since pg_dump does not support it
+	FlavorTimescaleDB = "timescaledb" // PostgresSQL with the TimescaleDB extension, restored between its restore hooks
+)
+
+// PostgresServer describes the server speaking the PostgresSQL protocol a backup is taken from or restored to
+type PostgresServer struct {
+	Version          string // server version, as returned by version()
+	Flavor           string // server flavor, see the Flavor constants
+	TimescaleVersion string // version of the TimescaleDB extension installed in the database
+}
+
+// CockroachBackup describes a CockroachDB backup, the data staying in the backup collection of the cluster
+type CockroachBackup struct {
+	Collection string `json:"collection"`       // URI of the backup collection, its secrets redacted
+	Path       string `json:"path"`             // subdirectory of the backup in the collection
+	JobID      int64  `json:"job_id,omitempty"` // identifier of the backup job
+	Rows       int64  `json:"rows,omitempty"`   // number of rows backed up
+	Bytes      int64  `json:"bytes,omitempty"`  // number of bytes backed up
+}
+
+// cockroachPathFormat is the layout of the subdirectory BACKUP INTO writes a backup to in its
+// collection, named after the time the backup is taken as of
+const cockroachPathFormat = "/2006/01/02-150405.00"
+
+// CockroachBackupPath returns the subdirectory of the collection BACKUP INTO writes a backup
+// taken as of the timestamp to, the timestamp being a cluster_logical_timestamp() decimal of
+// nanoseconds since the epoch, e.g. /2024/05/01-020000.12 for 1714528800123456789.0000000000.
+//
+// Returns the subdirectory, or an error if the timestamp is invalid.
+func CockroachBackupPath(timestamp string) (string, error) {
+	wall, _, _ := strings.Cut(timestamp, ".")
+	nanos, err := strconv.ParseInt(wall, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid CockroachDB timestamp %q", timestamp)
+	}
+
+	return time.Unix(0, nanos).UTC().Format(cockroachPathFormat), nil
+}
+
+// ParsePostgresFlavor returns the flavor of the server from the result of version(),
+// the TimescaleDB extension being detected separately
+func ParsePostgresFlavor(version string) string {
+	if strings.Contains(version, "CockroachDB") {
+		return FlavorCockroachDB
+	}
+
+	return FlavorPostgres
+}
+
+// RedactURI hides the password and the credentials passed as query parameters of a
+// storage URI, e.g. AWS_SECRET_ACCESS_KEY, before it is written to the metadata
+func RedactURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "redacted"
+	}
+
+	query := u.Query()
+	for name := range query {
+		upper := strings.ToUpper(name)
+		for _, secret := range []string{"KEY", "SECRET", "TOKEN", "CREDENTIALS", "PASSWORD", "SIG"} {
+			if strings.Contains(upper, secret) {
+				query.Set(name, "redacted")
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.Redacted()
+}
@@ -0,0 +1,24 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+)
+
+// RecoveryTargetLayout is the layout of the recovery targets given in the local time zone
+const RecoveryTargetLayout = "2006-01-02 15:04:05"
+
+// ParseRecoveryTarget parses the time a point-in-time restore stops at, either as RFC 3339
+// or as "2006-01-02 15:04:05" in the local time zone
+func ParseRecoveryTarget(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(RecoveryTargetLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid recovery target %q, expected RFC 3339 or %q", value, RecoveryTargetLayout)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,219 @@
+package redis
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeout bounds the connection and every read and write, the server sends
+// newlines while a snapshot is written so that transfers do not time out
+const timeout = 60 * time.Second
+
+// Options holds the parameters of the Redis connection
+type Options struct {
+	Host     string             // Redis host, or unix socket path
+	Port     string             // Redis port
+	Username string             // ACL user, empty for the default user
+	Password string             // password of the user, empty when authentication is disabled
+	TLS      *backup.TLSOptions // TLS options, Redis has no opportunistic TLS
+}
+
+// Client is a minimal Redis client speaking the RESP2 protocol, enough to
+// inspect the server and transfer its snapshots
+type Client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Error is an error reply of the server
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Dial connects to the Redis server and authenticates with the user and password of the options.
+//
+// Returns the client, or an error if the server cannot be reached or rejects the credentials.
+func Dial(o *Options) (*Client, error) {
+	network, address := "tcp", backup.HostPort(o.Host, o.Port)
+	if backup.IsSocket(o.Host) {
+		network, address = "unix", o.Host
+	}
+
+	conn, err := net.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis - %w", err)
+	}
+
+	if o.TLS.Required() {
+		cfg, err := o.TLS.Config(backup.TrimBrackets(o.Host))
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tls.Client(conn, cfg)
+	}
+
+	c := &Client{conn: conn, reader: bufio.NewReader(&deadlineReader{conn: conn})}
+
+	if o.Password != "" {
+		args := []string{"AUTH", o.Password}
+		if o.Username != "" {
+			args = []string{"AUTH", o.Username, o.Password}
+		}
+
+		if _, err := c.Do(args...); err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("failed to authenticate to redis - %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Do sends the command and reads its reply: a string, an int64, a []interface{},
+// nil, or an Error when the server replies with an error
+func (c *Client) Do(args ...string) (interface{}, error) {
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+
+	reply, err := c.readReply()
+	if err != nil {
+		return nil, err
+	}
+
+	if e, ok := reply.(Error); ok {
+		return nil, e
+	}
+
+	return reply, nil
+}
+
+// String sends the command and reads its reply as a string
+func (c *Client) String(args ...string) (string, error) {
+	reply, err := c.Do(args...)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := reply.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	default:
+		return "", fmt.Errorf("unexpected reply to %s: %v", args[0], reply)
+	}
+}
+
+// send writes the command as an array of bulk strings
+func (c *Client) send(args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return fmt.Errorf("failed to send %s command - %w", args[0], err)
+	}
+
+	return nil
+}
+
+// readReply reads a reply of the server
+func (c *Client) readReply() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, fmt.Errorf("invalid reply: empty line")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer reply: %s", line)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk string reply: %s", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, n+2) // the string is followed by CRLF
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, fmt.Errorf("failed to read reply - %w", err)
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid array reply: %s", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("invalid reply: %q", line)
+	}
+}
+
+// readLine reads a line of the protocol, without its line ending
+func (c *Client) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read reply - %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// deadlineReader extends the read deadline of the connection before each read,
+// so that long transfers only time out when the server stops sending data
+type deadlineReader struct {
+	conn net.Conn
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if err := r.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	return r.conn.Read(p)
+}
@@ -0,0 +1,279 @@
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer starts a Redis stand-in writing the raw reply of the handler to each command.
+//
+// Returns the options connecting to it.
+func fakeServer(t *testing.T, handler func(args []string) string) *Options {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	var mu sync.Mutex
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				server := &Client{conn: conn, reader: bufio.NewReader(conn)}
+				for {
+					reply, err := server.readReply()
+					if err != nil {
+						return
+					}
+
+					var args []string
+					for _, arg := range reply.([]interface{}) {
+						args = append(args, arg.(string))
+					}
+
+					mu.Lock()
+					response := handler(args)
+					mu.Unlock()
+					if _, err := conn.Write([]byte(response)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return &Options{Host: host, Port: port}
+}
+
+func TestDial(t *testing.T) {
+	var auth []string
+	o := fakeServer(t, func(args []string) string {
+		if args[0] == "AUTH" {
+			auth = args
+			if args[len(args)-1] != "secret" {
+				return "-WRONGPASS invalid username-password pair\r\n"
+			}
+		}
+		return "+OK\r\n"
+	})
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantAuth []string
+		wantErr  bool
+	}{
+		{name: "No authentication", wantAuth: nil},
+		{name: "Default user", password: "secret", wantAuth: []string{"AUTH", "secret"}},
+		{name: "ACL user", username: "backup", password: "secret", wantAuth: []string{"AUTH", "backup", "secret"}},
+		{name: "Wrong password - error expected", username: "backup", password: "wrong", wantAuth: []string{"AUTH", "backup", "wrong"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth = nil
+			client, err := Dial(&Options{Host: o.Host, Port: o.Port, Username: tt.username, Password: tt.password})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Dial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				_ = client.Close()
+			}
+			if !reflect.DeepEqual(auth, tt.wantAuth) {
+				t.Errorf("Dial() AUTH = %v, want %v", auth, tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestInfo(t *testing.T) {
+	info := "# Keyspace\r\ndb0:keys=12,expires=1,avg_ttl=0\r\ndb3:keys=4,expires=0,avg_ttl=0\r\n"
+	o := fakeServer(t, func(args []string) string {
+		switch args[0] {
+		case "INFO":
+			return "$" + strconv.Itoa(len(info)) + "\r\n" + info + "\r\n"
+		case "CONFIG":
+			return "*2\r\n$9\r\ndatabases\r\n$2\r\n16\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	})
+
+	client, err := Dial(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	fields, err := client.Info("keyspace")
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if got, want := Keyspace(fields), map[string]int64{"db0": 12, "db3": 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keyspace() got = %v, want %v", got, want)
+	}
+
+	databases, err := client.Config("databases")
+	if err != nil || databases != "16" {
+		t.Errorf("Config() got = %v, %v, want 16", databases, err)
+	}
+
+	if _, err := client.Do("FLUSHALL"); err == nil {
+		t.Errorf("Do() error = nil, want the error reply")
+	}
+}
+
+func TestSyncRDB(t *testing.T) {
+	rdb := "REDIS0011" + strings.Repeat("x", 100*1024)
+	mark := strings.Repeat("m", 40)
+
+	tests := []struct {
+		name    string
+		replies map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Snapshot with its size",
+			replies: map[string]string{"REPLCONF": "+OK\r\n", "SYNC": "\n\n$" + strconv.Itoa(len(rdb)) + "\r\n" + rdb},
+			want:    rdb,
+		},
+		{
+			name:    "Diskless snapshot followed by the replication stream",
+			replies: map[string]string{"REPLCONF": "-ERR Unrecognized REPLCONF option: rdb-only\r\n", "SYNC": "\n$EOF:" + mark + "\r\n" + rdb + mark + "*1\r\n$4\r\nPING\r\n"},
+			want:    rdb,
+		},
+		{
+			name:    "Sync refused - error expected",
+			replies: map[string]string{"REPLCONF": "+OK\r\n", "SYNC": "-NOPERM this user has no permissions to run the 'sync' command\r\n"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := Dial(fakeServer(t, func(args []string) string { return tt.replies[args[0]] }))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			var out bytes.Buffer
+			n, err := client.SyncRDB(&out)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SyncRDB() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if out.String() != tt.want || n != int64(len(tt.want)) {
+				t.Errorf("SyncRDB() got %d bytes, want %d", out.Len(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestBgSave(t *testing.T) {
+	saving, saveTime, polls := true, 100, 0
+	var commands []string
+	client, err := Dial(fakeServer(t, func(args []string) string {
+		commands = append(commands, args[0])
+		switch args[0] {
+		case "BGSAVE":
+			// a save is running when the first BGSAVE is sent
+			if saving {
+				return "-ERR Background save already in progress\r\n"
+			}
+			saving = true
+			return "+Background saving started\r\n"
+		case "INFO":
+			inProgress := 0
+			if saving {
+				polls++
+				if polls%2 == 0 {
+					saving = false
+					saveTime++
+				} else {
+					inProgress = 1
+				}
+			}
+			info := fmt.Sprintf("rdb_bgsave_in_progress:%d\r\nrdb_last_save_time:%d\r\nrdb_last_bgsave_status:ok\r\n", inProgress, saveTime)
+			return "$" + strconv.Itoa(len(info)) + "\r\n" + info + "\r\n"
+		case "CONFIG":
+			if args[2] == "dir" {
+				return "*2\r\n$3\r\ndir\r\n$10\r\n/data/save\r\n"
+			}
+			return "*2\r\n$10\r\ndbfilename\r\n$8\r\ndump.rdb\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	path, err := client.BgSave(time.Millisecond, time.Minute)
+	if err != nil {
+		t.Fatalf("BgSave() error = %v", err)
+	}
+	if path != "/data/save/dump.rdb" {
+		t.Errorf("BgSave() got = %v, want /data/save/dump.rdb", path)
+	}
+
+	// the running save is waited for, then a new one is triggered and waited for
+	if bgSaves := strings.Count(strings.Join(commands, " "), "BGSAVE"); bgSaves != 2 {
+		t.Errorf("BgSave() sent BGSAVE %d times, want 2", bgSaves)
+	}
+}
+
+func TestBgSave_timeout(t *testing.T) {
+	client, err := Dial(fakeServer(t, func(args []string) string {
+		if args[0] == "INFO" {
+			// the save never completes
+			info := "rdb_bgsave_in_progress:1\r\nrdb_last_save_time:100\r\n"
+			return "$" + strconv.Itoa(len(info)) + "\r\n" + info + "\r\n"
+		}
+		return "+Background saving started\r\n"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err := client.BgSave(time.Millisecond, 20*time.Millisecond); err == nil {
+		t.Errorf("BgSave() error = nil, want a timeout")
+	}
+}
+
+func Test_saveCompleted(t *testing.T) {
+	before := map[string]string{"rdb_last_save_time": "100", "rdb_changes_since_last_save": "42"}
+
+	tests := []struct {
+		name  string
+		after map[string]string
+		want  bool
+	}{
+		{name: "Save time changed", after: map[string]string{"rdb_last_save_time": "101", "rdb_changes_since_last_save": "0"}, want: true},
+		{name: "Save within the same second", after: map[string]string{"rdb_last_save_time": "100", "rdb_changes_since_last_save": "3"}, want: true},
+		{name: "Count of saves changed", after: map[string]string{"rdb_saves": "1", "rdb_last_save_time": "100", "rdb_changes_since_last_save": "42"}, want: true},
+		{name: "No save", after: map[string]string{"rdb_last_save_time": "100", "rdb_changes_since_last_save": "50"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := saveCompleted(before, tt.after); got != tt.want {
+				t.Errorf("saveCompleted() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Info runs the INFO command for the section.
+//
+// Returns the fields of the section, or an error if the command fails.
+func (c *Client) Info(section string) (map[string]string, error) {
+	reply, err := c.String("INFO", section)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redis %s info - %w", section, err)
+	}
+
+	return parseInfo(reply), nil
+}
+
+// parseInfo parses the "field:value" lines of an INFO reply, skipping the section headers
+func parseInfo(reply string) map[string]string {
+	fields := map[string]string{}
+
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			fields[name] = value
+		}
+	}
+
+	return fields
+}
+
+// Keyspace returns the number of keys of each database listed in the keyspace info,
+// e.g. "db0:keys=12,expires=0,avg_ttl=0". Empty databases are not listed.
+func Keyspace(info map[string]string) map[string]int64 {
+	keys := map[string]int64{}
+
+	for name, value := range info {
+		if !strings.HasPrefix(name, "db") {
+			continue
+		}
+
+		for _, stat := range strings.Split(value, ",") {
+			if count, ok := strings.CutPrefix(stat, "keys="); ok {
+				if n, err := strconv.ParseInt(count, 10, 64); err == nil {
+					keys[name] = n
+				}
+			}
+		}
+	}
+
+	return keys
+}
+
+// Config returns the value of a configuration parameter.
+// CONFIG is often disabled or renamed on managed servers, callers should not rely on it.
+func (c *Client) Config(parameter string) (string, error) {
+	reply, err := c.Do("CONFIG", "GET", parameter)
+	if err != nil {
+		return "", fmt.Errorf("failed to get redis %s configuration - %w", parameter, err)
+	}
+
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return "", fmt.Errorf("unknown redis configuration parameter: %s", parameter)
+	}
+
+	value, ok := items[1].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected redis %s configuration: %v", parameter, items[1])
+	}
+
+	return value, nil
+}
@@ -0,0 +1,193 @@
+package redis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyncRDB transfers a snapshot of the dataset the way replicas do on a full resynchronization:
+// the server forks to write the snapshot and streams it to w. The connection is a replication
+// link afterward and must be closed.
+//
+// Returns the size of the snapshot, or an error if the transfer fails.
+func (c *Client) SyncRDB(w io.Writer) (int64, error) {
+	// only ask for the snapshot, not for the replication stream following it, older servers reject it
+	if _, err := c.Do("REPLCONF", "rdb-only", "1"); err != nil {
+		var replyErr Error
+		if !errors.As(err, &replyErr) {
+			return 0, err
+		}
+	}
+
+	if err := c.send("SYNC"); err != nil {
+		return 0, err
+	}
+
+	// the server sends newlines while the snapshot is written, then its size
+	var line string
+	for line == "" {
+		var err error
+		if line, err = c.readLine(); err != nil {
+			return 0, err
+		}
+	}
+
+	switch {
+	case line[0] == '-':
+		return 0, fmt.Errorf("failed to sync redis snapshot - %s", line[1:])
+	case strings.HasPrefix(line, "$EOF:"):
+		// diskless replication streams the snapshot without knowing its size, up to a random mark
+		return copyUntilMark(w, c.reader, []byte(line[len("$EOF:"):]))
+	case line[0] == '$':
+		size, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid redis snapshot size: %s", line)
+		}
+
+		n, err := io.CopyN(w, c.reader, size)
+		if err != nil {
+			return n, fmt.Errorf("failed to transfer redis snapshot - %w", err)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("unexpected reply to SYNC: %q", line)
+	}
+}
+
+// copyUntilMark copies r to w until the mark, which is not copied.
+//
+// Returns the number of bytes copied.
+func copyUntilMark(w io.Writer, r io.Reader, mark []byte) (int64, error) {
+	var written int64
+	var pending []byte
+	buf := make([]byte, 32*1024)
+
+	for {
+		n, err := r.Read(buf)
+		pending = append(pending, buf[:n]...)
+
+		if i := bytes.Index(pending, mark); i >= 0 {
+			m, werr := w.Write(pending[:i])
+			return written + int64(m), werr
+		}
+
+		// keep the bytes which may be the start of the mark
+		if keep := len(mark) - 1; len(pending) > keep {
+			m, werr := w.Write(pending[:len(pending)-keep])
+			written += int64(m)
+			if werr != nil {
+				return written, werr
+			}
+			pending = append(pending[:0], pending[len(pending)-keep:]...)
+		}
+
+		if err != nil {
+			return written, fmt.Errorf("failed to transfer redis snapshot - %w", err)
+		}
+	}
+}
+
+// BgSave triggers a background save of the dataset and waits for it to complete, polling the
+// server every interval for at most the timeout. A save already running is waited for before
+// triggering a new one, so that the snapshot holds the writes made before the call.
+//
+// Returns the path of the RDB file on the host of the server, from its dir and dbfilename
+// configuration, or an error if the save fails or does not complete in time.
+func (c *Client) BgSave(interval, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		before, err := c.Info("persistence")
+		if err != nil {
+			return "", err
+		}
+
+		// the save is scheduled when the append only file is being rewritten
+		_, err = c.Do("BGSAVE", "SCHEDULE")
+		running := err != nil && strings.Contains(err.Error(), "already in progress")
+		if err != nil && !running {
+			return "", fmt.Errorf("failed to trigger redis background save - %w", err)
+		}
+
+		if err := c.waitSave(before, interval, deadline); err != nil {
+			return "", err
+		}
+
+		if !running {
+			break
+		}
+	}
+
+	dir, err := c.Config("dir")
+	if err != nil {
+		return "", err
+	}
+
+	name, err := c.Config("dbfilename")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// waitSave waits until the running or scheduled save completes, comparing the persistence
+// info to the one read before the save, or until the deadline. The status of the last save
+// tells the failures, once the save was seen running or when the previous one succeeded.
+func (c *Client) waitSave(before map[string]string, interval time.Duration, deadline time.Time) error {
+	started := false
+
+	for {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("redis background save did not complete in time")
+		}
+
+		time.Sleep(interval)
+
+		info, err := c.Info("persistence")
+		if err != nil {
+			return err
+		}
+
+		if info["rdb_bgsave_in_progress"] == "1" {
+			started = true
+			continue
+		}
+		if info["aof_rewrite_in_progress"] == "1" {
+			continue
+		}
+
+		if saveCompleted(before, info) {
+			return nil
+		}
+
+		if info["rdb_last_bgsave_status"] == "err" && (started || before["rdb_last_bgsave_status"] != "err") {
+			return fmt.Errorf("redis background save failed, see the server logs")
+		}
+	}
+}
+
+// saveCompleted reports whether a save completed between the two persistence infos. The time of
+// the last save has a resolution of a second, a save completing within the second of the previous
+// one is told by the count of saves (Redis 7) or by the changes since the last save, which the
+// save resets to the writes made while it ran.
+func saveCompleted(before, after map[string]string) bool {
+	if saves := after["rdb_saves"]; saves != "" && saves != before["rdb_saves"] {
+		return true
+	}
+
+	if after["rdb_last_save_time"] != before["rdb_last_save_time"] {
+		return true
+	}
+
+	changesBefore, _ := strconv.ParseInt(before["rdb_changes_since_last_save"], 10, 64)
+	changesAfter, _ := strconv.ParseInt(after["rdb_changes_since_last_save"], 10, 64)
+
+	return changesAfter < changesBefore
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Options holds the parameters of the Elasticsearch or OpenSearch connection
+type Options struct {
+	Host     string             // cluster host
+	Port     string             // cluster port
+	Username string             // basic authentication user, empty when the security is disabled
+	Password string             // password of the user
+	TLS      *backup.TLSOptions // TLS options, HTTPS is used when the mode requires TLS
+}
+
+// Client calls the snapshot REST API shared by Elasticsearch and OpenSearch
+type Client struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+}
+
+// Cluster describes the cluster Sentinel is connected to
+type Cluster struct {
+	Name    string `json:"cluster_name"`
+	Version struct {
+		Number       string `json:"number"`
+		Distribution string `json:"distribution"` // opensearch, empty for Elasticsearch
+	} `json:"version"`
+}
+
+// NewClient returns a client of the cluster.
+//
+// Returns an error if the TLS certificates cannot be loaded.
+func NewClient(o *Options) (*Client, error) {
+	scheme := "http"
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if o.TLS.Required() {
+		cfg, err := o.TLS.Config(backup.TrimBrackets(o.Host))
+		if err != nil {
+			return nil, err
+		}
+		scheme, transport.TLSClientConfig = "https", cfg
+	}
+
+	return &Client{
+		baseURL:  fmt.Sprintf("%s://%s", scheme, backup.HostPort(o.Host, o.Port)),
+		username: o.Username,
+		password: o.Password,
+		http:     &http.Client{Transport: transport, Timeout: time.Minute},
+	}, nil
+}
+
+// Info describes the cluster, checking it can be reached with the credentials
+func (c *Client) Info() (*Cluster, error) {
+	var cluster Cluster
+	if err := c.do(http.MethodGet, "/", nil, &cluster); err != nil {
+		return nil, fmt.Errorf("failed to connect to the cluster - %w", err)
+	}
+
+	return &cluster, nil
+}
+
+// do sends the request with the JSON body and decodes the JSON response into out, when not nil.
+//
+// Returns an error holding the reason given by the cluster when the request fails.
+func (c *Client) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request - %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response - %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return responseError(resp.StatusCode, data)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response - %w", err)
+	}
+
+	return nil
+}
+
+// responseError builds the error of a failed request from the error object of the response
+func responseError(status int, data []byte) error {
+	var body struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil && body.Error.Reason != "" {
+		return fmt.Errorf("%s (%d): %s", body.Error.Type, status, body.Error.Reason)
+	}
+
+	return fmt.Errorf("%s: %s", http.StatusText(status), strings.TrimSpace(string(data)))
+}
+
+// escape escapes a repository or snapshot name in a path
+func escape(name string) string {
+	return url.PathEscape(name)
+}
@@ -0,0 +1,162 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot states reported by the cluster
+const (
+	StateInProgress = "IN_PROGRESS"
+	StateSuccess    = "SUCCESS"
+	StatePartial    = "PARTIAL"
+	StateFailed     = "FAILED"
+)
+
+// Snapshot describes a snapshot of the repository
+type Snapshot struct {
+	Name      string                 `json:"snapshot"`
+	State     string                 `json:"state"`
+	Indices   []string               `json:"indices"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	StartTime int64                  `json:"start_time_in_millis"`
+	EndTime   int64                  `json:"end_time_in_millis"`
+	Shards    struct {
+		Total      int `json:"total"`
+		Failed     int `json:"failed"`
+		Successful int `json:"successful"`
+	} `json:"shards"`
+	Failures []ShardFailure `json:"failures"`
+}
+
+// ShardFailure describes a shard which could not be snapshotted
+type ShardFailure struct {
+	Index   string `json:"index"`
+	ShardID int    `json:"shard_id"`
+	Reason  string `json:"reason"`
+}
+
+// Duration returns the time the snapshot took, until now while it is in progress
+func (s *Snapshot) Duration() time.Duration {
+	end := s.EndTime
+	if end == 0 {
+		end = time.Now().UnixMilli()
+	}
+
+	return time.Duration(end-s.StartTime) * time.Millisecond
+}
+
+// RegisterRepository registers the snapshot repository, or updates its settings when it exists
+func (c *Client) RegisterRepository(name, repositoryType string, settings map[string]string) error {
+	body := map[string]interface{}{"type": repositoryType, "settings": settings}
+	if err := c.do(http.MethodPut, "/_snapshot/"+escape(name), body, nil); err != nil {
+		return fmt.Errorf("failed to register snapshot repository %s - %w", name, err)
+	}
+
+	return nil
+}
+
+// VerifyRepository checks the repository exists and every node of the cluster can write to it
+func (c *Client) VerifyRepository(name string) error {
+	if err := c.do(http.MethodPost, "/_snapshot/"+escape(name)+"/_verify", nil, nil); err != nil {
+		return fmt.Errorf("failed to verify snapshot repository %s - %w", name, err)
+	}
+
+	return nil
+}
+
+// CreateSnapshot starts a snapshot of the indices, every index when empty, without the
+// global state of the cluster. The snapshot runs in the background, see WaitSnapshot.
+func (c *Client) CreateSnapshot(repository, name string, indices []string, metadata map[string]string) error {
+	body := map[string]interface{}{
+		"include_global_state": false,
+		"metadata":             metadata,
+	}
+	if len(indices) > 0 {
+		body["indices"] = strings.Join(indices, ",")
+	}
+
+	if err := c.do(http.MethodPut, "/_snapshot/"+escape(repository)+"/"+escape(name), body, nil); err != nil {
+		return fmt.Errorf("failed to start snapshot %s - %w", name, err)
+	}
+
+	return nil
+}
+
+// GetSnapshot describes a snapshot of the repository
+func (c *Client) GetSnapshot(repository, name string) (*Snapshot, error) {
+	snapshots, err := c.getSnapshots(repository, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(snapshots) != 1 {
+		return nil, fmt.Errorf("snapshot %s not found in repository %s", name, repository)
+	}
+
+	return &snapshots[0], nil
+}
+
+// ListSnapshots lists the snapshots of the repository, oldest first
+func (c *Client) ListSnapshots(repository string) ([]Snapshot, error) {
+	snapshots, err := c.getSnapshots(repository, "_all")
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].StartTime < snapshots[j].StartTime })
+
+	return snapshots, nil
+}
+
+// getSnapshots gets the snapshots of the repository matching the name
+func (c *Client) getSnapshots(repository, name string) ([]Snapshot, error) {
+	var body struct {
+		Snapshots []Snapshot `json:"snapshots"`
+	}
+	if err := c.do(http.MethodGet, "/_snapshot/"+escape(repository)+"/"+escape(name), nil, &body); err != nil {
+		return nil, fmt.Errorf("failed to get snapshots of repository %s - %w", repository, err)
+	}
+
+	return body.Snapshots, nil
+}
+
+// ErrSnapshotTimeout is returned by WaitSnapshot when the snapshot is still in progress at the timeout
+var ErrSnapshotTimeout = errors.New("snapshot still in progress")
+
+// WaitSnapshot polls the snapshot every interval until it is no longer in progress, for at most the timeout.
+//
+// Returns the completed snapshot, whatever its state, or the snapshot in progress and ErrSnapshotTimeout.
+func (c *Client) WaitSnapshot(repository, name string, interval, timeout time.Duration) (*Snapshot, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		snapshot, err := c.GetSnapshot(repository, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if snapshot.State != StateInProgress {
+			return snapshot, nil
+		}
+
+		if time.Now().After(deadline) {
+			return snapshot, ErrSnapshotTimeout
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// DeleteSnapshot deletes a snapshot of the repository
+func (c *Client) DeleteSnapshot(repository, name string) error {
+	if err := c.do(http.MethodDelete, "/_snapshot/"+escape(repository)+"/"+escape(name), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete snapshot %s - %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package backup
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MySQLServer describes the MySQL or MariaDB server a dump is taken from
+type MySQLServer struct {
+	Version          string   // server version, e.g. 8.0.36 or 10.11.6-MariaDB
+	MariaDB          bool     // whether the server is a MariaDB server
+	LogBin           bool     // whether the binary log is enabled
+	BinlogPrivileges bool     // whether the user holds the RELOAD and REPLICATION CLIENT privileges the binary log coordinates need
+	NonTransactional []string // tables of the database using a non-transactional engine, e.g. "logs (MyISAM)"
+}
+
+// MySQLDumpClient describes the dump tool the snapshot profile is passed to, which may be of
+// another vendor or version than the server, e.g. the mysqldump of MariaDB
+type MySQLDumpClient struct {
+	Version string // client version, e.g. 8.0.36 or 10.11.6
+	MariaDB bool   // whether the tool is a MariaDB build
+}
+
+// MySQLSnapshotArgs returns the consistent snapshot profile of mysqldump and mariadb-dump: a single
+// transaction instead of table locks, the routines, triggers and events, binary columns as hex
+// and, when requested, enabled and readable by the user, the binary log coordinates written as
+// a comment of the dump. The coordinates options are those the dump tool supports, the GTID
+// position of --gtid is only read from MariaDB servers.
+func MySQLSnapshotArgs(server *MySQLServer, client *MySQLDumpClient, binlogPosition bool) []string {
+	args := []string{"--single-transaction", "--routines", "--triggers", "--events", "--hex-blob"}
+	if !binlogPosition || server == nil || client == nil || !server.LogBin || !server.BinlogPrivileges {
+		return args
+	}
+
+	switch {
+	case client.MariaDB:
+		args = append(args, "--master-data=2")
+		if server.MariaDB {
+			args = append(args, "--gtid")
+		} // --gtid reads gtid_binlog_pos, which MySQL servers do not have
+	case versionAtLeast(client.Version, 8, 0, 26):
+		args = append(args, "--source-data=2") // --master-data is deprecated since mysqldump 8.0.26
+	default:
+		args = append(args, "--master-data=2")
+	}
+
+	return args
+}
+
+var (
+	// dumpDistribRegex matches the version of the MySQL 5.7 and MariaDB builds of the dump tools,
+	// which report the version of their client library first
+	dumpDistribRegex = regexp.MustCompile(`(?:Distrib|from) (\d+(?:\.\d+)+)`)
+	// dumpVersionRegex matches the version of the MySQL 8.0 and later builds of the dump tools
+	dumpVersionRegex = regexp.MustCompile(`Ver (\d+(?:\.\d+)+)`)
+)
+
+// InspectMySQLDump runs the dump tool with --version.
+//
+// Returns the client it reports, or an error if it cannot be run or reports no version.
+func InspectMySQLDump(tool string) (*MySQLDumpClient, error) {
+	output, err := exec.Command(tool, "--version").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s --version: %w", tool, err)
+	}
+
+	client := ParseMySQLDumpVersion(string(output))
+	if client == nil {
+		return nil, fmt.Errorf("no version in the output of %s --version", tool)
+	}
+
+	return client, nil
+}
+
+// ParseMySQLDumpVersion reads the client from the output of mysqldump or mariadb-dump --version,
+// e.g. "mysqldump  Ver 8.0.36 for Linux on x86_64" or "mariadb-dump from 11.4.2-MariaDB, client 10.19".
+//
+// Returns the client, or nil if the output holds no version.
+func ParseMySQLDumpVersion(output string) *MySQLDumpClient {
+	line := strings.SplitN(strings.TrimSpace(output), "\n", 2)[0]
+
+	match := dumpDistribRegex.FindStringSubmatch(line)
+	if match == nil {
+		match = dumpVersionRegex.FindStringSubmatch(line)
+	}
+	if match == nil {
+		return nil
+	}
+
+	return &MySQLDumpClient{Version: match[1], MariaDB: strings.Contains(line, "MariaDB")}
+}
+
+// binlogPrivileges are the privileges reading the binary log coordinates needs: RELOAD for the
+// FLUSH TABLES WITH READ LOCK of --master-data, and REPLICATION CLIENT, named BINLOG MONITOR
+// since MariaDB 10.5, or SUPER for SHOW MASTER STATUS
+var binlogPrivileges = [][]string{{"RELOAD"}, {"REPLICATION CLIENT", "BINLOG MONITOR", "SUPER"}}
+
+// HasBinlogPrivileges reports whether the global privileges of the SHOW GRANTS output allow
+// reading the binary log coordinates. Privileges granted through roles are not followed.
+func HasBinlogPrivileges(grants []string) bool {
+	held := map[string]bool{}
+	for _, grant := range grants {
+		privileges, ok := strings.CutPrefix(grant, "GRANT ")
+		if !ok {
+			continue
+		}
+
+		privileges, _, ok = strings.Cut(privileges, " ON *.* TO ")
+		if !ok {
+			continue
+		}
+
+		for _, privilege := range strings.Split(privileges, ",") {
+			held[strings.TrimSpace(privilege)] = true
+		}
+	}
+
+	if held["ALL PRIVILEGES"] || held["ALL"] {
+		return true
+	}
+
+	for _, alternatives := range binlogPrivileges {
+		found := false
+		for _, privilege := range alternatives {
+			found = found || held[privilege]
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+var (
+	// binlogCoordinatesRegex matches the binary log coordinates written by --master-data=2 and --source-data=2
+	binlogCoordinatesRegex = regexp.MustCompile(`(?m)^-- CHANGE (?:MASTER|REPLICATION SOURCE) TO (?:MASTER|SOURCE)_LOG_FILE='([^']+)', (?:MASTER|SOURCE)_LOG_POS=(\d+)`)
+	// mysqlGTIDRegex matches the GTID set of a MySQL dump with GTIDs enabled
+	mysqlGTIDRegex = regexp.MustCompile(`SET @@GLOBAL\.GTID_PURGED=(?:/\*!80000 '\+'\*/ )?'([^']*)'`)
+	// mariadbGTIDRegex matches the GTID position of a MariaDB dump made with --gtid
+	mariadbGTIDRegex = regexp.MustCompile(`(?m)^-- SET GLOBAL gtid_slave_pos='([^']*)'`)
+)
+
+// ParseBinlogPosition reads the binary log coordinates and the GTID set from the head of a dump.
+//
+// Returns the position, or nil if the dump does not hold binary log coordinates.
+func ParseBinlogPosition(head []byte) *BinlogPosition {
+	match := binlogCoordinatesRegex.FindSubmatch(head)
+	if match == nil {
+		return nil
+	}
+
+	position, err := strconv.ParseUint(string(match[2]), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	binlog := &BinlogPosition{File: string(match[1]), Position: position}
+	for _, regex := range []*regexp.Regexp{mysqlGTIDRegex, mariadbGTIDRegex} {
+		if gtid := regex.FindSubmatch(head); gtid != nil {
+			binlog.GTIDSet = strings.Join(strings.Fields(string(gtid[1])), "")
+		}
+	}
+
+	return binlog
+}
+
+// versionAtLeast reports whether the version is at least major.minor.patch
+func versionAtLeast(version string, major, minor, patch int) bool {
+	want := []int{major, minor, patch}
+
+	parts := strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3)
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return false
+		}
+		if number != want[i] {
+			return number > want[i]
+		}
+	}
+
+	return len(parts) == 3
+}
+
+// HeadWriter keeps the first bytes written to it, up to its limit, and discards the rest
+type HeadWriter struct {
+	Limit int
+	head  []byte
+}
+
+func (w *HeadWriter) Write(p []byte) (int, error) {
+	if remaining := w.Limit - len(w.head); remaining > 0 {
+		w.head = append(w.head, p[:min(len(p), remaining)]...)
+	}
+
+	return len(p), nil
+}
+
+// Bytes returns the bytes kept by the writer
+func (w *HeadWriter) Bytes() []byte {
+	return w.head
+}
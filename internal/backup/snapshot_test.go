@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMySQLSnapshotArgs(t *testing.T) {
+	profile := []string{"--single-transaction", "--routines", "--triggers", "--events", "--hex-blob"}
+	mysql57 := &MySQLDumpClient{Version: "5.7.44"}
+	mysql84 := &MySQLDumpClient{Version: "8.4.0"}
+	mariadb := &MySQLDumpClient{Version: "10.11.6", MariaDB: true}
+	mariadbServer := &MySQLServer{Version: "10.11.6-MariaDB", MariaDB: true, LogBin: true, BinlogPrivileges: true}
+
+	tests := []struct {
+		name           string
+		server         *MySQLServer
+		client         *MySQLDumpClient
+		binlogPosition bool
+		want           []string
+	}{
+		{name: "Server not inspected", client: mysql84, binlogPosition: true, want: profile},
+		{name: "Client not inspected", server: &MySQLServer{Version: "8.4.0", LogBin: true, BinlogPrivileges: true}, binlogPosition: true, want: profile},
+		{name: "Binary log disabled", server: &MySQLServer{Version: "8.0.36", BinlogPrivileges: true}, client: mysql84, binlogPosition: true, want: profile},
+		{name: "Binary log position skipped", server: &MySQLServer{Version: "8.4.0", LogBin: true, BinlogPrivileges: true}, client: mysql84, want: profile},
+		{name: "Binary log privileges missing", server: &MySQLServer{Version: "8.4.0", LogBin: true}, client: mysql84, binlogPosition: true, want: profile},
+		{name: "mysqldump 8.0.26 and later", server: &MySQLServer{Version: "8.4.0", LogBin: true, BinlogPrivileges: true}, client: mysql84, binlogPosition: true, want: append(profile[:5:5], "--source-data=2")},
+		{name: "mysqldump before 8.0.26", server: &MySQLServer{Version: "5.7.44-log", LogBin: true, BinlogPrivileges: true}, client: mysql57, binlogPosition: true, want: append(profile[:5:5], "--master-data=2")},
+		{name: "mysqldump 8.0.26 and later against MySQL 5.7", server: &MySQLServer{Version: "5.7.44-log", LogBin: true, BinlogPrivileges: true}, client: mysql84, binlogPosition: true, want: append(profile[:5:5], "--source-data=2")},
+		{name: "mysqldump before 8.0.26 against MySQL 8.4", server: &MySQLServer{Version: "8.4.0", LogBin: true, BinlogPrivileges: true}, client: mysql57, binlogPosition: true, want: append(profile[:5:5], "--master-data=2")},
+		{name: "MariaDB", server: mariadbServer, client: mariadb, binlogPosition: true, want: append(profile[:5:5], "--master-data=2", "--gtid")},
+		{name: "MySQL mysqldump against MariaDB", server: mariadbServer, client: mysql84, binlogPosition: true, want: append(profile[:5:5], "--source-data=2")},
+		{name: "MariaDB mysqldump against MySQL", server: &MySQLServer{Version: "8.4.0", LogBin: true, BinlogPrivileges: true}, client: mariadb, binlogPosition: true, want: append(profile[:5:5], "--master-data=2")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MySQLSnapshotArgs(tt.server, tt.client, tt.binlogPosition); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MySQLSnapshotArgs() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMySQLDumpVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *MySQLDumpClient
+	}{
+		{name: "mysqldump 8.0", output: "mysqldump  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)\n", want: &MySQLDumpClient{Version: "8.0.36"}},
+		{name: "mysqldump 5.7", output: "mysqldump  Ver 10.13 Distrib 5.7.44, for Linux (x86_64)\n", want: &MySQLDumpClient{Version: "5.7.44"}},
+		{name: "mysqldump of MariaDB", output: "mysqldump  Ver 10.19 Distrib 10.11.6-MariaDB, for debian-linux-gnu (x86_64)\n", want: &MySQLDumpClient{Version: "10.11.6", MariaDB: true}},
+		{name: "mariadb-dump", output: "mariadb-dump from 11.4.2-MariaDB, client 10.19 for debian-linux-gnu (x86_64)\n", want: &MySQLDumpClient{Version: "11.4.2", MariaDB: true}},
+		{name: "No version", output: "mysqldump: command not found\n", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMySQLDumpVersion(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMySQLDumpVersion() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasBinlogPrivileges(t *testing.T) {
+	tests := []struct {
+		name   string
+		grants []string
+		want   bool
+	}{
+		{name: "All privileges", grants: []string{"GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost` WITH GRANT OPTION"}, want: true},
+		{name: "MySQL replication privileges", grants: []string{"GRANT SELECT, RELOAD, REPLICATION CLIENT ON *.* TO `backup`@`%`"}, want: true},
+		{name: "MariaDB binlog monitor", grants: []string{"GRANT SELECT, RELOAD, BINLOG MONITOR ON *.* TO `backup`@`%`"}, want: true},
+		{name: "Privileges split across grants", grants: []string{"GRANT RELOAD ON *.* TO `backup`@`%`", "GRANT SUPER ON *.* TO `backup`@`%`"}, want: true},
+		{name: "RELOAD missing", grants: []string{"GRANT SELECT, REPLICATION CLIENT ON *.* TO `backup`@`%`"}, want: false},
+		{name: "Database privileges only", grants: []string{"GRANT USAGE ON *.* TO `backup`@`%`", "GRANT ALL PRIVILEGES ON `app`.* TO `backup`@`%`"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasBinlogPrivileges(tt.grants); got != tt.want {
+				t.Errorf("HasBinlogPrivileges() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBinlogPosition(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want *BinlogPosition
+	}{
+		{
+			name: "MySQL 5.7 coordinates",
+			head: "-- Position to start replication or point-in-time recovery from\n--\n\n-- CHANGE MASTER TO MASTER_LOG_FILE='mysql-bin.000003', MASTER_LOG_POS=154;\n",
+			want: &BinlogPosition{File: "mysql-bin.000003", Position: 154},
+		},
+		{
+			name: "MySQL 8 coordinates with GTIDs",
+			head: "SET @@GLOBAL.GTID_PURGED=/*!80000 '+'*/ '3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,\n4f22ab58-71ca-11e1-9e33-c80aa9429562:1-3';\n\n-- CHANGE REPLICATION SOURCE TO SOURCE_LOG_FILE='binlog.000012', SOURCE_LOG_POS=2971;\n",
+			want: &BinlogPosition{File: "binlog.000012", Position: 2971, GTIDSet: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,4f22ab58-71ca-11e1-9e33-c80aa9429562:1-3"},
+		},
+		{
+			name: "MariaDB coordinates with GTIDs",
+			head: "-- CHANGE MASTER TO MASTER_USE_GTID=slave_pos;\n-- SET GLOBAL gtid_slave_pos='0-1-42';\n-- CHANGE MASTER TO MASTER_LOG_FILE='mariadb-bin.000001', MASTER_LOG_POS=328;\n",
+			want: &BinlogPosition{File: "mariadb-bin.000001", Position: 328, GTIDSet: "0-1-42"},
+		},
+		{
+			name: "No coordinates",
+			head: "-- MySQL dump 10.13\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseBinlogPosition([]byte(tt.head)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBinlogPosition() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"8.0.26", true},
+		{"8.0.36", true},
+		{"8.4.0", true},
+		{"8.0.25", false},
+		{"5.7.44-log", false},
+		{"10.11.6-MariaDB", true},
+		{"invalid", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := versionAtLeast(tt.version, 8, 0, 26); got != tt.want {
+				t.Errorf("versionAtLeast(%s) got = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadWriter(t *testing.T) {
+	w := &HeadWriter{Limit: 8}
+	for _, chunk := range []string{"-- MySQL", " dump", " 10.13"} {
+		if n, err := fmt.Fprint(w, chunk); err != nil || n != len(chunk) {
+			t.Fatalf("Write() n = %d, err = %v", n, err)
+		}
+	}
+
+	if got := string(w.Bytes()); got != "-- MySQL" {
+		t.Errorf("Bytes() got = %q, want %q", got, "-- MySQL")
+	}
+}
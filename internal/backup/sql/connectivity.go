@@ -1,51 +1,204 @@
 package sql
 
 import (
+	"database/sql"
 	"fmt"
 	"github.com/denisakp/sentinel/internal/backup"
 	"github.com/go-sql-driver/mysql"
-	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/lib/pq"
-	"net/url"
+	"github.com/lib/pq"
+	"net"
+	"strings"
+	"time"
 )
 
-func CheckConnectivity(dbType, host, port, user, password, database string) (bool, error) {
-	// Todo: the user maybe wants to use a tcp6 or unix socket, so this should be configurable in the future
+// Connection holds the parameters of the database connection
+type Connection struct {
+	Host     string             // database host
+	Port     string             // database port
+	User     string             // database user
+	Password string             // database password
+	Database string             // database name
+	TLS      *backup.TLSOptions // TLS options, nil to keep the driver defaults
+}
+
+func CheckConnectivity(dbType string, conn *Connection) (bool, error) {
+	if _, _, err := reachableDSN(dbType, conn); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// reachableDSN builds the data source name of the connection and pings the database with it.
+//
+// Returns the driver and the data source name the database answered on.
+func reachableDSN(dbType string, conn *Connection) (string, string, error) {
 	scheme, err := defineScheme(dbType) // define the scheme based on the database type
 	if err != nil {
-		return false, err
+		return "", "", err
 	}
 
 	switch scheme {
 	case "mysql":
-		cfg := (&mysql.Config{
-			User:                 user,
-			Passwd:               password,
+		cfg := &mysql.Config{
+			User:                 conn.User,
+			Passwd:               conn.Password,
 			Net:                  "tcp",
-			Addr:                 fmt.Sprintf("%s:%s", host, port),
-			DBName:               database,
+			Addr:                 backup.HostPort(conn.Host, conn.Port),
+			DBName:               conn.Database,
 			AllowNativePasswords: true,
-		}).FormatDSN()
+		}
+		if backup.IsSocket(conn.Host) {
+			cfg.Net, cfg.Addr = "unix", conn.Host
+		}
+		if err := applyMySQLTLS(cfg, conn); err != nil {
+			return "", "", err
+		}
 
-		if err := PingSqlDatabase("mysql", cfg); err != nil {
-			return false, fmt.Errorf("failed to ping database - %w", err)
+		dsn := cfg.FormatDSN()
+		if err := PingSqlDatabase("mysql", dsn); err != nil {
+			return "", "", fmt.Errorf("failed to ping database - %w", err)
 		}
+
+		return "mysql", dsn, nil
 	case "postgres":
-		cfg := (&url.URL{
-			Scheme:   dbType,
-			User:     url.UserPassword(user, password),
-			Host:     fmt.Sprintf("%s:%s", host, port),
-			Path:     "/" + database,
-			RawQuery: "sslmode=disable",
-		}).String()
-		if err := PingSqlDatabase("postgres", cfg); err != nil {
-			return false, fmt.Errorf("failed to ping database - %w", err)
+		// lib/pq has no prefer mode, TLS is tried first and plain TCP is the fallback
+		var modes []string
+		switch mode := conn.tlsMode(); mode {
+		case "", "prefer":
+			modes = []string{"require", "disable"}
+			if backup.IsSocket(conn.Host) {
+				modes = []string{"disable"} // TLS does not apply to unix sockets
+			}
+		default:
+			modes = []string{mode}
+		}
+
+		for _, mode := range modes {
+			dsn := postgresDSN(conn, mode)
+			if err = pingPostgres(dsn, conn); err == nil {
+				return "postgres", dsn, nil
+			}
 		}
+
+		return "", "", fmt.Errorf("failed to ping database - %w", err)
 	default:
-		return false, err
+		return "", "", fmt.Errorf("invalid database type: %s", dbType)
 	}
+}
 
-	return true, nil
+// openDB opens the database of the data source name. Through an SSH tunnel in verify-full mode,
+// the PostgresSQL data source name holds the server name the certificate is verified against,
+// lib/pq dialing the local end of the tunnel instead.
+func openDB(driver, dsn string, conn *Connection) (*sql.DB, error) {
+	if driver != "postgres" || conn.TLS == nil || conn.TLS.ServerName == "" {
+		return sql.Open(driver, dsn)
+	}
+
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector.Dialer(tunnelDialer{address: backup.HostPort(conn.Host, conn.Port)})
+
+	return sql.OpenDB(connector), nil
+}
+
+// pingPostgres pings the PostgresSQL database of the data source name
+func pingPostgres(dsn string, conn *Connection) error {
+	db, err := openDB("postgres", dsn, conn)
+	if err != nil {
+		return fmt.Errorf("failed to open database connection: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return nil
+}
+
+// tunnelDialer dials the local end of an SSH tunnel, whatever the address lib/pq dials
+type tunnelDialer struct {
+	address string
+}
+
+func (d tunnelDialer) Dial(network, _ string) (net.Conn, error) {
+	return net.Dial(network, d.address)
+}
+
+func (d tunnelDialer) DialTimeout(network, _ string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(network, d.address, timeout)
+}
+
+// postgresDSN builds the lib/pq connection string with the TLS mode and the certificates.
+// Unlike a connection URL, a connection string takes unix socket directories and IPv6
+// literals as the host without any escaping.
+func postgresDSN(conn *Connection, mode string) string {
+	params := [][2]string{
+		{"host", conn.TLS.PgHost(conn.Host)}, // the server name through an SSH tunnel, see openDB
+		{"port", conn.Port},
+		{"user", conn.User},
+		{"password", conn.Password},
+		{"dbname", conn.Database},
+		{"sslmode", mode},
+	}
+
+	if mode != "disable" && conn.TLS != nil {
+		for _, param := range conn.TLS.PgConnParams() {
+			if param[0] != "sslmode" {
+				params = append(params, param)
+			}
+		}
+	}
+
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+	var dsn []string
+	for _, param := range params {
+		dsn = append(dsn, fmt.Sprintf("%s='%s'", param[0], quote.Replace(param[1])))
+	}
+
+	return strings.Join(dsn, " ")
+}
+
+// applyMySQLTLS sets the TLS configuration of the go-sql-driver connection
+func applyMySQLTLS(cfg *mysql.Config, conn *Connection) error {
+	switch conn.tlsMode() {
+	case "disable":
+		cfg.TLSConfig = "false"
+		return nil
+	case "", "prefer":
+		cfg.AllowFallbackToPlaintext = true
+	}
+
+	if conn.TLS == nil {
+		cfg.TLSConfig = "preferred"
+		return nil
+	}
+
+	// the certificate is checked against the bare address, unix sockets have no host name
+	serverName := backup.TrimBrackets(conn.Host)
+	if backup.IsSocket(conn.Host) {
+		serverName = ""
+	}
+
+	tlsConfig, err := conn.TLS.Config(serverName)
+	if err != nil {
+		return err
+	}
+	cfg.TLS = tlsConfig
+
+	return nil
+}
+
+// tlsMode returns the TLS mode of the connection, empty when not provided
+func (c *Connection) tlsMode() string {
+	if c.TLS == nil {
+		return ""
+	}
+	return c.TLS.Mode
 }
 
 func defineScheme(dbType string) (string, error) {
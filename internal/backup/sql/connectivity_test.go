@@ -0,0 +1,119 @@
+package sql
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/go-sql-driver/mysql"
+	"net"
+	"testing"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		conn *Connection
+		mode string
+		want string
+	}{
+		{
+			name: "Host name",
+			conn: &Connection{Host: "db.internal", Port: "5432", User: "postgres", Password: "secret", Database: "app"},
+			mode: "disable",
+			want: "host='db.internal' port='5432' user='postgres' password='secret' dbname='app' sslmode='disable'",
+		},
+		{
+			name: "Bracketed IPv6",
+			conn: &Connection{Host: "[::1]", Port: "5432", User: "postgres", Database: "app"},
+			mode: "disable",
+			want: "host='::1' port='5432' user='postgres' password='' dbname='app' sslmode='disable'",
+		},
+		{
+			name: "Unix socket and quoting",
+			conn: &Connection{Host: "/var/run/postgresql", Port: "5432", User: "postgres", Password: `it's\`, Database: "app"},
+			mode: "disable",
+			want: `host='/var/run/postgresql' port='5432' user='postgres' password='it\'s\\' dbname='app' sslmode='disable'`,
+		},
+		{
+			name: "TLS certificates",
+			conn: &Connection{Host: "db.internal", Port: "5432", User: "postgres", Database: "app", TLS: &backup.TLSOptions{Mode: "verify-ca", CAFile: "/certs/ca.pem"}},
+			mode: "verify-ca",
+			want: "host='db.internal' port='5432' user='postgres' password='' dbname='app' sslmode='verify-ca' sslrootcert='/certs/ca.pem'",
+		},
+		{
+			name: "Verify full through an SSH tunnel",
+			conn: &Connection{Host: "127.0.0.1", Port: "40321", User: "postgres", Database: "app", TLS: &backup.TLSOptions{Mode: "verify-full", CAFile: "/certs/ca.pem", ServerName: "db.internal"}},
+			mode: "verify-full",
+			want: "host='db.internal' port='40321' user='postgres' password='' dbname='app' sslmode='verify-full' sslrootcert='/certs/ca.pem'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postgresDSN(tt.conn, tt.mode); got != tt.want {
+				t.Errorf("postgresDSN() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_applyMySQLTLS(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{name: "Host name", host: "db.internal", want: "db.internal"},
+		{name: "Bracketed IPv6", host: "[::1]", want: "::1"},
+		{name: "Unix socket", host: "/var/run/mysqld/mysqld.sock", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mysql.Config{}
+			conn := &Connection{Host: tt.host, TLS: &backup.TLSOptions{Mode: "verify-full"}}
+
+			if err := applyMySQLTLS(cfg, conn); err != nil {
+				t.Fatalf("applyMySQLTLS() error = %v", err)
+			}
+			if cfg.TLS == nil {
+				t.Fatal("applyMySQLTLS() did not set the TLS configuration")
+			}
+			if cfg.TLS.ServerName != tt.want {
+				t.Errorf("applyMySQLTLS() server name = %v, want %v", cfg.TLS.ServerName, tt.want)
+			}
+		})
+	}
+}
+
+func Test_openDB_tunnel(t *testing.T) {
+	// the local end of the tunnel
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	dialed := make(chan bool, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		dialed <- true
+		_ = conn.Close()
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	conn := &Connection{Host: host, Port: port, User: "postgres", Database: "app", TLS: &backup.TLSOptions{Mode: "verify-full", ServerName: "db.invalid"}}
+
+	// the server name cannot be resolved, the connection reaches the tunnel
+	db, err := openDB("postgres", postgresDSN(conn, "verify-full"), conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_ = db.Ping()
+
+	select {
+	case <-dialed:
+	default:
+		t.Errorf("openDB() did not dial the local end of the tunnel")
+	}
+}
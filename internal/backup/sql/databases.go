@@ -0,0 +1,97 @@
+package sql
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/utils"
+)
+
+// listDatabasesQueries maps the drivers to the query listing the databases of the server
+var listDatabasesQueries = map[string]string{
+	"postgres": "SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate ORDER BY datname",
+	"mysql":    "SHOW DATABASES",
+}
+
+// ListDatabases lists the databases of the server, the templates excepted for PostgresSQL.
+// PostgresSQL requires a database to connect to, the postgres database is used when none is provided.
+//
+// Returns the database names, or an error if the server cannot be reached or queried.
+func ListDatabases(dbType string, conn *Connection) ([]string, error) {
+	discovery := *conn
+	if dbType == "postgres" {
+		discovery.Database = utils.DefaultValue(conn.Database, "postgres")
+	}
+
+	driver, dsn, err := reachableDSN(dbType, &discovery)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openDB(driver, dsn, &discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(listDatabasesQueries[driver])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+	defer rows.Close()
+
+	var databases []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list databases: %w", err)
+		}
+		databases = append(databases, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	return databases, nil
+}
+
+// ListTables lists the tables and views of the MySQL or MariaDB database of the connection,
+// for the table filters mysqldump cannot match itself.
+//
+// Returns the table names, or an error if the server cannot be reached or queried.
+func ListTables(dbType string, conn *Connection) ([]string, error) {
+	driver, dsn, err := reachableDSN(dbType, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if driver != "mysql" {
+		return nil, fmt.Errorf("listing tables is not supported for %s", dbType)
+	}
+
+	db, err := openDB(driver, dsn, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() ORDER BY table_name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		tables = append(tables, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	return tables, nil
+}
@@ -0,0 +1,491 @@
+package sql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"io"
+	"strings"
+)
+
+// insertBatchSize is the size above which the rows of a table are split into several INSERT
+// statements, well below the default max_allowed_packet of the servers
+const insertBatchSize = 1024 * 1024
+
+// dumpHeader sets the session of the restore up, like mysqldump: the rows are inserted without
+// the foreign key and unique checks, the timestamps being dumped in UTC
+const dumpHeader = `SET NAMES utf8mb4;
+SET @OLD_TIME_ZONE=@@TIME_ZONE, TIME_ZONE='+00:00';
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO';
+SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0;
+`
+
+// dumpFooter restores the session of the restore
+const dumpFooter = `
+SET SQL_NOTES=@OLD_SQL_NOTES;
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+SET TIME_ZONE=@OLD_TIME_ZONE;
+`
+
+// mysqlDumper writes the SQL script of a MySQL or MariaDB database read within a transaction
+type mysqlDumper struct {
+	ctx  context.Context
+	conn *sql.Conn
+	w    *bufio.Writer
+}
+
+// mysqlColumn describes a column of a table or view
+type mysqlColumn struct {
+	name      string
+	generated bool // whether the column is computed by the server, and left out of the INSERT statements
+}
+
+// DumpMySQL dumps the MySQL or MariaDB database of the connection as a SQL script restored with
+// the mysql client, without the dump tools: the schema of the tables from SHOW CREATE TABLE, their
+// rows in batched INSERT statements, the views and the triggers. The database is read within a
+// single transaction started WITH CONSISTENT SNAPSHOT, and the table filters are applied to it.
+//
+// Returns the binary log position the dump is consistent with, when the server exposes it (MariaDB),
+// or an error if the server cannot be reached or queried.
+func DumpMySQL(dbType string, conn *Connection, filters *backup.Filters, w io.Writer) (*backup.BinlogPosition, error) {
+	driver, dsn, err := reachableDSN(dbType, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if driver != "mysql" {
+		return nil, fmt.Errorf("dumping the database is not supported for %s", dbType)
+	}
+
+	db, err := openDB(driver, dsn, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	defer db.Close()
+
+	// the transaction is bound to a single connection of the pool
+	ctx := context.Background()
+	c, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	defer c.Close()
+
+	for _, statement := range []string{
+		"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+		"SET SESSION time_zone = '+00:00'",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+	} {
+		if _, err := c.ExecContext(ctx, statement); err != nil {
+			return nil, fmt.Errorf("failed to start the snapshot transaction: %w", err)
+		}
+	}
+	defer func() {
+		_, _ = c.ExecContext(ctx, "ROLLBACK")
+	}()
+
+	d := &mysqlDumper{ctx: ctx, conn: c, w: bufio.NewWriter(w)}
+
+	position, err := d.binlogPosition()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.dump(conn.Database, filters); err != nil {
+		return nil, err
+	}
+
+	return position, nil
+}
+
+// binlogPosition reads the binary log position of the snapshot, which only MariaDB exposes
+// without locking the tables.
+//
+// Returns nil when the server does not expose it or the binary log is disabled.
+func (d *mysqlDumper) binlogPosition() (*backup.BinlogPosition, error) {
+	rows, err := d.conn.QueryContext(d.ctx, "SHOW STATUS LIKE 'binlog_snapshot_%'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the binary log position: %w", err)
+	}
+	defer rows.Close()
+
+	position := &backup.BinlogPosition{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to read the binary log position: %w", err)
+		}
+
+		switch strings.ToLower(name) {
+		case "binlog_snapshot_file":
+			position.File = value
+		case "binlog_snapshot_position":
+			_, _ = fmt.Sscan(value, &position.Position)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the binary log position: %w", err)
+	}
+
+	if position.File == "" {
+		return nil, nil
+	}
+
+	var gtid sql.NullString
+	if err := d.conn.QueryRowContext(d.ctx, "SELECT BINLOG_GTID_POS(?, ?)", position.File, position.Position).Scan(&gtid); err == nil {
+		position.GTIDSet = gtid.String
+	}
+
+	return position, nil
+}
+
+// dump writes the script of the database: the tables and their rows, then the views, which may
+// select from any table, and the triggers, created last for the inserts not to fire them
+func (d *mysqlDumper) dump(database string, filters *backup.Filters) error {
+	var version string
+	if err := d.conn.QueryRowContext(d.ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return fmt.Errorf("failed to inspect server: %w", err)
+	}
+
+	tables, views, types, err := d.listTables()
+	if err != nil {
+		return err
+	}
+
+	// the tables filtered out are left out, and their triggers with them
+	withData := append(append([]string{}, tables...), views...)
+	var withoutData []string
+	if filters != nil && filters.HasTables() {
+		withData, withoutData = filters.SelectTables(withData)
+		if len(withData)+len(withoutData) == 0 {
+			return fmt.Errorf("no table of %s matches the table filters", database)
+		}
+	}
+	selected := make(map[string]bool)
+	for _, table := range withData {
+		selected[table] = true
+	}
+	for _, table := range withoutData {
+		selected[table] = false
+	}
+
+	_, _ = fmt.Fprintf(d.w, "-- Sentinel dump of %s\n-- Server version: %s\n\n%s", backup.MySQLIdentifier(database), version, dumpHeader)
+
+	for _, table := range tables {
+		data, ok := selected[table]
+		if !ok {
+			continue
+		}
+
+		// sequences have no rows to insert
+		if err := d.dumpTable(table, data && types[table] != "SEQUENCE"); err != nil {
+			return err
+		}
+	}
+
+	// stand-in views first, the views may select from each other
+	var selectedViews []string
+	for _, view := range views {
+		if _, ok := selected[view]; ok {
+			selectedViews = append(selectedViews, view)
+		}
+	}
+	for _, view := range selectedViews {
+		if err := d.dumpStandInView(view); err != nil {
+			return err
+		}
+	}
+	for _, view := range selectedViews {
+		if err := d.dumpView(view); err != nil {
+			return err
+		}
+	}
+
+	if err := d.dumpTriggers(selected); err != nil {
+		return err
+	}
+
+	_, _ = io.WriteString(d.w, dumpFooter)
+
+	if err := d.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write dump: %w", err)
+	}
+
+	return nil
+}
+
+// listTables lists the tables and views of the database.
+//
+// Returns the table names, the view names and the type of each table.
+func (d *mysqlDumper) listTables() ([]string, []string, map[string]string, error) {
+	rows, err := d.conn.QueryContext(d.ctx, "SELECT table_name, table_type FROM information_schema.tables WHERE table_schema = DATABASE() ORDER BY table_name")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables, views []string
+	types := make(map[string]string)
+	for rows.Next() {
+		var name, tableType string
+		if err := rows.Scan(&name, &tableType); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+
+		if tableType == "VIEW" {
+			views = append(views, name)
+		} else {
+			tables = append(tables, name)
+		}
+		types[name] = tableType
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	return tables, views, types, nil
+}
+
+// dumpTable writes the schema of the table and, when asked, its rows
+func (d *mysqlDumper) dumpTable(table string, data bool) error {
+	create, err := d.showCreate("SHOW CREATE TABLE "+backup.MySQLIdentifier(table), 1)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(d.w, "\n--\n-- Table %s\n--\n\nDROP TABLE IF EXISTS %s;\n%s;\n", backup.MySQLIdentifier(table), backup.MySQLIdentifier(table), create)
+
+	if !data {
+		return nil
+	}
+
+	return d.dumpRows(table)
+}
+
+// dumpRows writes the rows of the table in INSERT statements of up to insertBatchSize bytes.
+// The generated columns are left out, the server computing them again.
+func (d *mysqlDumper) dumpRows(table string) error {
+	columns, err := d.columns(table)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, column := range columns {
+		if !column.generated {
+			names = append(names, backup.MySQLIdentifier(column.name))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	rows, err := d.conn.QueryContext(d.ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(names, ", "), backup.MySQLIdentifier(table)))
+	if err != nil {
+		return fmt.Errorf("failed to read the rows of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return fmt.Errorf("failed to read the rows of %s: %w", table, err)
+	}
+
+	// []byte keeps empty values apart from NULL values
+	values := make([][]byte, len(names))
+	pointers := make([]interface{}, len(names))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", backup.MySQLIdentifier(table), strings.Join(names, ", "))
+
+	var batch strings.Builder
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return fmt.Errorf("failed to read the rows of %s: %w", table, err)
+		}
+
+		if batch.Len() == 0 {
+			batch.WriteString(insert)
+		} else {
+			batch.WriteString(",")
+		}
+
+		batch.WriteString("(")
+		for i, value := range values {
+			if i > 0 {
+				batch.WriteString(",")
+			}
+			batch.WriteString(backup.MySQLValue(columnTypes[i].DatabaseTypeName(), value))
+		}
+		batch.WriteString(")")
+
+		if batch.Len() >= insertBatchSize {
+			_, _ = fmt.Fprintf(d.w, "%s;\n", batch.String())
+			batch.Reset()
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read the rows of %s: %w", table, err)
+	}
+
+	if batch.Len() > 0 {
+		_, _ = fmt.Fprintf(d.w, "%s;\n", batch.String())
+	}
+
+	return nil
+}
+
+// dumpStandInView writes a view selecting constants in place of the columns of the view,
+// for the views selecting from it to be created before the view itself
+func (d *mysqlDumper) dumpStandInView(view string) error {
+	columns, err := d.columns(view)
+	if err != nil {
+		return err
+	}
+
+	var constants []string
+	for _, column := range columns {
+		constants = append(constants, "1 AS "+backup.MySQLIdentifier(column.name))
+	}
+
+	_, _ = fmt.Fprintf(d.w, "\n--\n-- Stand-in of the view %s\n--\n\nDROP TABLE IF EXISTS %s;\nDROP VIEW IF EXISTS %s;\nCREATE VIEW %s AS SELECT %s;\n",
+		backup.MySQLIdentifier(view), backup.MySQLIdentifier(view), backup.MySQLIdentifier(view), backup.MySQLIdentifier(view), strings.Join(constants, ", "))
+
+	return nil
+}
+
+// dumpView writes the definition of the view, replacing its stand-in
+func (d *mysqlDumper) dumpView(view string) error {
+	create, err := d.showCreate("SHOW CREATE VIEW "+backup.MySQLIdentifier(view), 1)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(d.w, "\n--\n-- View %s\n--\n\n%s;\n", backup.MySQLIdentifier(view), strings.Replace(create, "CREATE ", "CREATE OR REPLACE ", 1))
+
+	return nil
+}
+
+// dumpTriggers writes the triggers of the tables selected, with the SQL mode they were created with
+func (d *mysqlDumper) dumpTriggers(selected map[string]bool) error {
+	rows, err := d.conn.QueryContext(d.ctx, "SELECT trigger_name, event_object_table FROM information_schema.triggers WHERE trigger_schema = DATABASE() ORDER BY event_object_table, action_order")
+	if err != nil {
+		return fmt.Errorf("failed to list triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []string
+	for rows.Next() {
+		var name, table string
+		if err := rows.Scan(&name, &table); err != nil {
+			return fmt.Errorf("failed to list triggers: %w", err)
+		}
+		if _, ok := selected[table]; ok {
+			triggers = append(triggers, name)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list triggers: %w", err)
+	}
+
+	for _, trigger := range triggers {
+		columns, err := d.show("SHOW CREATE TRIGGER " + backup.MySQLIdentifier(trigger))
+		if err != nil {
+			return err
+		}
+		if len(columns) < 3 {
+			return fmt.Errorf("failed to read the definition of %s", trigger)
+		}
+
+		// the body of the trigger holds semicolons, the statement ends with the delimiter
+		_, _ = fmt.Fprintf(d.w, "\n--\n-- Trigger %s\n--\n\nDELIMITER ;;\nSET SESSION SQL_MODE='%s';;\n%s;;\nSET SESSION SQL_MODE='NO_AUTO_VALUE_ON_ZERO';;\nDELIMITER ;\n",
+			backup.MySQLIdentifier(trigger), columns[1].String, columns[2].String)
+	}
+
+	return nil
+}
+
+// columns lists the columns of the table or view, in their order.
+//
+// Returns the columns, or an error if they cannot be listed.
+func (d *mysqlDumper) columns(table string) ([]mysqlColumn, error) {
+	rows, err := d.conn.QueryContext(d.ctx, "SELECT column_name, COALESCE(generation_expression, '') FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []mysqlColumn
+	for rows.Next() {
+		var name, expression string
+		if err := rows.Scan(&name, &expression); err != nil {
+			return nil, fmt.Errorf("failed to list the columns of %s: %w", table, err)
+		}
+		columns = append(columns, mysqlColumn{name: name, generated: expression != ""})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list the columns of %s: %w", table, err)
+	}
+
+	return columns, nil
+}
+
+// showCreate runs the SHOW CREATE statement and returns the column holding the definition
+func (d *mysqlDumper) showCreate(query string, column int) (string, error) {
+	columns, err := d.show(query)
+	if err != nil {
+		return "", err
+	}
+	if len(columns) <= column {
+		return "", fmt.Errorf("failed to run %s: unexpected result", query)
+	}
+
+	return columns[column].String, nil
+}
+
+// show runs the SHOW statement returning a single row.
+//
+// Returns the columns of the row, whose count depends on the statement.
+func (d *mysqlDumper) show(query string) ([]sql.NullString, error) {
+	rows, err := d.conn.QueryContext(d.ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", query, err)
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", query, err)
+	}
+
+	columns := make([]sql.NullString, len(names))
+	pointers := make([]interface{}, len(names))
+	for i := range columns {
+		pointers[i] = &columns[i]
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to run %s: %w", query, err)
+		}
+		return nil, fmt.Errorf("failed to run %s: no result", query)
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", query, err)
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,325 @@
+package sql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeResult is the result of a query of the fake server
+type fakeResult struct {
+	columns []string
+	types   []string // database type names of the columns
+	rows    [][]driver.Value
+}
+
+// fakeServer is a database/sql connector answering the queries of the dumper with canned results,
+// keyed by the query and its arguments, and failing the queries it has no result for
+type fakeServer map[string]fakeResult
+
+func (s fakeServer) Connect(context.Context) (driver.Conn, error) { return &fakeConn{server: s}, nil }
+func (s fakeServer) Driver() driver.Driver                        { return nil }
+
+// fakeKey returns the key of the result of the query run with the arguments
+func fakeKey(query string, args ...any) string {
+	for _, arg := range args {
+		query += fmt.Sprintf(" [%v]", arg)
+	}
+	return query
+}
+
+type fakeConn struct {
+	server fakeServer
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args := make([]any, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+
+	result, ok := c.server[fakeKey(query, args...)]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query %s", fakeKey(query, args...))
+	}
+
+	return &fakeRows{result: result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	next   int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.result.types) {
+		return r.result.types[i]
+	}
+	return "VARCHAR"
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+// names builds the result of the queries listing names, one per row
+func names(columns []string, rows ...[]driver.Value) fakeResult {
+	return fakeResult{columns: columns, rows: rows}
+}
+
+// shopServer is a MariaDB database with tables, a generated column, a sequence, a view and triggers
+func shopServer() fakeServer {
+	columnsQuery := "SELECT column_name, COALESCE(generation_expression, '') FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position"
+	columns := []string{"column_name", "generation_expression"}
+
+	return fakeServer{
+		"SELECT VERSION()": names([]string{"VERSION()"}, []driver.Value{"10.11.6-MariaDB"}),
+		"SELECT table_name, table_type FROM information_schema.tables WHERE table_schema = DATABASE() ORDER BY table_name": names([]string{"table_name", "table_type"},
+			[]driver.Value{"customer_totals", "VIEW"},
+			[]driver.Value{"customers", "BASE TABLE"},
+			[]driver.Value{"logs", "BASE TABLE"},
+			[]driver.Value{"order_seq", "SEQUENCE"},
+			[]driver.Value{"orders", "BASE TABLE"},
+		),
+
+		"SHOW CREATE TABLE `customers`":        names([]string{"Table", "Create Table"}, []driver.Value{"customers", "CREATE TABLE `customers` (`id` int, `name` varchar(64), `name_upper` varchar(64) AS (upper(`name`)))"}),
+		fakeKey(columnsQuery, "customers"):     names(columns, []driver.Value{"id", ""}, []driver.Value{"name", ""}, []driver.Value{"name_upper", "upper(`name`)"}),
+		"SELECT `id`, `name` FROM `customers`": {columns: []string{"id", "name"}, types: []string{"INT", "VARCHAR"}, rows: [][]driver.Value{{"1", "Ada"}, {"2", nil}}},
+
+		"SHOW CREATE TABLE `logs`":           names([]string{"Table", "Create Table"}, []driver.Value{"logs", "CREATE TABLE `logs` (`id` int, `message` text)"}),
+		fakeKey(columnsQuery, "logs"):        names(columns, []driver.Value{"id", ""}, []driver.Value{"message", ""}),
+		"SELECT `id`, `message` FROM `logs`": {columns: []string{"id", "message"}, types: []string{"INT", "TEXT"}, rows: [][]driver.Value{{"1", "started"}}},
+
+		"SHOW CREATE TABLE `order_seq`": names([]string{"Table", "Create Table"}, []driver.Value{"order_seq", "CREATE SEQUENCE `order_seq` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB"}),
+
+		"SHOW CREATE TABLE `orders`":                        names([]string{"Table", "Create Table"}, []driver.Value{"orders", "CREATE TABLE `orders` (`id` int, `customer_id` int, `total` decimal(10,2))"}),
+		fakeKey(columnsQuery, "orders"):                     names(columns, []driver.Value{"id", ""}, []driver.Value{"customer_id", ""}, []driver.Value{"total", ""}),
+		"SELECT `id`, `customer_id`, `total` FROM `orders`": {columns: []string{"id", "customer_id", "total"}, types: []string{"INT", "INT", "DECIMAL"}, rows: [][]driver.Value{{"1", "1", "9.90"}}},
+
+		fakeKey(columnsQuery, "customer_totals"): names(columns, []driver.Value{"customer_id", ""}, []driver.Value{"total", ""}),
+		"SHOW CREATE VIEW `customer_totals`": names([]string{"View", "Create View", "character_set_client", "collation_connection"},
+			[]driver.Value{"customer_totals", "CREATE ALGORITHM=UNDEFINED VIEW `customer_totals` AS select `customer_id`, sum(`total`) AS `total` from `orders` group by `customer_id`", "utf8mb4", "utf8mb4_general_ci"}),
+
+		"SELECT trigger_name, event_object_table FROM information_schema.triggers WHERE trigger_schema = DATABASE() ORDER BY event_object_table, action_order": names([]string{"trigger_name", "event_object_table"},
+			[]driver.Value{"logs_stamp", "logs"},
+			[]driver.Value{"orders_check", "orders"},
+		),
+		"SHOW CREATE TRIGGER `logs_stamp`": names([]string{"Trigger", "sql_mode", "SQL Original Statement"},
+			[]driver.Value{"logs_stamp", "STRICT_TRANS_TABLES", "CREATE TRIGGER `logs_stamp` BEFORE INSERT ON `logs` FOR EACH ROW SET NEW.message = TRIM(NEW.message)"}),
+		"SHOW CREATE TRIGGER `orders_check`": names([]string{"Trigger", "sql_mode", "SQL Original Statement"},
+			[]driver.Value{"orders_check", "", "CREATE TRIGGER `orders_check` BEFORE INSERT ON `orders` FOR EACH ROW BEGIN IF NEW.total < 0 THEN SET NEW.total = 0; END IF; END"}),
+	}
+}
+
+// dumpScript runs the dumper against the fake server.
+//
+// Returns the script written, or an error if the dump fails.
+func dumpScript(t *testing.T, server fakeServer, filters *backup.Filters) (string, error) {
+	t.Helper()
+
+	db := sql.OpenDB(server)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	defer conn.Close()
+
+	var script strings.Builder
+	d := &mysqlDumper{ctx: ctx, conn: conn, w: bufio.NewWriter(&script)}
+	err = d.dump("shop", filters)
+
+	return script.String(), err
+}
+
+func Test_mysqlDumper_dump(t *testing.T) {
+	want := "-- Sentinel dump of `shop`\n-- Server version: 10.11.6-MariaDB\n\n" + dumpHeader +
+		"\n--\n-- Table `customers`\n--\n\nDROP TABLE IF EXISTS `customers`;\n" +
+		"CREATE TABLE `customers` (`id` int, `name` varchar(64), `name_upper` varchar(64) AS (upper(`name`)));\n" +
+		"INSERT INTO `customers` (`id`, `name`) VALUES (1,'Ada'),(2,NULL);\n" +
+		"\n--\n-- Table `logs`\n--\n\nDROP TABLE IF EXISTS `logs`;\n" +
+		"CREATE TABLE `logs` (`id` int, `message` text);\n" +
+		"INSERT INTO `logs` (`id`, `message`) VALUES (1,'started');\n" +
+		"\n--\n-- Table `order_seq`\n--\n\nDROP TABLE IF EXISTS `order_seq`;\n" +
+		"CREATE SEQUENCE `order_seq` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB;\n" +
+		"\n--\n-- Table `orders`\n--\n\nDROP TABLE IF EXISTS `orders`;\n" +
+		"CREATE TABLE `orders` (`id` int, `customer_id` int, `total` decimal(10,2));\n" +
+		"INSERT INTO `orders` (`id`, `customer_id`, `total`) VALUES (1,1,9.90);\n" +
+		"\n--\n-- Stand-in of the view `customer_totals`\n--\n\nDROP TABLE IF EXISTS `customer_totals`;\nDROP VIEW IF EXISTS `customer_totals`;\n" +
+		"CREATE VIEW `customer_totals` AS SELECT 1 AS `customer_id`, 1 AS `total`;\n" +
+		"\n--\n-- View `customer_totals`\n--\n\n" +
+		"CREATE OR REPLACE ALGORITHM=UNDEFINED VIEW `customer_totals` AS select `customer_id`, sum(`total`) AS `total` from `orders` group by `customer_id`;\n" +
+		"\n--\n-- Trigger `logs_stamp`\n--\n\nDELIMITER ;;\nSET SESSION SQL_MODE='STRICT_TRANS_TABLES';;\n" +
+		"CREATE TRIGGER `logs_stamp` BEFORE INSERT ON `logs` FOR EACH ROW SET NEW.message = TRIM(NEW.message);;\n" +
+		"SET SESSION SQL_MODE='NO_AUTO_VALUE_ON_ZERO';;\nDELIMITER ;\n" +
+		"\n--\n-- Trigger `orders_check`\n--\n\nDELIMITER ;;\nSET SESSION SQL_MODE='';;\n" +
+		"CREATE TRIGGER `orders_check` BEFORE INSERT ON `orders` FOR EACH ROW BEGIN IF NEW.total < 0 THEN SET NEW.total = 0; END IF; END;;\n" +
+		"SET SESSION SQL_MODE='NO_AUTO_VALUE_ON_ZERO';;\nDELIMITER ;\n" +
+		dumpFooter
+
+	got, err := dumpScript(t, shopServer(), nil)
+	if err != nil {
+		t.Fatalf("dump() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("dump() got = %q, want %q", got, want)
+	}
+}
+
+func Test_mysqlDumper_dump_filters(t *testing.T) {
+	tests := []struct {
+		name        string
+		filters     *backup.Filters
+		contains    []string
+		notContains []string
+		wantErr     bool
+	}{
+		{
+			name:        "Included tables",
+			filters:     &backup.Filters{IncludeTables: []string{"orders"}},
+			contains:    []string{"CREATE TABLE `orders`", "INSERT INTO `orders`", "-- Trigger `orders_check`"},
+			notContains: []string{"`customers`", "`logs`", "`order_seq`", "`customer_totals`"},
+		},
+		{
+			name:        "Excluded tables",
+			filters:     &backup.Filters{ExcludeTables: []string{"logs", "customer_*"}},
+			contains:    []string{"CREATE TABLE `customers`", "INSERT INTO `orders`", "-- Trigger `orders_check`"},
+			notContains: []string{"`logs`", "`customer_totals`"},
+		},
+		{
+			name:        "Tables without data",
+			filters:     &backup.Filters{ExcludeTableData: []string{"logs"}},
+			contains:    []string{"CREATE TABLE `logs`", "INSERT INTO `customers`", "-- Trigger `logs_stamp`"},
+			notContains: []string{"INSERT INTO `logs`"},
+		},
+		{
+			name:    "No table matched",
+			filters: &backup.Filters{IncludeTables: []string{"missing"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dumpScript(t, shopServer(), tt.filters)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("dump() got = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("dump() got = %q, want it not to contain %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func Test_mysqlDumper_dump_batches(t *testing.T) {
+	// each row fills more than a quarter of a batch: a batch holds four rows
+	value := strings.Repeat("x", insertBatchSize/4+1)
+	server := fakeServer{
+		"SELECT VERSION()": names([]string{"VERSION()"}, []driver.Value{"8.0.36"}),
+		"SELECT table_name, table_type FROM information_schema.tables WHERE table_schema = DATABASE() ORDER BY table_name": names([]string{"table_name", "table_type"}, []driver.Value{"notes", "BASE TABLE"}),
+		"SHOW CREATE TABLE `notes`": names([]string{"Table", "Create Table"}, []driver.Value{"notes", "CREATE TABLE `notes` (`body` longtext)"}),
+		fakeKey("SELECT column_name, COALESCE(generation_expression, '') FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position", "notes"): names([]string{"column_name", "generation_expression"}, []driver.Value{"body", ""}),
+		"SELECT `body` FROM `notes`": {columns: []string{"body"}, types: []string{"LONGTEXT"}, rows: [][]driver.Value{{value}, {value}, {value}, {value}, {value}, {value}}},
+		"SELECT trigger_name, event_object_table FROM information_schema.triggers WHERE trigger_schema = DATABASE() ORDER BY event_object_table, action_order": names([]string{"trigger_name", "event_object_table"}),
+	}
+
+	got, err := dumpScript(t, server, nil)
+	if err != nil {
+		t.Fatalf("dump() error = %v", err)
+	}
+
+	var rows []int
+	for _, line := range strings.Split(got, "\n") {
+		if strings.HasPrefix(line, "INSERT INTO `notes` (`body`) VALUES ") {
+			if len(line) > insertBatchSize+len(value)+4 {
+				t.Errorf("dump() got an INSERT statement of %d bytes, want at most a row above %d", len(line), insertBatchSize)
+			}
+			rows = append(rows, strings.Count(line, "'"+value+"'"))
+		}
+	}
+	if want := []int{4, 2}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("dump() got INSERT statements of %v rows, want %v", rows, want)
+	}
+}
+
+func Test_mysqlDumper_binlogPosition(t *testing.T) {
+	status := "SHOW STATUS LIKE 'binlog_snapshot_%'"
+
+	tests := []struct {
+		name   string
+		server fakeServer
+		want   *backup.BinlogPosition
+	}{
+		{
+			name: "MariaDB",
+			server: fakeServer{
+				status: names([]string{"Variable_name", "Value"}, []driver.Value{"Binlog_snapshot_file", "mariadb-bin.000012"}, []driver.Value{"Binlog_snapshot_position", "4711"}),
+				fakeKey("SELECT BINLOG_GTID_POS(?, ?)", "mariadb-bin.000012", uint64(4711)): names([]string{"BINLOG_GTID_POS"}, []driver.Value{"0-1-42"}),
+			},
+			want: &backup.BinlogPosition{File: "mariadb-bin.000012", Position: 4711, GTIDSet: "0-1-42"},
+		},
+		{
+			name:   "MySQL",
+			server: fakeServer{status: names([]string{"Variable_name", "Value"})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sql.OpenDB(tt.server)
+			defer db.Close()
+
+			ctx := context.Background()
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatalf("Conn() error = %v", err)
+			}
+			defer conn.Close()
+
+			d := &mysqlDumper{ctx: ctx, conn: conn}
+			got, err := d.binlogPosition()
+			if err != nil {
+				t.Fatalf("binlogPosition() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("binlogPosition() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/lib/pq"
+)
+
+// InspectPostgres inspects the server speaking the PostgresSQL protocol of the connection: its
+// version and its flavor, CockroachDB or PostgresSQL with the TimescaleDB extension installed
+// in the database of the connection.
+//
+// Returns the server description, or an error if the server cannot be reached or queried.
+func InspectPostgres(conn *Connection) (*backup.PostgresServer, error) {
+	db, err := openPostgres(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	server := &backup.PostgresServer{}
+	if err := db.QueryRow("SELECT version()").Scan(&server.Version); err != nil {
+		return nil, fmt.Errorf("failed to inspect server: %w", err)
+	}
+
+	server.Flavor = backup.ParsePostgresFlavor(server.Version)
+	if server.Flavor == backup.FlavorCockroachDB {
+		return server, nil // CockroachDB has no extensions
+	}
+
+	err = db.QueryRow("SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'").Scan(&server.TimescaleVersion)
+	switch {
+	case err == nil:
+		server.Flavor = backup.FlavorTimescaleDB
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("failed to list extensions: %w", err)
+	}
+
+	return server, nil
+}
+
+// ExecPostgres runs the statements in the database of the connection, in order
+func ExecPostgres(conn *Connection, statements ...string) error {
+	db, err := openPostgres(conn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to run %q: %w", statement, err)
+		}
+	}
+
+	return nil
+}
+
+// CockroachBackup backs the database of the connection up into the backup collection with
+// BACKUP INTO, the collection being a storage URI the nodes of the cluster write to.
+// The backup is taken as of a timestamp read first, which names its subdirectory in the
+// collection whatever the other backups written to it meanwhile. The statement returns
+// once the backup job completed.
+//
+// Returns the description of the backup, or an error if the backup failed.
+func CockroachBackup(conn *Connection, collection string) (*backup.CockroachBackup, error) {
+	db, err := openPostgres(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	result := &backup.CockroachBackup{Collection: backup.RedactURI(collection)}
+
+	var timestamp string
+	if err := db.QueryRow("SELECT cluster_logical_timestamp()::STRING").Scan(&timestamp); err != nil {
+		return nil, fmt.Errorf("failed to read the cluster timestamp: %w", err)
+	}
+	if result.Path, err = backup.CockroachBackupPath(timestamp); err != nil {
+		return nil, err
+	}
+
+	var status string
+	var fraction float64
+	var indexEntries int64
+	statement := fmt.Sprintf("BACKUP DATABASE %s INTO %s AS OF SYSTEM TIME %s", pq.QuoteIdentifier(conn.Database), pq.QuoteLiteral(collection), pq.QuoteLiteral(timestamp))
+	if err := db.QueryRow(statement).Scan(&result.JobID, &status, &fraction, &result.Rows, &indexEntries, &result.Bytes); err != nil {
+		return nil, fmt.Errorf("failed to back up database: %w", err)
+	}
+	if status != "succeeded" {
+		return nil, fmt.Errorf("backup job %d %s", result.JobID, status)
+	}
+
+	// check the backup is in the subdirectory named after its timestamp
+	var found bool
+	query := fmt.Sprintf("SELECT count(*) > 0 FROM [SHOW BACKUPS IN %s] WHERE path = %s", pq.QuoteLiteral(collection), pq.QuoteLiteral(result.Path))
+	if err := db.QueryRow(query).Scan(&found); err != nil {
+		return nil, fmt.Errorf("failed to find the backup in the collection: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("backup job %d not found at %s in the collection", result.JobID, result.Path)
+	}
+
+	return result, nil
+}
+
+// CockroachRestore restores a database backed up with BACKUP INTO from the subdirectory of
+// the backup collection, as the database of the connection, renamed when it differs from
+// the backed up database. The database restored must not exist, the connection is opened
+// on defaultdb.
+func CockroachRestore(conn *Connection, database, collection, path string) error {
+	restore := *conn
+	restore.Database = "defaultdb"
+
+	db, err := openPostgres(&restore)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statement := fmt.Sprintf("RESTORE DATABASE %s FROM %s IN %s", pq.QuoteIdentifier(database), pq.QuoteLiteral(path), pq.QuoteLiteral(collection))
+	if conn.Database != database {
+		statement += fmt.Sprintf(" WITH new_db_name = %s", pq.QuoteLiteral(conn.Database))
+	}
+
+	if _, err := db.Exec(statement); err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	return nil
+}
+
+// openPostgres opens a connection to the server speaking the PostgresSQL protocol
+func openPostgres(conn *Connection) (*sql.DB, error) {
+	driver, dsn, err := reachableDSN("postgres", conn)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openDB(driver, dsn, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	return db, nil
+}
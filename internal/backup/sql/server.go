@@ -0,0 +1,96 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"strings"
+)
+
+// nonTransactionalTablesQuery lists the base tables of the current database whose engine
+// does not take part in the snapshot of --single-transaction
+const nonTransactionalTablesQuery = `SELECT table_name, COALESCE(engine, '') FROM information_schema.tables
+WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'
+AND COALESCE(engine, '') NOT IN ('InnoDB', 'ndbcluster', 'RocksDB', 'TokuDB')
+ORDER BY table_name`
+
+// InspectMySQL inspects the MySQL or MariaDB server of the connection: its version, whether the
+// binary log is enabled and readable by the user, and the tables of the database using a
+// non-transactional engine.
+//
+// Returns the server description, or an error if the server cannot be reached or queried.
+func InspectMySQL(dbType string, conn *Connection) (*backup.MySQLServer, error) {
+	driver, dsn, err := reachableDSN(dbType, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if driver != "mysql" {
+		return nil, fmt.Errorf("inspecting the server is not supported for %s", dbType)
+	}
+
+	db, err := openDB(driver, dsn, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	defer db.Close()
+
+	server := &backup.MySQLServer{}
+	if err := db.QueryRow("SELECT VERSION(), @@log_bin").Scan(&server.Version, &server.LogBin); err != nil {
+		return nil, fmt.Errorf("failed to inspect server: %w", err)
+	}
+	server.MariaDB = strings.Contains(strings.ToLower(server.Version), "mariadb")
+
+	// the binary log coordinates need replication privileges the backup user may lack
+	if server.LogBin {
+		if server.BinlogPrivileges, err = hasBinlogPrivileges(db); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := db.Query(nonTransactionalTablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list table engines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, engine string
+		if err := rows.Scan(&table, &engine); err != nil {
+			return nil, fmt.Errorf("failed to list table engines: %w", err)
+		}
+		server.NonTransactional = append(server.NonTransactional, fmt.Sprintf("%s (%s)", table, engine))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list table engines: %w", err)
+	}
+
+	return server, nil
+}
+
+// hasBinlogPrivileges reads the grants of the current user.
+//
+// Returns whether they allow reading the binary log coordinates.
+func hasBinlogPrivileges(db *sql.DB) (bool, error) {
+	rows, err := db.Query("SHOW GRANTS")
+	if err != nil {
+		return false, fmt.Errorf("failed to read the grants of the user: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return false, fmt.Errorf("failed to read the grants of the user: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to read the grants of the user: %w", err)
+	}
+
+	return backup.HasBinlogPrivileges(grants), nil
+}
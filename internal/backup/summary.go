@@ -0,0 +1,64 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// RunResult is the outcome of the backup of a single database
+type RunResult struct {
+	Database string        // name of the database
+	Duration time.Duration // time spent backing up the database
+	Err      error         // error of the backup, nil on success
+}
+
+// RunSummary collects the results of a run backing up several databases
+type RunSummary struct {
+	Results []RunResult
+}
+
+// Add records the result of the backup of a database
+func (s *RunSummary) Add(database string, duration time.Duration, err error) {
+	s.Results = append(s.Results, RunResult{Database: database, Duration: duration, Err: err})
+}
+
+// Failed returns the number of databases whose backup failed
+func (s *RunSummary) Failed() int {
+	failed := 0
+	for _, result := range s.Results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// Print writes the summary as a table, one line per database, followed by the totals
+func (s *RunSummary) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "DATABASE\tSTATUS\tDURATION\tERROR")
+
+	var total time.Duration
+	for _, result := range s.Results {
+		status, message := "ok", ""
+		if result.Err != nil {
+			status, message = "failed", result.Err.Error()
+		}
+
+		total += result.Duration
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Database, status, result.Duration.Round(time.Millisecond), message)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%d database(s) backed up, %d failed, in %s\n",
+		len(s.Results)-s.Failed(), s.Failed(), total.Round(time.Millisecond))
+
+	return err
+}
@@ -0,0 +1,322 @@
+package backup
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSOptions holds the TLS settings of the database connection. The modes follow the
+// PostgresSQL naming (disable, prefer, require, verify-ca, verify-full) and are translated
+// for each engine, both for the connectivity check and for the dump tools arguments.
+type TLSOptions struct {
+	Mode     string // TLS mode, empty to keep the default of the client (prefer)
+	CAFile   string // CA certificate used to verify the server certificate
+	CertFile string // client certificate
+	KeyFile  string // client private key
+	// ServerName is the host the certificate is verified against in verify-full mode when the
+	// connection goes through an SSH tunnel, the host connected to being its local end
+	ServerName string
+}
+
+// validTLSModes lists the supported TLS modes
+var validTLSModes = map[string]bool{
+	"":            true,
+	"disable":     true,
+	"prefer":      true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// ValidateTLSOptions validates the TLS mode and checks that the certificate files exist
+func ValidateTLSOptions(o *TLSOptions) error {
+	if !validTLSModes[o.Mode] {
+		return fmt.Errorf("invalid TLS mode: %s (expected disable, prefer, require, verify-ca or verify-full)", o.Mode)
+	}
+
+	if (o.Mode == "verify-ca" || o.Mode == "verify-full") && o.CAFile == "" {
+		return fmt.Errorf("TLS mode %s requires a CA certificate", o.Mode)
+	}
+
+	if o.KeyFile != "" && o.CertFile == "" {
+		return fmt.Errorf("TLS client key requires a client certificate")
+	}
+
+	for _, file := range []string{o.CAFile, o.CertFile, o.KeyFile} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("TLS file %s is not readable: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// ThroughTunnel prepares the TLS options of a connection reaching the host through an SSH tunnel,
+// so that the certificate is verified against the host rather than the local end of the tunnel in
+// verify-full mode. The MySQL, MariaDB and MongoDB tools cannot verify the certificate against
+// another name than the host they connect to.
+//
+// Returns an error for these engines in verify-full mode.
+func (o *TLSOptions) ThroughTunnel(dbType, host string) error {
+	if o == nil || o.Mode != "verify-full" {
+		return nil
+	}
+
+	switch dbType {
+	case "mysql", "mariadb":
+		return fmt.Errorf("TLS mode verify-full is not supported through an SSH tunnel with %s: its client tools verify the certificate against the local end of the tunnel, use verify-ca", dbType)
+	case "mongodb":
+		return fmt.Errorf("TLS mode verify-full is not supported through an SSH tunnel with mongodb: mongodump and mongorestore verify the certificate against the local end of the tunnel, use verify-ca")
+	}
+
+	o.ServerName = TrimBrackets(host)
+
+	return nil
+}
+
+// Enabled reports whether TLS options were provided, otherwise the clients keep their defaults
+func (o *TLSOptions) Enabled() bool {
+	return o != nil && (o.Mode != "" || o.CAFile != "" || o.CertFile != "" || o.KeyFile != "")
+}
+
+// Config builds the Go TLS configuration of the mode, serverName being the host the
+// certificate is verified against in verify-full mode.
+//
+// Returns nil when TLS is disabled, or an error if the certificates cannot be loaded.
+func (o *TLSOptions) Config(serverName string) (*tls.Config, error) {
+	if o == nil || o.Mode == "disable" {
+		return nil, nil
+	}
+
+	// the host connected to is the local end of the tunnel
+	if o.ServerName != "" {
+		serverName = o.ServerName
+	}
+
+	cfg := &tls.Config{ServerName: serverName}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", o.CAFile)
+		}
+	}
+
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.defaultKeyFile())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	switch o.Mode {
+	case "verify-full":
+		// the default verification checks both the chain and the host name
+	case "verify-ca":
+		// check the chain against the CA, but not the host name
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = verifyChain(cfg.RootCAs)
+	default:
+		cfg.InsecureSkipVerify = true
+	}
+
+	return cfg, nil
+}
+
+// defaultKeyFile returns the client key file, the certificate file being expected to hold the key when not provided
+func (o *TLSOptions) defaultKeyFile() string {
+	if o.KeyFile == "" {
+		return o.CertFile
+	}
+	return o.KeyFile
+}
+
+// verifyChain returns a function verifying the server certificate chain without checking the host name
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server did not present a certificate")
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse server certificate: %w", err)
+			}
+			certs[i] = cert
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+		return err
+	}
+}
+
+// PgConnParams returns the libpq connection parameters of the TLS options, in a stable order
+func (o *TLSOptions) PgConnParams() [][2]string {
+	if !o.Enabled() {
+		return nil
+	}
+
+	var params [][2]string
+	for _, param := range [][2]string{
+		{"sslmode", o.Mode},
+		{"sslrootcert", o.CAFile},
+		{"sslcert", o.CertFile},
+		{"sslkey", o.KeyFile},
+	} {
+		if param[1] != "" {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// MySQLArgs returns the mysqldump and mysql arguments of the TLS options
+func (o *TLSOptions) MySQLArgs() []string {
+	if !o.Enabled() {
+		return nil
+	}
+
+	var args []string
+
+	mysqlModes := map[string]string{
+		"disable":     "DISABLED",
+		"prefer":      "PREFERRED",
+		"require":     "REQUIRED",
+		"verify-ca":   "VERIFY_CA",
+		"verify-full": "VERIFY_IDENTITY",
+	}
+	if mode, ok := mysqlModes[o.Mode]; ok {
+		args = append(args, fmt.Sprintf("--ssl-mode=%s", mode))
+	}
+
+	return append(args, o.sslFileArgs()...)
+}
+
+// MariaDBArgs returns the mariadb-dump and mariadb arguments of the TLS options.
+// MariaDB clients have no --ssl-mode option, the mode is expressed with --ssl flags.
+func (o *TLSOptions) MariaDBArgs() []string {
+	if !o.Enabled() {
+		return nil
+	}
+
+	var args []string
+
+	switch o.Mode {
+	case "disable":
+		return []string{"--skip-ssl"}
+	case "require", "verify-ca":
+		args = append(args, "--ssl")
+	case "verify-full":
+		args = append(args, "--ssl", "--ssl-verify-server-cert")
+	}
+
+	return append(args, o.sslFileArgs()...)
+}
+
+// sslFileArgs returns the certificate arguments shared by the MySQL and MariaDB clients
+func (o *TLSOptions) sslFileArgs() []string {
+	var args []string
+
+	if o.CAFile != "" {
+		args = append(args, fmt.Sprintf("--ssl-ca=%s", o.CAFile))
+	}
+	if o.CertFile != "" {
+		args = append(args, fmt.Sprintf("--ssl-cert=%s", o.CertFile))
+	}
+	if o.KeyFile != "" {
+		args = append(args, fmt.Sprintf("--ssl-key=%s", o.KeyFile))
+	}
+
+	return args
+}
+
+// MongoTLS reports whether TLS must be enforced on the MongoDB connection. MongoDB has
+// no opportunistic TLS, so the disable and prefer modes leave the decision to the URI.
+func (o *TLSOptions) MongoTLS() bool {
+	return o.Required()
+}
+
+// Required reports whether the mode requires TLS, for the engines without opportunistic TLS
+func (o *TLSOptions) Required() bool {
+	return o != nil && (o.Mode == "require" || o.Mode == "verify-ca" || o.Mode == "verify-full")
+}
+
+// MongoArgs returns the mongodump and mongorestore arguments of the TLS options.
+// MongoDB tools expect the client certificate and its key in a single PEM file.
+func (o *TLSOptions) MongoArgs() []string {
+	if !o.MongoTLS() {
+		return nil
+	}
+
+	args := []string{"--tls"}
+
+	if o.CAFile != "" {
+		args = append(args, fmt.Sprintf("--tlsCAFile=%s", o.CAFile))
+	}
+	if o.CertFile != "" {
+		args = append(args, fmt.Sprintf("--tlsCertificateKeyFile=%s", o.CertFile))
+	}
+
+	switch o.Mode {
+	case "require":
+		args = append(args, "--tlsInsecure")
+	case "verify-ca":
+		args = append(args, "--tlsAllowInvalidHostnames")
+	}
+
+	return args
+}
+
+// PgHost returns the value of the pg_dump, pg_restore and psql --host argument: the server name
+// when the connection goes through an SSH tunnel, so that libpq verifies the certificate against
+// it, the local end of the tunnel being passed as hostaddr by PgConnString
+func (o *TLSOptions) PgHost(host string) string {
+	if o != nil && o.ServerName != "" {
+		return o.ServerName
+	}
+
+	return TrimBrackets(host)
+}
+
+// PgConnString returns the value of the pg_dump, pg_restore and psql --dbname argument.
+// When TLS options are provided, the database name is turned into a connection string
+// carrying them, since these tools have no dedicated TLS arguments. Through an SSH tunnel,
+// the connection string holds the address of the host connected to, see PgHost.
+func (o *TLSOptions) PgConnString(database, host string) string {
+	if !o.Enabled() {
+		return database
+	}
+
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+	conn := fmt.Sprintf("dbname='%s'", quote.Replace(database))
+	for _, param := range o.PgConnParams() {
+		conn += fmt.Sprintf(" %s='%s'", param[0], quote.Replace(param[1]))
+	}
+
+	if o.ServerName != "" {
+		conn += fmt.Sprintf(" hostaddr='%s'", quote.Replace(TrimBrackets(host)))
+	}
+
+	return conn
+}
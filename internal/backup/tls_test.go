@@ -0,0 +1,142 @@
+package backup
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateTLSOptions(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("ca"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options *TLSOptions
+		wantErr bool
+	}{
+		{name: "No options", options: &TLSOptions{}},
+		{name: "Require", options: &TLSOptions{Mode: "require"}},
+		{name: "Verify with CA", options: &TLSOptions{Mode: "verify-full", CAFile: caFile}},
+		{name: "Invalid mode", options: &TLSOptions{Mode: "strict"}, wantErr: true},
+		{name: "Verify without CA", options: &TLSOptions{Mode: "verify-ca"}, wantErr: true},
+		{name: "Key without certificate", options: &TLSOptions{Mode: "require", KeyFile: caFile}, wantErr: true},
+		{name: "Missing CA file", options: &TLSOptions{Mode: "verify-ca", CAFile: filepath.Join(t.TempDir(), "missing.pem")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTLSOptions(tt.options); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTLSOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSOptions_Config(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	// the test server certificate is self-signed, it acts as its own CA
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		mode             string
+		serverName       string
+		tunnelServerName string // server name set by ThroughTunnel
+		wantErr          bool
+	}{
+		{name: "Require skips the verification", mode: "require", serverName: "db.internal"},
+		{name: "Verify CA ignores the host name", mode: "verify-ca", serverName: "db.internal"},
+		{name: "Verify full checks the host name", mode: "verify-full", serverName: "example.com"},
+		{name: "Verify full with another host name", mode: "verify-full", serverName: "db.internal", wantErr: true},
+		{name: "Verify full through an SSH tunnel checks the server name", mode: "verify-full", serverName: "db.internal", tunnelServerName: "example.com"},
+		{name: "Verify full through an SSH tunnel with another server name", mode: "verify-full", serverName: "example.com", tunnelServerName: "db.internal", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := (&TLSOptions{Mode: tt.mode, CAFile: caFile, ServerName: tt.tunnelServerName}).Config(tt.serverName)
+			if err != nil {
+				t.Fatalf("Config() error = %v", err)
+			}
+
+			conn, err := tls.Dial("tcp", server.Listener.Addr().String(), cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("tls.Dial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if conn != nil {
+				_ = conn.Close()
+			}
+		})
+	}
+}
+
+func TestTLSOptions_PgConnString(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *TLSOptions
+		database string
+		want     string
+	}{
+		{name: "No options", options: &TLSOptions{}, database: "test", want: "test"},
+		{name: "Mode only", options: &TLSOptions{Mode: "require"}, database: "test", want: "dbname='test' sslmode='require'"},
+		{
+			name:     "Certificates and quoting",
+			options:  &TLSOptions{Mode: "verify-full", CAFile: "/certs/ca.pem", CertFile: "/certs/client.pem", KeyFile: "/certs/client.key"},
+			database: "o'neil",
+			want:     `dbname='o\'neil' sslmode='verify-full' sslrootcert='/certs/ca.pem' sslcert='/certs/client.pem' sslkey='/certs/client.key'`,
+		},
+		{
+			name:     "Verify full through an SSH tunnel",
+			options:  &TLSOptions{Mode: "verify-full", CAFile: "/certs/ca.pem", ServerName: "db.internal"},
+			database: "test",
+			want:     "dbname='test' sslmode='verify-full' sslrootcert='/certs/ca.pem' hostaddr='127.0.0.1'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.PgConnString(tt.database, "127.0.0.1"); got != tt.want {
+				t.Errorf("PgConnString() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLSOptions_ThroughTunnel(t *testing.T) {
+	tests := []struct {
+		name           string
+		dbType         string
+		mode           string
+		wantServerName string
+		wantErr        bool
+	}{
+		{name: "PostgresSQL verify full", dbType: "postgres", mode: "verify-full", wantServerName: "db.internal"},
+		{name: "Redis verify full", dbType: "redis", mode: "verify-full", wantServerName: "db.internal"},
+		{name: "PostgresSQL verify CA", dbType: "postgres", mode: "verify-ca"},
+		{name: "MySQL verify CA", dbType: "mysql", mode: "verify-ca"},
+		{name: "MySQL verify full - error expected", dbType: "mysql", mode: "verify-full", wantErr: true},
+		{name: "MariaDB verify full - error expected", dbType: "mariadb", mode: "verify-full", wantErr: true},
+		{name: "MongoDB verify full - error expected", dbType: "mongodb", mode: "verify-full", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &TLSOptions{Mode: tt.mode}
+			err := options.ThroughTunnel(tt.dbType, "db.internal")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ThroughTunnel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if options.ServerName != tt.wantServerName {
+				t.Errorf("ThroughTunnel() server name = %v, want %v", options.ServerName, tt.wantServerName)
+			}
+		})
+	}
+}
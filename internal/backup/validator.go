@@ -5,10 +5,14 @@ import "fmt"
 // ValidateDbType validates the database type provided by the user
 func ValidateDbType(dbType string) error {
 	validTypes := map[string]bool{
-		"mysql":    true,
-		"postgres": true,
-		"mariadb":  true,
-		"mongodb":  true,
+		"mysql":         true,
+		"postgres":      true,
+		"mariadb":       true,
+		"mongodb":       true,
+		"sqlite":        true,
+		"redis":         true,
+		"elasticsearch": true,
+		"opensearch":    true,
 	}
 
 	if _, ok := validTypes[dbType]; !ok {
@@ -0,0 +1,33 @@
+package backup
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/encryption"
+	"regexp"
+)
+
+// walFileName matches the files PostgresSQL archives: WAL segments, partial segments,
+// backup history files and timeline history files
+var walFileName = regexp.MustCompile(`^([0-9A-F]{24}(\.partial|\.[0-9A-F]{8}\.backup)?|[0-9A-F]{8}\.history)$`)
+
+// ValidateWalFileName validates the name of a file archived by PostgresSQL, as passed to
+// the archive_command and restore_command with %f
+func ValidateWalFileName(name string) error {
+	if !walFileName.MatchString(name) {
+		return fmt.Errorf("invalid WAL file name: %s", name)
+	}
+
+	return nil
+}
+
+// WalArchiveName returns the name a file archived by PostgresSQL is stored under, with the
+// extension of the compression algorithm and the encryption extension when it is encrypted
+func WalArchiveName(name, algorithm string, encrypted bool) string {
+	name = compression.AppendExtension(name, algorithm)
+	if encrypted {
+		name += encryption.Extension
+	}
+
+	return name
+}
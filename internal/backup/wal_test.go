@@ -0,0 +1,26 @@
+package backup
+
+import "testing"
+
+func TestValidateWalFileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{name: "Segment", file: "000000010000000000000001"},
+		{name: "Partial segment", file: "000000010000000000000001.partial"},
+		{name: "Backup history", file: "000000010000000000000002.00000028.backup"},
+		{name: "Timeline history", file: "00000002.history"},
+		{name: "Lower case", file: "00000001000000000000000a", wantErr: true},
+		{name: "Path", file: "pg_wal/000000010000000000000001", wantErr: true},
+		{name: "Empty", file: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWalFileName(tt.file); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWalFileName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
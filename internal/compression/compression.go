@@ -0,0 +1,197 @@
+package compression
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extensions maps the supported compression algorithms to the extension of the compressed files
+var extensions = map[string]string{
+	"gzip": ".gz",
+	"zstd": ".zst",
+	"lz4":  ".lz4",
+}
+
+// maxLevels maps the supported compression algorithms to their highest compression level
+var maxLevels = map[string]int{
+	"gzip": 9,
+	"zstd": 22,
+	"lz4":  9,
+}
+
+// Enabled reports whether the algorithm actually compresses the data
+func Enabled(algorithm string) bool {
+	return algorithm != "" && algorithm != "none"
+}
+
+// ValidateAlgorithm validates the compression algorithm provided by the user
+func ValidateAlgorithm(algorithm string) error {
+	if !Enabled(algorithm) {
+		return nil
+	}
+
+	if _, ok := extensions[algorithm]; !ok {
+		return fmt.Errorf("unsupported compression algorithm: %s", algorithm)
+	}
+
+	return nil
+}
+
+// ValidateLevel validates the compression level of the algorithm, 0 being the algorithm default
+func ValidateLevel(algorithm string, level int) error {
+	if !Enabled(algorithm) {
+		return nil
+	}
+
+	if level < 0 || level > maxLevels[algorithm] {
+		return fmt.Errorf("invalid %s compression level: %d (expected 1-%d)", algorithm, level, maxLevels[algorithm])
+	}
+
+	return nil
+}
+
+// Extension returns the extension of the files compressed with the algorithm
+func Extension(algorithm string) string {
+	return extensions[algorithm]
+}
+
+// FromExtension returns the algorithm a file was compressed with, based on its extension.
+// It returns an empty string if the file is not compressed.
+func FromExtension(name string) string {
+	ext := filepath.Ext(name)
+
+	for algorithm, extension := range extensions {
+		if ext == extension {
+			return algorithm
+		}
+	}
+
+	return ""
+}
+
+// AppendExtension adds the extension of the algorithm to the file name, unless it already ends with it
+func AppendExtension(name, algorithm string) string {
+	ext := Extension(algorithm)
+	if strings.HasSuffix(name, ext) {
+		return name
+	}
+
+	return name + ext
+}
+
+// TrimExtension removes the compression extension from the file name
+func TrimExtension(name string) string {
+	if algorithm := FromExtension(name); algorithm != "" {
+		return strings.TrimSuffix(name, extensions[algorithm])
+	}
+
+	return name
+}
+
+// NewWriter returns a writer compressing the data written to it into w.
+// The writer must be closed to flush the compressed stream. When the
+// algorithm is empty or "none", the data is written to w as is.
+//
+// Returns an error if the algorithm or the level is not supported.
+func NewWriter(w io.Writer, algorithm string, level int) (io.WriteCloser, error) {
+	if err := ValidateAlgorithm(algorithm); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateLevel(algorithm, level); err != nil {
+		return nil, err
+	}
+
+	switch algorithm {
+	case "gzip":
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	case "zstd":
+		if level == 0 {
+			return zstd.NewWriter(w)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	case "lz4":
+		writer := lz4.NewWriter(w)
+		if level > 0 {
+			if err := writer.Apply(lz4.CompressionLevelOption(lz4.CompressionLevel(1 << (8 + level - 1)))); err != nil {
+				return nil, fmt.Errorf("failed to set lz4 compression level: %w", err)
+			}
+		}
+		return writer, nil
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+// NewReader returns a reader decompressing the data read from r.
+// When the algorithm is empty or "none", the data is read from r as is.
+//
+// Returns an error if the algorithm is not supported or the stream header is invalid.
+func NewReader(r io.Reader, algorithm string) (io.ReadCloser, error) {
+	if err := ValidateAlgorithm(algorithm); err != nil {
+		return nil, err
+	}
+
+	switch algorithm {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "zstd":
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		return decoder.IOReadCloser(), nil
+	case "lz4":
+		return io.NopCloser(lz4.NewReader(r)), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// OpenFile opens a backup file and decompresses it on the fly, based on its extension.
+//
+// Returns a reader of the decompressed data, which closes the file when closed.
+func OpenFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup file: %w", err)
+	}
+
+	reader, err := NewReader(file, FromExtension(path))
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &fileReader{ReadCloser: reader, file: file}, nil
+}
+
+// fileReader closes the underlying file together with the decompressing reader
+type fileReader struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (f *fileReader) Close() error {
+	err := f.ReadCloser.Close()
+	if fileErr := f.file.Close(); err == nil {
+		err = fileErr
+	}
+	return err
+}
+
+// nopWriteCloser is a writer whose Close method does nothing
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
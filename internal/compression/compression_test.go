@@ -0,0 +1,102 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("INSERT INTO logs VALUES (1, 'sentinel');\n", 1000))
+
+	tests := []struct {
+		algorithm string
+		level     int
+	}{
+		{"gzip", 0},
+		{"gzip", 9},
+		{"zstd", 0},
+		{"zstd", 19},
+		{"lz4", 0},
+		{"lz4", 9},
+		{"none", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			var compressed bytes.Buffer
+
+			w, err := NewWriter(&compressed, tt.algorithm, tt.level)
+			if err != nil {
+				t.Fatalf("NewWriter() error = %v", err)
+			}
+			if _, err := w.Write(data); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			if Enabled(tt.algorithm) && compressed.Len() >= len(data) {
+				t.Errorf("compressed size = %d, want less than %d", compressed.Len(), len(data))
+			}
+
+			r, err := NewReader(&compressed, tt.algorithm)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			defer r.Close()
+
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("round trip mismatch: got %d bytes, want %d", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestValidateLevel(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		level     int
+		wantErr   bool
+	}{
+		{"gzip", 0, false},
+		{"gzip", 10, true},
+		{"zstd", 22, false},
+		{"zstd", 23, true},
+		{"lz4", -1, true},
+		{"none", 42, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			if err := ValidateLevel(tt.algorithm, tt.level); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLevel(%s, %d) error = %v, wantErr %v", tt.algorithm, tt.level, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFromExtension(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"backup.sql.gz", "gzip"},
+		{"backup.tar.zst", "zstd"},
+		{"backup.sql.lz4", "lz4"},
+		{"backup.sql", ""},
+		{"backup", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromExtension(tt.name); got != tt.want {
+				t.Errorf("FromExtension(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PasswordEnv is the environment variable holding the database password
+const PasswordEnv = "SENTINEL_DB_PASSWORD"
+
+// SSHKeyPassphraseEnv is the environment variable holding the passphrase of the SSH private key
+const SSHKeyPassphraseEnv = "SENTINEL_SSH_KEY_PASSPHRASE"
+
+// ResolvePassword returns the database password from, in order of precedence,
+// the --password flag, the --password-file file or the SENTINEL_DB_PASSWORD
+// environment variable. The trailing line break of the password file is ignored,
+// and the flag and the environment variable may hold a secret reference.
+//
+// Returns an error if both the flag and the file are provided or the file cannot be read.
+func ResolvePassword(password, passwordFile string) (string, error) {
+	return resolveSecret("password", password, passwordFile, PasswordEnv)
+}
+
+// ResolveSSHKeyPassphrase returns the passphrase of the SSH private key from, in order of
+// precedence, the --ssh-key-passphrase flag, the --ssh-key-passphrase-file file or the
+// SENTINEL_SSH_KEY_PASSPHRASE environment variable, like ResolvePassword.
+//
+// Returns an error if both the flag and the file are provided or the file cannot be read.
+func ResolveSSHKeyPassphrase(passphrase, passphraseFile string) (string, error) {
+	return resolveSecret("ssh-key-passphrase", passphrase, passphraseFile, SSHKeyPassphraseEnv)
+}
+
+// resolveSecret returns the secret from the flag, the file or the environment variable
+func resolveSecret(flag, value, file, env string) (string, error) {
+	if value != "" && file != "" {
+		return "", fmt.Errorf("--%s and --%s-file are mutually exclusive", flag, flag)
+	}
+
+	if value != "" {
+		return Resolve(value)
+	}
+
+	if file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s file: %w", flag, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+
+	return Resolve(os.Getenv(env))
+}
+
+// Environ returns the environment of the current process with the provided
+// "KEY=value" variables added, replacing the inherited variables with the same key.
+// Commands must keep the inherited environment, e.g. PATH, HOME or the locale.
+func Environ(vars ...string) []string {
+	env := make([]string, 0, len(os.Environ())+len(vars))
+
+	for _, inherited := range os.Environ() {
+		key, _, _ := strings.Cut(inherited, "=")
+
+		overridden := false
+		for _, v := range vars {
+			if strings.HasPrefix(v, key+"=") {
+				overridden = true
+				break
+			}
+		}
+
+		if !overridden {
+			env = append(env, inherited)
+		}
+	}
+
+	return append(env, vars...)
+}
@@ -0,0 +1,149 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePassword(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		password     string
+		passwordFile string
+		env          string
+		want         string
+		wantErr      bool
+	}{
+		{name: "Flag", password: "from-flag", env: "from-env", want: "from-flag"},
+		{name: "File", passwordFile: passwordFile, env: "from-env", want: "from-file"},
+		{name: "Environment", env: "from-env", want: "from-env"},
+		{name: "No password", want: ""},
+		{name: "Flag and file", password: "from-flag", passwordFile: passwordFile, wantErr: true},
+		{name: "Missing file", passwordFile: filepath.Join(t.TempDir(), "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PasswordEnv, tt.env)
+
+			got, err := ResolvePassword(tt.password, tt.passwordFile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolvePassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ResolvePassword() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveSSHKeyPassphrase(t *testing.T) {
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	if err := os.WriteFile(passphraseFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(PasswordEnv, "database-password")
+
+	tests := []struct {
+		name           string
+		passphrase     string
+		passphraseFile string
+		env            string
+		want           string
+		wantErr        bool
+	}{
+		{name: "Flag", passphrase: "from-flag", env: "from-env", want: "from-flag"},
+		{name: "File", passphraseFile: passphraseFile, env: "from-env", want: "from-file"},
+		{name: "Environment", env: "from-env", want: "from-env"},
+		{name: "Flag and file", passphrase: "from-flag", passphraseFile: passphraseFile, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(SSHKeyPassphraseEnv, tt.env)
+
+			got, err := ResolveSSHKeyPassphrase(tt.passphrase, tt.passphraseFile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveSSHKeyPassphrase() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSSHKeyPassphrase() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("PGPASSFILE", "/inherited")
+	t.Setenv("SENTINEL_TEST_KEEP", "kept")
+
+	env := Environ("PGPASSFILE=/tmp/sentinel.pgpass")
+
+	var pgPassFiles []string
+	kept := false
+	for _, v := range env {
+		switch v {
+		case "PGPASSFILE=/inherited", "PGPASSFILE=/tmp/sentinel.pgpass":
+			pgPassFiles = append(pgPassFiles, v)
+		case "SENTINEL_TEST_KEEP=kept":
+			kept = true
+		}
+	}
+
+	if len(pgPassFiles) != 1 || pgPassFiles[0] != "PGPASSFILE=/tmp/sentinel.pgpass" {
+		t.Errorf("Environ() PGPASSFILE = %v, want the override only", pgPassFiles)
+	}
+	if !kept {
+		t.Errorf("Environ() dropped the inherited environment")
+	}
+}
+
+func TestSecretFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(string) (string, func(), error)
+		in    string
+		want  string
+	}{
+		{name: "MySQL option file", write: MySQLOptionFile, in: `p@ss#word`, want: "[client]\npassword=\"p@ss#word\"\n"},
+		{name: "MySQL option file escaping", write: MySQLOptionFile, in: `a\b"c`, want: "[client]\npassword='a\\\\b\"c'\n"},
+		{name: "MySQL option file with both quotes", write: MySQLOptionFile, in: `it's "q"`, want: "[client]\npassword='it\\'s \"q\"'\n"},
+		{name: "pgpass file", write: PgPassFile, in: `secret`, want: "*:*:*:*:secret\n"},
+		{name: "pgpass file escaping", write: PgPassFile, in: `a:b\c`, want: "*:*:*:*:a\\:b\\\\c\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cleanup, err := tt.write(tt.in)
+			if err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("permissions = %v, want 0600", info.Mode().Perm())
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("content = %q, want %q", b, tt.want)
+			}
+
+			cleanup()
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("secret file %s was not removed", path)
+			}
+		})
+	}
+}
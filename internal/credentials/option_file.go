@@ -0,0 +1,122 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var (
+	tempFiles   = map[string]struct{}{} // secret files not removed yet
+	tempFilesMu sync.Mutex
+	signals     chan os.Signal // interrupt signals, nil when no secret file is left
+)
+
+// MySQLOptionFile writes the password to a temporary MySQL option file, readable
+// by the current user only, to be passed with --defaults-extra-file. Unlike
+// --password or MYSQL_PWD, the password does not show up in the process list.
+//
+// Returns the path of the file and the function removing it.
+func MySQLOptionFile(password string) (string, func(), error) {
+	// escape sequences are interpreted inside the quoted value
+	value := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(password)
+
+	// the quote chosen is escaped inside the value when the password holds both
+	quote := `"`
+	if strings.Contains(value, `"`) {
+		quote = `'`
+	}
+	value = strings.ReplaceAll(value, quote, `\`+quote)
+
+	return writeSecretFile("sentinel-*.cnf", fmt.Sprintf("[client]\npassword=%s%s%s\n", quote, value, quote))
+}
+
+// PgPassFile writes the password to a temporary password file, readable by the
+// current user only, to be passed with the PGPASSFILE environment variable.
+// The entry matches any host, port, database and user since the file only lives
+// for a single command.
+//
+// Returns the path of the file and the function removing it.
+func PgPassFile(password string) (string, func(), error) {
+	value := strings.NewReplacer(`\`, `\\`, ":", `\:`).Replace(password)
+
+	return writeSecretFile("sentinel-*.pgpass", fmt.Sprintf("*:*:*:*:%s\n", value))
+}
+
+// writeSecretFile creates a temporary file with 0600 permissions holding the content.
+// The file is removed by the returned function, or when Sentinel is interrupted.
+func writeSecretFile(pattern, content string) (string, func(), error) {
+	file, err := os.CreateTemp("", pattern) // created with 0600 permissions
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create secret file: %w", err)
+	}
+
+	path := file.Name()
+	track(path)
+
+	cleanup := func() {
+		untrack(path)
+	}
+
+	if _, err := file.WriteString(content); err != nil {
+		_ = file.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write secret file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write secret file: %w", err)
+	}
+
+	return path, cleanup, nil
+}
+
+// track registers the secret file so that it is removed if Sentinel is
+// interrupted before the cleanup function runs
+func track(path string) {
+	tempFilesMu.Lock()
+	defer tempFilesMu.Unlock()
+
+	tempFiles[path] = struct{}{}
+
+	if signals == nil {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go removeOnSignal(signals)
+	}
+}
+
+// untrack removes the secret file. Once no secret file is left, the interrupt
+// signals are handed back, e.g. to long-running commands stopping gracefully.
+func untrack(path string) {
+	tempFilesMu.Lock()
+	defer tempFilesMu.Unlock()
+
+	_ = os.Remove(path)
+	delete(tempFiles, path)
+
+	if len(tempFiles) == 0 && signals != nil {
+		signal.Stop(signals)
+		close(signals)
+		signals = nil
+	}
+}
+
+// removeOnSignal removes the secret files and exits when Sentinel is interrupted
+func removeOnSignal(ch chan os.Signal) {
+	if _, ok := <-ch; !ok {
+		return // every secret file was removed
+	}
+
+	tempFilesMu.Lock()
+	for p := range tempFiles {
+		_ = os.Remove(p)
+	}
+	tempFilesMu.Unlock()
+
+	os.Exit(1)
+}
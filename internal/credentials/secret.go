@@ -0,0 +1,95 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Resolver resolves secret references into the secret they point to. Supported references are
+// "env:NAME" (environment variable), "file:/run/secrets/name" (file content, without the
+// trailing line break) and "vault:kv/path#key" (HashiCorp Vault KV secret). Literal values
+// starting with one of these schemes are escaped with the "literal:" prefix, which is removed,
+// e.g. "literal:env:secret" stands for the password "env:secret". Any other value is returned
+// as is. Resolved secrets are cached for the lifetime of the resolver.
+type Resolver struct {
+	Vault *VaultClient // Vault client, created from the VAULT_* environment variables when nil
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// LiteralPrefix escapes literal values that would be mistaken for a secret reference
+const LiteralPrefix = "literal:"
+
+// defaultResolver is the resolver used by Resolve
+var defaultResolver = &Resolver{}
+
+// Resolve resolves the secret reference with the default resolver
+func Resolve(value string) (string, error) {
+	return defaultResolver.Resolve(value)
+}
+
+// IsReference reports whether the value is a secret reference rather than a literal value
+func IsReference(value string) bool {
+	for _, scheme := range []string{"env:", "file:", "vault:"} {
+		if strings.HasPrefix(value, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolve returns the secret the value refers to, or the value itself, without its literal
+// prefix, if it is not a reference.
+//
+// Returns an error if the referenced secret does not exist or cannot be read.
+func (r *Resolver) Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return strings.TrimPrefix(value, LiteralPrefix), nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if secret, ok := r.cache[value]; ok {
+		return secret, nil
+	}
+
+	scheme, ref, _ := strings.Cut(value, ":")
+
+	var secret string
+	var err error
+	switch scheme {
+	case "env":
+		var ok bool
+		if secret, ok = os.LookupEnv(ref); !ok {
+			err = fmt.Errorf("environment variable %s is not set", ref)
+		}
+	case "file":
+		var b []byte
+		if b, err = os.ReadFile(ref); err == nil {
+			secret = strings.TrimRight(string(b), "\r\n")
+		}
+	case "vault":
+		if r.Vault == nil {
+			if r.Vault, err = NewVaultClientFromEnv(); err != nil {
+				break
+			}
+		}
+		secret, err = r.Vault.Read(ref)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", value, err)
+	}
+
+	if r.cache == nil {
+		r.cache = map[string]string{}
+	}
+	r.cache[value] = secret
+
+	return secret, nil
+}
@@ -0,0 +1,104 @@
+package credentials
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newVaultStandIn starts an HTTP server answering like Vault, with a KV version 2
+// mount named "kv" and a KV version 1 mount named "secret"
+func newVaultStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/kv/data/sentinel/prod":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"v2-secret","sa":{"type":"service_account"}},"metadata":{"version":3}}}`))
+		case "/v1/secret/sentinel/prod":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1-secret"}}`))
+		default:
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	server := newVaultStandIn(t)
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SENTINEL_TEST_SECRET", "env-secret")
+
+	tests := []struct {
+		name    string
+		value   string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Literal value", value: "plain", want: "plain"},
+		{name: "Escaped literal value", value: "literal:env:SENTINEL_TEST_SECRET", want: "env:SENTINEL_TEST_SECRET"},
+		{name: "Escaped literal prefix", value: "literal:literal:x", want: "literal:x"},
+		{name: "Environment variable", value: "env:SENTINEL_TEST_SECRET", want: "env-secret"},
+		{name: "Missing environment variable", value: "env:SENTINEL_TEST_MISSING", wantErr: true},
+		{name: "File", value: "file:" + secretFile, want: "file-secret"},
+		{name: "Missing file", value: "file:" + filepath.Join(t.TempDir(), "missing"), wantErr: true},
+		{name: "Vault KV v2", value: "vault:kv/sentinel/prod#password", token: "test-token", want: "v2-secret"},
+		{name: "Vault KV v2 object", value: "vault:kv/sentinel/prod#sa", token: "test-token", want: `{"type":"service_account"}`},
+		{name: "Vault KV v1", value: "vault:secret/sentinel/prod#password", token: "test-token", want: "v1-secret"},
+		{name: "Vault missing key", value: "vault:kv/sentinel/prod#user", token: "test-token", wantErr: true},
+		{name: "Vault missing secret", value: "vault:kv/sentinel/staging#password", token: "test-token", wantErr: true},
+		{name: "Vault invalid reference", value: "vault:kv/sentinel/prod", token: "test-token", wantErr: true},
+		{name: "Vault permission denied", value: "vault:kv/sentinel/prod#password", token: "wrong-token", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Resolver{Vault: &VaultClient{Address: server.URL, Token: tt.token}}
+
+			got, err := r.Resolve(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewVaultClientFromEnv(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+
+	r := &Resolver{}
+	if _, err := r.Resolve("vault:kv/sentinel/prod#password"); err == nil {
+		t.Errorf("Resolve() expected an error without VAULT_ADDR and VAULT_TOKEN")
+	}
+
+	server := newVaultStandIn(t)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	got, err := r.Resolve("vault:kv/sentinel/prod#password")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got != "v2-secret" {
+		t.Errorf("Resolve() got = %v, want v2-secret", got)
+	}
+}
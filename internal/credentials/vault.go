@@ -0,0 +1,145 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultClient reads secrets from the KV secrets engine of HashiCorp Vault, version 1 or 2
+type VaultClient struct {
+	Address    string       // Vault address, e.g. https://vault.example.com:8200
+	Token      string       // Vault token
+	Namespace  string       // Vault Enterprise namespace
+	HTTPClient *http.Client // HTTP client, http.DefaultClient with a timeout when nil
+}
+
+// NewVaultClientFromEnv creates a Vault client from the VAULT_ADDR, VAULT_TOKEN and
+// VAULT_NAMESPACE environment variables, as the Vault CLI does.
+//
+// Returns an error if VAULT_ADDR or VAULT_TOKEN is not set.
+func NewVaultClientFromEnv() (*VaultClient, error) {
+	client := &VaultClient{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+	}
+
+	if client.Address == "" || client.Token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required to read Vault secrets")
+	}
+
+	return client, nil
+}
+
+// Read reads a key of a KV secret, the reference being "mount/path#key", e.g. "kv/sentinel/prod#password".
+// The KV version 2 API is tried first, then the version 1 API if the secret is not found.
+//
+// Returns the value of the key, or an error if the secret or the key does not exist.
+func (c *VaultClient) Read(ref string) (string, error) {
+	secretPath, key, ok := strings.Cut(ref, "#")
+	if !ok || key == "" {
+		return "", fmt.Errorf("invalid Vault reference %q, expected mount/path#key", ref)
+	}
+
+	mount, secretPath, ok := strings.Cut(strings.Trim(secretPath, "/"), "/")
+	if !ok || secretPath == "" {
+		return "", fmt.Errorf("invalid Vault reference %q, expected mount/path#key", ref)
+	}
+
+	// KV version 2 nests the secret under data/data
+	data, found, err := c.get(fmt.Sprintf("%s/data/%s", mount, secretPath))
+	if err != nil {
+		return "", err
+	}
+	if found {
+		var nested map[string]interface{}
+		if err := json.Unmarshal(data["data"], &nested); err == nil && nested != nil {
+			return secretKey(nested, key, ref)
+		}
+	}
+
+	// KV version 1 returns the secret under data
+	data, found, err = c.get(fmt.Sprintf("%s/%s", mount, secretPath))
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("vault secret %s not found", ref)
+	}
+
+	secret := map[string]interface{}{}
+	for k, v := range data {
+		var value interface{}
+		if err := json.Unmarshal(v, &value); err != nil {
+			return "", fmt.Errorf("failed to decode vault secret: %w", err)
+		}
+		secret[k] = value
+	}
+
+	return secretKey(secret, key, ref)
+}
+
+// get reads a Vault API path.
+//
+// Returns the "data" object of the response, whether the path exists and an error if the request fails.
+func (c *VaultClient) get(apiPath string) (map[string]json.RawMessage, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(c.Address, "/"), apiPath), nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create vault request: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", c.Token)
+	if c.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.Namespace)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reach vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("vault returned %s for %s", resp.Status, apiPath)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, false, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	return body.Data, body.Data != nil, nil
+}
+
+// secretKey returns the key of the secret as a string, JSON encoding non-string values
+func secretKey(secret map[string]interface{}, key, ref string) (string, error) {
+	value, ok := secret[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in vault secret %s", key, ref)
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode vault secret key %s: %w", key, err)
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,147 @@
+package doctor
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// clientTool is a client binary Sentinel runs for an engine
+type clientTool struct {
+	name    string
+	feature string // feature needing the tool, empty when backups and restores need it
+}
+
+// clientTools maps the engines to the client binaries Sentinel runs for them
+var clientTools = map[string][]clientTool{
+	"postgres": {{name: "pg_dump"}, {name: "pg_restore"}, {name: "psql"}, {name: "pg_dumpall", feature: "--pg-globals"}, {name: "pg_basebackup", feature: "--pg-physical"}},
+	"mysql":    {{name: "mysqldump", feature: "--mysql-dump-engine tool (the default)"}, {name: "mysql"}, {name: "mysqlbinlog", feature: "binlog-archive and point-in-time restores"}},
+	"mariadb":  {{name: "mariadb-dump", feature: "--mysql-dump-engine tool (the default)"}, {name: "mariadb"}, {name: "mariadb-binlog", feature: "binlog-archive and point-in-time restores"}},
+	"mongodb":  {{name: "mongodump"}, {name: "mongorestore"}},
+	"sqlite":   {{name: "sqlite3"}},
+}
+
+// Engines lists the engines running client binaries, in the order they are checked.
+// Redis, Elasticsearch and OpenSearch are backed up without any.
+var Engines = []string{"postgres", "mysql", "mariadb", "mongodb", "sqlite"}
+
+var (
+	// distribVersionRegex matches the server version the MariaDB builds of the tools report next to their own version
+	distribVersionRegex = regexp.MustCompile(`(?:Distrib|from) (\d+(?:\.\d+)+)`)
+	// versionRegex matches the first version number of a version string
+	versionRegex = regexp.MustCompile(`\d+(?:\.\d+)+`)
+)
+
+// CheckClients looks the client binaries of the engine up in the PATH and reads their version.
+// A missing binary fails the check when the engine is used and backups or restores need it,
+// it is only a warning otherwise.
+//
+// Returns one check per binary.
+func CheckClients(engine string, used bool) []Check {
+	var checks []Check
+
+	for _, tool := range clientTools[engine] {
+		path, err := exec.LookPath(tool.name)
+		if err != nil {
+			check := Check{Name: tool.name, Status: StatusWarning, Detail: "not found in PATH"}
+			if tool.feature != "" {
+				check.Detail += ", needed by " + tool.feature
+			} else if used {
+				check.Status = StatusFailed
+			}
+			checks = append(checks, check)
+			continue
+		}
+
+		version, err := ClientVersion(tool.name)
+		if err != nil {
+			checks = append(checks, Check{Name: tool.name, Status: StatusWarning, Detail: fmt.Sprintf("%s: %s", path, err)})
+			continue
+		}
+
+		checks = append(checks, Check{Name: tool.name, Status: StatusOK, Detail: fmt.Sprintf("%s (%s)", version, path)})
+	}
+
+	return checks
+}
+
+// ClientVersion runs the client binary with --version (-version for sqlite3).
+//
+// Returns the version it reports, or an error if it cannot be run or reports none.
+func ClientVersion(name string) (string, error) {
+	flag := "--version"
+	if name == "sqlite3" {
+		flag = "-version"
+	}
+
+	output, err := exec.Command(name, flag).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s %s - %w", name, flag, err)
+	}
+
+	version := ParseVersion(string(output))
+	if version == "" {
+		return "", fmt.Errorf("no version in the output of %s %s", name, flag)
+	}
+
+	return version, nil
+}
+
+// ParseVersion reads the version number from the first line of the output of a client binary
+// or from the version of a server. The MariaDB builds of the tools report their own version
+// first and the version of the server they were built with next, which is the one returned.
+//
+// Returns the version, or an empty string if there is none.
+func ParseVersion(output string) string {
+	line := strings.SplitN(strings.TrimSpace(output), "\n", 2)[0]
+
+	if match := distribVersionRegex.FindStringSubmatch(line); match != nil {
+		return match[1]
+	}
+
+	return versionRegex.FindString(line)
+}
+
+// compareVersions compares the first parts of the versions numerically.
+//
+// Returns -1, 0 or 1 when a is older than, the same as or newer than b.
+func compareVersions(a, b string, parts int) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < parts; i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// postgresMajorParts returns the number of parts of the major version of PostgresSQL:
+// 9.6 before version 10, 16 since
+func postgresMajorParts(version string) int {
+	if compareVersions(version, "10", 1) < 0 {
+		return 2
+	}
+
+	return 1
+}
+
+// majorVersion returns the first parts of the version
+func majorVersion(version string, parts int) string {
+	split := strings.Split(version, ".")
+	return strings.Join(split[:min(parts, len(split))], ".")
+}
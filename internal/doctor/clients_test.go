@@ -0,0 +1,111 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "pg_dump", output: "pg_dump (PostgreSQL) 16.2 (Debian 16.2-1.pgdg120+2)\n", want: "16.2"},
+		{name: "PostgresSQL server", output: "PostgreSQL 15.6 on x86_64-pc-linux-gnu, compiled by gcc (Debian 12.2.0-14) 12.2.0, 64-bit", want: "15.6"},
+		{name: "mysqldump", output: "mysqldump  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)", want: "8.0.36"},
+		{name: "mysqldump of MariaDB", output: "mysqldump  Ver 10.19 Distrib 10.11.6-MariaDB, for debian-linux-gnu (x86_64)", want: "10.11.6"},
+		{name: "mariadb-dump", output: "mariadb-dump from 11.4.2-MariaDB, client 10.19 for Linux (x86_64)", want: "11.4.2"},
+		{name: "MariaDB server", output: "10.11.6-MariaDB-1:10.11.6+maria~ubu2204", want: "10.11.6"},
+		{name: "mongodump", output: "mongodump version: 100.9.4\ngit version: 3.0.2\nGo version: go1.21.5", want: "100.9.4"},
+		{name: "sqlite3", output: "3.45.1 2024-01-30 16:01:20 e876e51a0ed5c5b3126f52e532044363a014bc594cfefa87ffb5b82257ccalt1", want: "3.45.1"},
+		{name: "No version", output: "command not found", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseVersion(tt.output); got != tt.want {
+				t.Errorf("ParseVersion() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compareDumpTool(t *testing.T) {
+	tests := []struct {
+		name          string
+		engine        string
+		clientVersion string
+		serverVersion string
+		want          string
+	}{
+		{name: "pg_dump of the server version", engine: "postgres", clientVersion: "16.2", serverVersion: "16.1", want: StatusOK},
+		{name: "Newer pg_dump", engine: "postgres", clientVersion: "17.0", serverVersion: "13.14", want: StatusOK},
+		{name: "Older pg_dump - failure expected", engine: "postgres", clientVersion: "15.6", serverVersion: "16.2", want: StatusFailed},
+		{name: "Older pg_dump of PostgresSQL 9 - failure expected", engine: "postgres", clientVersion: "9.5.25", serverVersion: "9.6.24", want: StatusFailed},
+		{name: "Older mysqldump minor version", engine: "mysql", clientVersion: "8.0.36", serverVersion: "8.4.0", want: StatusWarning},
+		{name: "mysqldump of a newer patch", engine: "mysql", clientVersion: "8.0.30", serverVersion: "8.0.36", want: StatusOK},
+		{name: "Newer mariadb-dump", engine: "mariadb", clientVersion: "11.4.2", serverVersion: "10.11.6", want: StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := compareDumpTool(tt.engine, dumpTools[tt.engine], tt.clientVersion, tt.serverVersion); got != tt.want {
+				t.Errorf("compareDumpTool() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckClients(t *testing.T) {
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"pg_dump":    "#!/bin/sh\necho 'pg_dump (PostgreSQL) 16.2'\n",
+		"pg_restore": "#!/bin/sh\necho 'pg_restore (PostgreSQL) 16.2'\n",
+		"pg_dumpall": "#!/bin/sh\nexit 1\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	tests := []struct {
+		name string
+		used bool
+		want []string
+	}{
+		{name: "Engine used - failure expected", used: true, want: []string{StatusOK, StatusOK, StatusFailed, StatusWarning, StatusWarning}},
+		{name: "Engine not used", used: false, want: []string{StatusOK, StatusOK, StatusWarning, StatusWarning, StatusWarning}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, check := range CheckClients("postgres", tt.used) {
+				got = append(got, check.Status)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckClients() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatBytes(t *testing.T) {
+	tests := []struct {
+		size uint64
+		want string
+	}{
+		{size: 512, want: "512 B"},
+		{size: 1536, want: "1.5 KiB"},
+		{size: 10 * 1024 * 1024 * 1024, want: "10.0 GiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatBytes(tt.size); got != tt.want {
+				t.Errorf("formatBytes() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Status of a check
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Check is the outcome of a single preflight check
+type Check struct {
+	Name   string // what was checked, e.g. pg_dump, server or storage
+	Status string // ok, warning, failed or skipped
+	Detail string // version found, error or advice
+}
+
+// Report collects the checks of a doctor run
+type Report struct {
+	Checks []Check
+}
+
+// Add records a check
+func (r *Report) Add(check Check) {
+	r.Checks = append(r.Checks, check)
+}
+
+// Count returns the number of checks with the status
+func (r *Report) Count(status string) int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Print writes the report as a table, one line per check, followed by the totals
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+	for _, check := range r.Checks {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", check.Name, check.Status, check.Detail)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%d check(s) passed, %d warning(s), %d failed\n",
+		r.Count(StatusOK), r.Count(StatusWarning), r.Count(StatusFailed))
+
+	return err
+}
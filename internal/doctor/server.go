@@ -0,0 +1,97 @@
+package doctor
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+)
+
+// dumpTools maps the engines whose server version is compared to the version of their dump tool
+var dumpTools = map[string]string{
+	"postgres": "pg_dump",
+	"mysql":    "mysqldump",
+	"mariadb":  "mariadb-dump",
+}
+
+// CheckServer connects to the server of the engine and compares its major version to the version
+// of the dump tool: pg_dump refuses to dump servers newer than itself, and older MySQL and MariaDB
+// dump tools may miss the features of newer servers.
+//
+// Returns the check, skipped for the engines without a server to connect to.
+func CheckServer(engine string, conn *sql.Connection, uri string) Check {
+	check := Check{Name: "server"}
+
+	var serverVersion string
+	switch engine {
+	case "postgres":
+		server, err := sql.InspectPostgres(conn)
+		if err != nil {
+			check.Status, check.Detail = StatusFailed, err.Error()
+			return check
+		}
+
+		// CockroachDB is backed up with BACKUP INTO, not with pg_dump
+		if server.Flavor == backup.FlavorCockroachDB {
+			check.Status, check.Detail = StatusOK, fmt.Sprintf("CockroachDB %s, backed up without pg_dump", ParseVersion(server.Version))
+			return check
+		}
+		serverVersion = ParseVersion(server.Version)
+	case "mysql", "mariadb":
+		server, err := sql.InspectMySQL("mysql", conn)
+		if err != nil {
+			check.Status, check.Detail = StatusFailed, err.Error()
+			return check
+		}
+
+		// the versions of MySQL and MariaDB cannot be compared
+		if server.MariaDB != (engine == "mariadb") {
+			check.Status, check.Detail = StatusWarning, fmt.Sprintf("the server %s does not match the %s type", server.Version, engine)
+			return check
+		}
+		serverVersion = ParseVersion(server.Version)
+	case "mongodb":
+		if _, err := mongo.CheckConnectivity(uri, conn.TLS); err != nil {
+			check.Status, check.Detail = StatusFailed, err.Error()
+			return check
+		}
+
+		check.Status, check.Detail = StatusOK, "reachable"
+		return check
+	default:
+		check.Status, check.Detail = StatusSkipped, fmt.Sprintf("no dump tool to compare with for %s", engine)
+		return check
+	}
+
+	tool := dumpTools[engine]
+	clientVersion, err := ClientVersion(tool)
+	if err != nil {
+		check.Status, check.Detail = StatusWarning, fmt.Sprintf("server %s, %s", serverVersion, err)
+		return check
+	}
+
+	check.Status, check.Detail = compareDumpTool(engine, tool, clientVersion, serverVersion)
+
+	return check
+}
+
+// compareDumpTool compares the major version of the dump tool to the major version of the server.
+//
+// Returns the status and the detail of the check.
+func compareDumpTool(engine, tool, clientVersion, serverVersion string) (string, string) {
+	parts := 2 // MySQL and MariaDB major versions are 8.0, 8.4 or 10.11
+	if engine == "postgres" {
+		parts = postgresMajorParts(serverVersion)
+	}
+
+	detail := fmt.Sprintf("server %s, %s %s", serverVersion, tool, clientVersion)
+	if compareVersions(clientVersion, serverVersion, parts) >= 0 {
+		return StatusOK, detail
+	}
+
+	if engine == "postgres" {
+		return StatusFailed, fmt.Sprintf("%s: pg_dump refuses to dump newer servers, install the %s client", detail, majorVersion(serverVersion, parts))
+	}
+
+	return StatusWarning, fmt.Sprintf("%s: the dump tool is older than the server", detail)
+}
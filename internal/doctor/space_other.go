@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package doctor
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// freeSpace is only supported on Linux and macOS
+func freeSpace(dir string) (uint64, error) {
+	return 0, fmt.Errorf("reading the free space of %s is not supported on %s", dir, runtime.GOOS)
+}
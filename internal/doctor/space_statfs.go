@@ -0,0 +1,18 @@
+//go:build linux || darwin
+
+package doctor
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// freeSpace returns the space available to unprivileged users in the file system of the directory
+func freeSpace(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, fmt.Errorf("failed to read the free space of %s - %w", dir, err)
+	}
+
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,82 @@
+package doctor
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
+	"time"
+)
+
+// probeContent is written to the probe object of the storage check
+const probeContent = "sentinel doctor write probe\n"
+
+// CheckStorage writes a probe object to the storage and deletes it, which checks the credentials
+// of the storage and the permission to write backups to it
+func CheckStorage(params *storage.Params) Check {
+	check := Check{Name: fmt.Sprintf("storage (%s)", utils.DefaultValue(params.StorageType, "local"))}
+
+	storageHandler, err := storage.NewStorage(params)
+	if err != nil {
+		check.Status, check.Detail = StatusFailed, err.Error()
+		return check
+	}
+
+	backupPath, err := storageHandler.GetBackupPath(params.LocalPath)
+	if err != nil {
+		check.Status, check.Detail = StatusFailed, err.Error()
+		return check
+	}
+
+	probe := utils.FullPath(backupPath, fmt.Sprintf(".sentinel-doctor-%d", time.Now().UnixNano()))
+	if err := storageHandler.WriteBackup([]byte(probeContent), probe); err != nil {
+		check.Status, check.Detail = StatusFailed, fmt.Sprintf("failed to write a probe object - %s", err)
+		return check
+	}
+
+	if err := storageHandler.DeleteBackup(probe); err != nil {
+		check.Status, check.Detail = StatusWarning, fmt.Sprintf("wrote a probe object but failed to delete it - %s", err)
+		return check
+	}
+
+	check.Status, check.Detail = StatusOK, fmt.Sprintf("wrote and deleted a probe object in %s", backupPath)
+
+	return check
+}
+
+// CheckTempSpace checks the free space of the temporary directory, where the backups are staged
+// before their upload and decompressed before their restore
+func CheckTempSpace(minFree uint64) Check {
+	dir := os.TempDir()
+	check := Check{Name: "temp space"}
+
+	free, err := freeSpace(dir)
+	if err != nil {
+		check.Status, check.Detail = StatusSkipped, err.Error()
+		return check
+	}
+
+	check.Status, check.Detail = StatusOK, fmt.Sprintf("%s free in %s", formatBytes(free), dir)
+	if free < minFree {
+		check.Status = StatusWarning
+		check.Detail += fmt.Sprintf(", below %s", formatBytes(minFree))
+	}
+
+	return check
+}
+
+// formatBytes formats the size with a binary unit, e.g. 1.5 GiB
+func formatBytes(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,78 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// Extension is added to the name of the files encrypted by Sentinel
+const Extension = ".enc"
+
+// ParseKey decodes the base64 encoded 256-bit key the files are encrypted with.
+//
+// Returns the key, or an error if it is not a base64 encoded 256-bit key.
+func ParseKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be a base64 encoded 256-bit key")
+	}
+
+	return key, nil
+}
+
+// Encrypt encrypts the data with AES-256-GCM. The random nonce is written before the
+// encrypted data, which is authenticated: a tampered file fails to decrypt.
+//
+// Returns the nonce followed by the encrypted data.
+func Encrypt(data, key []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt decrypts the data encrypted by Encrypt.
+//
+// Returns an error if the key is wrong or the data was tampered with.
+func Decrypt(data, key []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("failed to decrypt: data too short")
+	}
+
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: wrong key or corrupted data")
+	}
+
+	return plain, nil
+}
+
+// newAEAD returns the AES-256-GCM cipher of the key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 256-bit long, got %d bits", len(key)*8)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
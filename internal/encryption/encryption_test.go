@@ -0,0 +1,56 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "256-bit key", value: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))},
+		{name: "128-bit key - error expected", value: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 16)), wantErr: true},
+		{name: "Not base64 - error expected", value: "not a key", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseKey(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, other := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+	data := []byte("WAL segment")
+
+	encrypted, err := Encrypt(data, key)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if bytes.Contains(encrypted, data) {
+		t.Errorf("Encrypt() left the data in clear")
+	}
+
+	got, err := Decrypt(encrypted, key)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Decrypt() got = %s, want %s", got, data)
+	}
+
+	if _, err := Decrypt(encrypted, other); err == nil {
+		t.Errorf("Decrypt() with another key, error expected")
+	}
+
+	encrypted[len(encrypted)-1] ^= 0xff
+	if _, err := Decrypt(encrypted, key); err == nil {
+		t.Errorf("Decrypt() of tampered data, error expected")
+	}
+}
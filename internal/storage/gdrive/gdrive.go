@@ -1,41 +1,87 @@
 package gdrive
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"github.com/denisakp/sentinel/internal/utils"
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// folderMimeType is the MIME type Google Drive uses for folders
+const folderMimeType = "application/vnd.google-apps.folder"
+
+// defaultChunkSizeMiB is the size of the chunks of resumable uploads
+const defaultChunkSizeMiB = 16
+
 type MyGoogleDriveClient struct {
-	folderId string
-	service  *drive.Service
+	folderId  string
+	prefix    string
+	driveId   string
+	chunkSize int
+	service   *drive.Service
 }
 
 type GoogleDriveStorage struct {
 	FolderId           string
 	ServiceAccountFile string
+	ServiceAccountKey  []byte // service account key, used instead of the file when provided
+	OAuthClientFile    string // OAuth client file used to act as a Google user
+	OAuthTokenFile     string // file storing the refresh token of the Google user
+	SharedDriveId      string // ID of the Shared Drive holding the folder
+	ChunkSizeMiB       int    // size of the resumable upload chunks in MiB
+	Prefix             string // folder hierarchy created under the folder
 }
 
-// NewGoogleDriveStorage creates a new MyGoogleDriveClient instance
+// NewGoogleDriveStorage creates a new MyGoogleDriveClient instance.
+// It authenticates with the service account file when provided, otherwise
+// as the Google user whose refresh token was stored by Authorize.
 func NewGoogleDriveStorage(gds *GoogleDriveStorage) (*MyGoogleDriveClient, error) {
 	ctx := context.Background()
-	b, err := os.ReadFile(gds.ServiceAccountFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read service account file: %w", err)
+
+	var clientOption option.ClientOption
+	if len(gds.ServiceAccountKey) > 0 {
+		clientOption = option.WithCredentialsJSON(gds.ServiceAccountKey)
+	} else if gds.ServiceAccountFile != "" {
+		b, err := os.ReadFile(gds.ServiceAccountFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account file: %w", err)
+		}
+		clientOption = option.WithCredentialsJSON(b)
+	} else {
+		tokenSource, err := userTokenSource(ctx, gds.OAuthClientFile, gds.OAuthTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		clientOption = option.WithTokenSource(tokenSource)
 	}
 
-	srv, err := drive.NewService(ctx, option.WithCredentialsJSON(b))
+	srv, err := drive.NewService(ctx, clientOption)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Google Drive service: %w", err)
 	}
 
+	// the root of a Shared Drive is addressed by the drive ID
+	folderId := utils.DefaultValue(gds.FolderId, gds.SharedDriveId)
+
+	chunkSize := gds.ChunkSizeMiB
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSizeMiB
+	}
+
 	// Code to create a new MyGoogleDriveClient instance
-	return &MyGoogleDriveClient{service: srv, folderId: gds.FolderId}, nil
+	return &MyGoogleDriveClient{
+		service:   srv,
+		folderId:  folderId,
+		prefix:    gds.Prefix,
+		driveId:   gds.SharedDriveId,
+		chunkSize: chunkSize * 1024 * 1024,
+	}, nil
 }
 
 // GetBackupPath returns the backup path for Google Drive
@@ -55,6 +101,87 @@ func (g *MyGoogleDriveClient) WriteBackup(data []byte, resource string) error {
 	return g.uploadData(resource)
 }
 
+// WriteBackupStream uploads the backup read from the reader until EOF to Google Drive, chunk by
+// chunk, without staging it on the disk. No file is created if reading fails.
+func (g *MyGoogleDriveClient) WriteBackupStream(reader io.Reader, resource string) error {
+	parentId, err := g.resolveFolderPath(g.prefix)
+	if err != nil {
+		return err
+	}
+
+	return g.uploadReader(filepath.Base(resource), parentId, reader)
+}
+
+// Exists checks if a file or folder with the name of the resource already exists in the folder layout
+func (g *MyGoogleDriveClient) Exists(resource string) (bool, error) {
+	parentId, err := g.resolveFolderPath(g.prefix)
+	if err != nil {
+		return false, err
+	}
+
+	fileId, err := g.findFile(filepath.Base(resource), parentId, "")
+	if err != nil {
+		return false, err
+	}
+
+	return fileId != "", nil
+}
+
+// ReadBackup downloads the file with the name of the resource from the folder layout
+func (g *MyGoogleDriveClient) ReadBackup(resource string) ([]byte, error) {
+	parentId, err := g.resolveFolderPath(g.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(resource)
+	fileId, err := g.findFile(name, parentId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if fileId == "" {
+		return nil, fmt.Errorf("file %s does not exist", name)
+	}
+
+	response, err := g.service.Files.Get(fileId).SupportsAllDrives(true).Download()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return data, nil
+}
+
+// DeleteBackup deletes the file with the name of the resource from the folder layout
+func (g *MyGoogleDriveClient) DeleteBackup(resource string) error {
+	parentId, err := g.resolveFolderPath(g.prefix)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(resource)
+	fileId, err := g.findFile(name, parentId, "")
+	if err != nil {
+		return err
+	}
+
+	if fileId == "" {
+		return fmt.Errorf("file %s does not exist", name)
+	}
+
+	if err := g.service.Files.Delete(fileId).SupportsAllDrives(true).Do(); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+
+	return nil
+}
+
 // createGoogleDriveFolder creates a new folder in Google Drive with the specified name
 // under the specified parent folder identified by parentId.
 // It returns the ID of the newly created folder or an error if the folder creation fails.
@@ -69,11 +196,11 @@ func (g *MyGoogleDriveClient) WriteBackup(data []byte, resource string) error {
 func (g *MyGoogleDriveClient) createGoogleDriveFolder(name, parentId string) (string, error) {
 	folderMetaData := &drive.File{
 		Name:     name,
-		MimeType: "application/vnd.google-apps.folder",
+		MimeType: folderMimeType,
 		Parents:  []string{parentId},
 	}
 
-	file, err := g.service.Files.Create(folderMetaData).Do()
+	file, err := g.service.Files.Create(folderMetaData).SupportsAllDrives(true).Do()
 	if err != nil {
 		return "", fmt.Errorf("failed to create folder: %w", err)
 	}
@@ -81,31 +208,141 @@ func (g *MyGoogleDriveClient) createGoogleDriveFolder(name, parentId string) (st
 	return file.Id, nil
 }
 
-// uploadFile uploads a file to Google Drive using the specified data and name,
-// placing it in the folder identified by parentId.
+// findFile looks for a file with the specified name under the folder identified by parentId.
+// When mimeType is not empty, only the files of this type are considered.
+// Files of Shared Drives are included, restricted to the configured drive if any.
+//
+// Returns:
+// - string: the ID of the file, or an empty string if no file matches.
+// - error: an error if the lookup fails.
+func (g *MyGoogleDriveClient) findFile(name, parentId, mimeType string) (string, error) {
+	query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false",
+		strings.ReplaceAll(name, "'", "\\'"), parentId)
+	if mimeType != "" {
+		query += fmt.Sprintf(" and mimeType = '%s'", mimeType)
+	}
+
+	call := g.service.Files.List().Q(query).Fields("files(id)").PageSize(1).
+		SupportsAllDrives(true).IncludeItemsFromAllDrives(true)
+	if g.driveId != "" {
+		call = call.Corpora("drive").DriveId(g.driveId)
+	}
+
+	list, err := call.Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to look for %s: %w", name, err)
+	}
+
+	if len(list.Files) == 0 {
+		return "", nil
+	}
+
+	return list.Files[0].Id, nil
+}
+
+// findOrCreateFolder looks for a folder with the specified name under the folder
+// identified by parentId, and creates it if it does not exist yet. This keeps the
+// folder layout stable across runs instead of duplicating it on every backup.
+//
+// Parameters:
+// - name: the name of the folder to look for.
+// - parentId: the ID of the parent folder.
+//
+// Returns:
+// - string: the ID of the existing or created folder.
+// - error: an error if the lookup or the folder creation fails.
+func (g *MyGoogleDriveClient) findOrCreateFolder(name, parentId string) (string, error) {
+	folderId, err := g.findFile(name, parentId, folderMimeType)
+	if err != nil {
+		return "", err
+	}
+
+	if folderId != "" {
+		return folderId, nil
+	}
+
+	return g.createGoogleDriveFolder(name, parentId)
+}
+
+// resolveFolderPath walks the slash separated folder path from the root folder,
+// creating the missing folders along the way.
+//
+// Returns:
+// - string: the ID of the last folder of the path, or the root folder if the path is empty.
+// - error: an error if a folder cannot be found or created.
+func (g *MyGoogleDriveClient) resolveFolderPath(folderPath string) (string, error) {
+	parentId := g.folderId
+
+	for _, name := range strings.Split(folderPath, "/") {
+		if name == "" {
+			continue
+		}
+
+		folderId, err := g.findOrCreateFolder(name, parentId)
+		if err != nil {
+			return "", err
+		}
+		parentId = folderId
+	}
+
+	return parentId, nil
+}
+
+// uploadFile uploads a local file to Google Drive, placing it in the folder identified by parentId.
+// The file is streamed from disk with a resumable upload, in chunks of the configured size,
+// so large dumps are neither loaded in memory nor restarted from scratch on a transient error.
 // The name is extracted from the provided path to ensure only the base name is used.
+// If a file with the same name already exists in the folder, its content is replaced.
 // It returns an error if the upload fails.
 //
 // Parameters:
-// - data: the byte slice containing the file data to upload.
-// - name: the full path or name of the file being uploaded.
+// - localPath: the path of the local file to upload.
 // - parentId: the ID of the parent folder where the file will be uploaded.
 //
 // Returns:
 // - error: an error if the upload fails.
-func (g *MyGoogleDriveClient) uploadFile(data []byte, name, parentId string) error {
-	name = filepath.Base(name)
+func (g *MyGoogleDriveClient) uploadFile(localPath, parentId string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return g.uploadReader(filepath.Base(localPath), parentId, file)
+}
+
+// uploadReader uploads the content read from the reader as a file with the specified name
+// under the folder identified by parentId, overwriting the file with the same name if any.
+//
+// Returns an error if the upload fails.
+func (g *MyGoogleDriveClient) uploadReader(name, parentId string, reader io.Reader) error {
 	fmt.Printf("uploading file: %s \n", name)
 
+	existingId, err := g.findFile(name, parentId, "")
+	if err != nil {
+		return err
+	}
+
+	mediaOptions := []googleapi.MediaOption{
+		googleapi.ChunkSize(g.chunkSize),
+		googleapi.ContentType("application/octet-stream"),
+	}
+
+	if existingId != "" {
+		_, err := g.service.Files.Update(existingId, &drive.File{}).Media(reader, mediaOptions...).SupportsAllDrives(true).Do()
+		if err != nil {
+			return fmt.Errorf("failed to overwrite file: %w", err)
+		}
+		return nil
+	}
+
 	fileMetadata := &drive.File{
 		Name:     name,
 		Parents:  []string{parentId},
 		MimeType: "application/octet-stream",
 	}
 
-	file := bytes.NewReader(data)
-	_, err := g.service.Files.Create(fileMetadata).Media(file).Do()
-	if err != nil {
+	if _, err := g.service.Files.Create(fileMetadata).Media(reader, mediaOptions...).SupportsAllDrives(true).Do(); err != nil {
 		return fmt.Errorf("failed to upload file: %w", err)
 	}
 
@@ -113,7 +350,7 @@ func (g *MyGoogleDriveClient) uploadFile(data []byte, name, parentId string) err
 }
 
 // uploadDirectory uploads an entire local directory to Google Drive.
-// It creates a new folder in Google Drive corresponding to the local directory,
+// It creates a folder in Google Drive corresponding to the local directory, or reuses it if it exists,
 // and recursively uploads all files and subdirectories within it.
 // The new folder is created under the folder identified by parentDir.
 // It returns an error if any part of the upload process fails.
@@ -126,7 +363,7 @@ func (g *MyGoogleDriveClient) uploadFile(data []byte, name, parentId string) err
 // - error: an error if the upload process fails.
 func (g *MyGoogleDriveClient) uploadDirectory(localDir, parentDir string) error {
 	dirName := filepath.Base(localDir)
-	folderId, err := g.createGoogleDriveFolder(dirName, parentDir)
+	folderId, err := g.findOrCreateFolder(dirName, parentDir)
 	if err != nil {
 		return err
 	}
@@ -144,12 +381,7 @@ func (g *MyGoogleDriveClient) uploadDirectory(localDir, parentDir string) error
 				return err
 			}
 		} else {
-			fileData, err := os.ReadFile(localPath)
-			if err != nil {
-				return fmt.Errorf("failed to read file data: %w", err)
-			}
-
-			if err := g.uploadFile(fileData, entry.Name(), folderId); err != nil {
+			if err := g.uploadFile(localPath, folderId); err != nil {
 				return err
 			}
 		}
@@ -159,8 +391,9 @@ func (g *MyGoogleDriveClient) uploadDirectory(localDir, parentDir string) error
 }
 
 // uploadData uploads a resource to Google Drive, which can be either a file or a directory.
+// The resource is placed in the folder layout of the client, under the root folder.
 // It checks if the resource exists; if it's a directory, it calls uploadDirectory,
-// otherwise, it reads the file data and calls uploadFile.
+// otherwise, it calls uploadFile.
 // It returns an error if the resource does not exist or if the upload fails.
 //
 // Parameters:
@@ -173,14 +406,14 @@ func (g *MyGoogleDriveClient) uploadData(resource string) error {
 		return fmt.Errorf("resource %s does not exist", resource)
 	}
 
-	if utils.IsDirectory(resource) {
-		return g.uploadDirectory(resource, g.folderId)
+	parentId, err := g.resolveFolderPath(g.prefix)
+	if err != nil {
+		return err
 	}
 
-	data, err := os.ReadFile(resource)
-	if err != nil {
-		return fmt.Errorf("failed to read resource data: %w", err)
+	if utils.IsDirectory(resource) {
+		return g.uploadDirectory(resource, parentId)
 	}
 
-	return g.uploadFile(data, resource, g.folderId)
+	return g.uploadFile(resource, parentId)
 }
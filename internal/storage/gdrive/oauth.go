@@ -0,0 +1,176 @@
+package gdrive
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/drive/v3"
+	"net"
+	"net/http"
+	"os"
+)
+
+// callbackResult is the outcome of the OAuth redirect to the loopback server
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// loadOAuthConfig reads the OAuth client file downloaded from the Google Cloud console.
+// The client must be of the "Desktop app" type so that the loopback redirect is allowed.
+//
+// Returns the OAuth configuration, or an error if the file cannot be read or parsed.
+func loadOAuthConfig(clientFile string) (*oauth2.Config, error) {
+	b, err := os.ReadFile(clientFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth client file: %w", err)
+	}
+
+	config, err := google.ConfigFromJSON(b, drive.DriveScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth client file: %w", err)
+	}
+
+	return config, nil
+}
+
+// loadToken reads the OAuth token stored by Authorize.
+//
+// Returns the token, or an error if the file cannot be read or parsed.
+func loadToken(tokenFile string) (*oauth2.Token, error) {
+	b, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth token file, run `sentinel gdrive-auth` first: %w", err)
+	}
+
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(b, token); err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth token file: %w", err)
+	}
+
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("OAuth token file %s has no refresh token", tokenFile)
+	}
+
+	return token, nil
+}
+
+// saveToken stores the OAuth token, refresh token included, readable by the current user only.
+//
+// Returns an error if the file cannot be written.
+func saveToken(tokenFile string, token *oauth2.Token) error {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode OAuth token: %w", err)
+	}
+
+	if err := os.WriteFile(tokenFile, b, 0600); err != nil {
+		return fmt.Errorf("failed to write OAuth token file: %w", err)
+	}
+
+	return nil
+}
+
+// userTokenSource returns a token source refreshing the access token from the stored refresh token.
+func userTokenSource(ctx context.Context, clientFile, tokenFile string) (oauth2.TokenSource, error) {
+	config, err := loadOAuthConfig(clientFile)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := loadToken(tokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.TokenSource(ctx, token), nil
+}
+
+// Authorize runs the OAuth consent flow for a Google user and stores the resulting
+// refresh token in tokenFile. The consent URL is passed to prompt, and Google
+// redirects the browser to a temporary loopback server that receives the code.
+//
+// Returns an error if the flow fails or the token cannot be stored.
+func Authorize(ctx context.Context, clientFile, tokenFile string, prompt func(url string)) error {
+	config, err := loadOAuthConfig(clientFile)
+	if err != nil {
+		return err
+	}
+
+	return authorize(ctx, config, tokenFile, prompt)
+}
+
+// authorize runs the loopback OAuth flow with the provided configuration
+func authorize(ctx context.Context, config *oauth2.Config, tokenFile string, prompt func(url string)) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to start OAuth callback server: %w", err)
+	}
+	defer listener.Close()
+
+	config.RedirectURL = fmt.Sprintf("http://%s/", listener.Addr().String())
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	state := hex.EncodeToString(stateBytes)
+
+	results := make(chan callbackResult, 1)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// browsers also request the favicon, only the redirect carries the code
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+
+		var result error
+		switch {
+		case query.Get("state") != state:
+			http.Error(w, "invalid OAuth state", http.StatusBadRequest)
+			result = fmt.Errorf("invalid OAuth state in callback")
+		case query.Get("error") != "":
+			http.Error(w, "authorization denied", http.StatusForbidden)
+			result = fmt.Errorf("authorization denied: %s", query.Get("error"))
+		default:
+			_, _ = fmt.Fprintln(w, "Sentinel is authorized, you can close this window.")
+		}
+
+		select {
+		case results <- callbackResult{code: query.Get("code"), err: result}:
+		default: // a callback was already received
+		}
+	})}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Close()
+
+	// offline access and forced consent make Google return a refresh token
+	prompt(config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce))
+
+	var callback callbackResult
+	select {
+	case callback = <-results:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if callback.err != nil {
+		return callback.err
+	}
+
+	token, err := config.Exchange(ctx, callback.code)
+	if err != nil {
+		return fmt.Errorf("failed to exchange OAuth code: %w", err)
+	}
+
+	return saveToken(tokenFile, token)
+}
@@ -0,0 +1,90 @@
+package gdrive
+
+import (
+	"context"
+	"golang.org/x/oauth2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_authorize(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("code") != "test-code" {
+			http.Error(w, "invalid code", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	config := &oauth2.Config{
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example.com/auth", TokenURL: tokenServer.URL},
+	}
+	tokenFile := filepath.Join(t.TempDir(), "token.json")
+
+	// the prompt plays the browser, following the redirect of the consent screen
+	prompt := func(authURL string) {
+		u, _ := url.Parse(authURL)
+		redirect := u.Query().Get("redirect_uri") + "?code=test-code&state=" + u.Query().Get("state")
+		go func() {
+			resp, err := http.Get(redirect)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := authorize(ctx, config, tokenFile, prompt); err != nil {
+		t.Fatalf("authorize() error = %v", err)
+	}
+
+	token, err := loadToken(tokenFile)
+	if err != nil {
+		t.Fatalf("loadToken() error = %v", err)
+	}
+	if token.RefreshToken != "refresh" {
+		t.Errorf("loadToken() refresh token = %v, want refresh", token.RefreshToken)
+	}
+
+	info, err := os.Stat(tokenFile)
+	if err != nil {
+		t.Fatalf("os.Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func Test_authorizeInvalidState(t *testing.T) {
+	config := &oauth2.Config{
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example.com/auth", TokenURL: "http://127.0.0.1:1/token"},
+	}
+
+	prompt := func(authURL string) {
+		u, _ := url.Parse(authURL)
+		go func() {
+			resp, err := http.Get(u.Query().Get("redirect_uri") + "?code=test-code&state=forged")
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := authorize(ctx, config, filepath.Join(t.TempDir(), "token.json"), prompt); err == nil {
+		t.Errorf("authorize() error = nil, want an invalid state error")
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/utils"
+	"strings"
+	"time"
+)
+
+// RenderLayout renders the folder layout template of the backup, e.g.
+// "{env}/{engine}/{database}/{yyyy}/{mm}/{name}". The same layout is used as
+// a sub-directory by the local storage, as a key prefix by S3 and as a folder
+// hierarchy by Google Drive. A trailing {name} segment stands for the backup
+// itself and is dropped, since every storage appends the backup name on its own.
+//
+// Returns the rendered slash separated path, or an error if the template is invalid.
+func RenderLayout(p *Params, now time.Time) (string, error) {
+	if p.Layout == "" {
+		return "", nil
+	}
+
+	layout := strings.TrimSuffix(strings.TrimSuffix(p.Layout, "/"), "{name}")
+	if strings.Contains(layout, "{name}") {
+		return "", fmt.Errorf("the {name} placeholder must be the last segment of the layout %q", p.Layout)
+	}
+
+	rendered, err := utils.RenderTemplate(layout, map[string]string{
+		"env":      p.Env,
+		"job":      p.Job,
+		"engine":   p.Engine,
+		"database": p.Database,
+		"yyyy":     now.Format("2006"),
+		"mm":       now.Format("01"),
+		"dd":       now.Format("02"),
+		"hh":       now.Format("15"),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return utils.CleanPathSegments(rendered), nil
+}
+
+// IsDatedLayout reports whether the folder layout depends on the time of the backup.
+// Files fetched back by name, such as archived WAL segments, cannot be found in such a layout.
+func IsDatedLayout(layout string) bool {
+	for _, placeholder := range []string{"{yyyy}", "{mm}", "{dd}", "{hh}"} {
+		if strings.Contains(layout, placeholder) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderLayout(t *testing.T) {
+	now := time.Date(2024, time.March, 7, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		args    *Params
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "No layout",
+			args:    &Params{Engine: "postgres", Database: "app"},
+			want:    "",
+			wantErr: false,
+		},
+		{
+			name:    "Full layout with trailing name",
+			args:    &Params{Env: "prod", Engine: "postgres", Database: "app", Layout: "{env}/{engine}/{database}/{yyyy}/{mm}/{name}"},
+			want:    "prod/postgres/app/2024/03",
+			wantErr: false,
+		},
+		{
+			name:    "Empty placeholder is skipped",
+			args:    &Params{Engine: "mysql", Database: "shop", Layout: "{env}/{engine}/{database}/{dd}"},
+			want:    "mysql/shop/07",
+			wantErr: false,
+		},
+		{
+			name:    "Values cannot add path segments",
+			args:    &Params{Job: "../nightly/full", Layout: "backups/{job}"},
+			want:    "backups/.._nightly_full",
+			wantErr: false,
+		},
+		{
+			name:    "Name in the middle - error expected",
+			args:    &Params{Layout: "{name}/{engine}"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown placeholder - error expected",
+			args:    &Params{Layout: "{region}/{engine}"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderLayout(tt.args, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenderLayout() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RenderLayout() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDatedLayout(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   bool
+	}{
+		{layout: "", want: false},
+		{layout: "{env}/{engine}/{database}", want: false},
+		{layout: "{engine}/{yyyy}/{mm}/{name}", want: true},
+		{layout: "wal/{hh}", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			if got := IsDatedLayout(tt.layout); got != tt.want {
+				t.Errorf("IsDatedLayout() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
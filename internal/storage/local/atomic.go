@@ -0,0 +1,51 @@
+package local
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// writeFileAtomic writes the file next to the resource, flushes it to the disk and renames it
+// once complete, so that the resource is either missing or complete, even after a crash.
+// Callers such as the archive_command of PostgresSQL consider the file stored once it returns.
+//
+// Returns an error if the file cannot be written, flushed or renamed.
+func writeFileAtomic(resource string, write func(w io.Writer) error) error {
+	// created like os.Create, with the permissions of the umask, but never over an existing file
+	temp := resource + ".tmp-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	file, err := os.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(temp) // does nothing once renamed
+
+	if err := write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to flush file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	if err := os.Rename(temp, resource); err != nil {
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	// persist the rename, not every platform can flush a directory
+	if dir, err := os.Open(filepath.Dir(resource)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+
+	return nil
+}
@@ -3,15 +3,21 @@ package local
 import (
 	"fmt"
 	"github.com/denisakp/sentinel/internal/utils"
+	"io"
+	"os"
+	"path/filepath"
 )
 
 // LocalStorage is a struct that implements the Storage interface.
-type LocalStorage struct{}
+type LocalStorage struct {
+	Prefix string // folder layout rendered under the backup directory
+}
 
 // GetBackupPath returns the path where the backup will be stored.
 // This implementation is part of the LocalStorage struct, which
 // manages local backup paths. The function determines the local backup
-// directory path and ensures that the directory exists, creating it if necessary.
+// directory path, appends the folder layout prefix and ensures that the
+// directory exists, creating it if necessary.
 //
 // Returns the determined backup path or an error if path determination
 // or directory creation fails.
@@ -22,6 +28,8 @@ func (ls *LocalStorage) GetBackupPath(path string) (string, error) {
 		return "", err
 	}
 
+	localBackupPath = filepath.Join(localBackupPath, filepath.FromSlash(ls.Prefix))
+
 	// Ensure the directory exists or create it
 	if err := createDirIfNotExists(localBackupPath); err != nil {
 		return "", err
@@ -35,13 +43,43 @@ func (ls *LocalStorage) GetBackupPath(path string) (string, error) {
 // the logic for managing backups on the local file system.
 // If the provided path is a directory, the function will copy the
 // contents of the backup data (as multiple files) into that directory.
-// If the path is a file, it creates the file and writes the backup data to it.
+// If the path is a file, it writes the backup data to a temporary file
+// renamed to the path once flushed to the disk.
 //
 // Returns an error if the path is invalid, if the directory cannot be
 // accessed, or if file operations fail.
 func (ls *LocalStorage) WriteBackup(data []byte, resource string) error {
+	var err error
+	if utils.IsDirectory(resource) {
+		err = utils.WriteData(data, resource)
+	} else {
+		err = writeFileAtomic(resource, func(w io.Writer) error {
+			if _, err := w.Write(data); err != nil {
+				return fmt.Errorf("failed to write data to file: %w", err)
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return err
+	}
 
-	if err := utils.WriteData(data, resource); err != nil {
+	fmt.Printf("Backup successfully written to %s\n", resource)
+
+	return nil
+}
+
+// WriteBackupStream writes the backup read from the reader until EOF to the specified path,
+// through a temporary file renamed to the path once flushed to the disk. Nothing is left at
+// the path if reading fails.
+func (ls *LocalStorage) WriteBackupStream(reader io.Reader, resource string) error {
+	err := writeFileAtomic(resource, func(w io.Writer) error {
+		if _, err := io.Copy(w, reader); err != nil {
+			return fmt.Errorf("failed to write data to file: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -49,3 +87,27 @@ func (ls *LocalStorage) WriteBackup(data []byte, resource string) error {
 
 	return nil
 }
+
+// Exists checks if a backup file or directory already exists at the specified path.
+func (ls *LocalStorage) Exists(resource string) (bool, error) {
+	return utils.PathExists(resource), nil
+}
+
+// ReadBackup reads the backup file stored at the specified path.
+func (ls *LocalStorage) ReadBackup(resource string) ([]byte, error) {
+	data, err := os.ReadFile(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	return data, nil
+}
+
+// DeleteBackup deletes the backup file or directory stored at the specified path.
+func (ls *LocalStorage) DeleteBackup(resource string) error {
+	if err := os.RemoveAll(resource); err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/utils"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultOutNameTemplate is the backup name used when the user does not provide one
+const defaultOutNameTemplate = "SENTINEL_{timestamp}"
+
+// timestampRegex matches the {timestamp:format} placeholder, format being a Go time layout
+var timestampRegex = regexp.MustCompile(`\{timestamp:([^{}]+)}`)
+
+// RenderOutName renders the backup name template, e.g. "{engine}_{db}_{timestamp:20060102}".
+// Supported placeholders are {db}, {host}, {engine}, {job}, {env}, {timestamp}
+// and {timestamp:format} where format is a Go time layout.
+//
+// Returns the rendered name, or an error if the template references an unknown placeholder.
+func RenderOutName(p *Params, now time.Time) (string, error) {
+	template := utils.DefaultValue(p.OutName, defaultOutNameTemplate)
+
+	values := map[string]string{
+		"db":        p.Database,
+		"host":      p.Host,
+		"engine":    p.Engine,
+		"job":       p.Job,
+		"env":       p.Env,
+		"timestamp": now.Format("2006-01-02T15-04-05"),
+	}
+
+	// the formatted timestamps are regular values, sanitized like the others
+	for _, match := range timestampRegex.FindAllStringSubmatch(template, -1) {
+		values["timestamp:"+match[1]] = now.Format(match[1])
+	}
+
+	name, err := utils.RenderTemplate(template, values)
+	if err != nil {
+		return "", err
+	}
+
+	if name == "." || name == ".." {
+		return "", fmt.Errorf("invalid backup name %q rendered from template %q", name, template)
+	}
+
+	return name, nil
+}
+
+// ResolveCollision applies the collision policy when a backup already exists at the resource path.
+// With the "suffix" policy (default) a numeric suffix is added before the extension,
+// with "overwrite" the existing backup is replaced, and with "fail" an error is returned.
+//
+// Returns the resource path the backup must be written to.
+func ResolveCollision(s Storage, resource, policy string) (string, error) {
+	exists, err := s.Exists(resource)
+	if err != nil {
+		return "", fmt.Errorf("failed to check if backup %s exists: %w", resource, err)
+	}
+
+	if !exists {
+		return resource, nil
+	}
+
+	switch utils.DefaultValue(policy, "suffix") {
+	case "overwrite":
+		return resource, nil
+	case "fail":
+		return "", fmt.Errorf("backup %s already exists", filepath.Base(resource))
+	case "suffix":
+		base, ext := utils.SplitExt(resource)
+
+		for i := 1; i <= 1000; i++ {
+			candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+
+			exists, err := s.Exists(candidate)
+			if err != nil {
+				return "", fmt.Errorf("failed to check if backup %s exists: %w", candidate, err)
+			}
+
+			if !exists {
+				return candidate, nil
+			}
+		}
+
+		return "", fmt.Errorf("unable to find a free name for backup %s", filepath.Base(resource))
+	default:
+		return "", fmt.Errorf("unsupported collision policy: %s", policy)
+	}
+}
+
+// PerDatabaseOutName returns the backup name template used when several databases are
+// backed up in the same run, adding the {db} placeholder when the template lacks it
+// so that the backups of the run do not collide.
+func PerDatabaseOutName(template string) string {
+	template = utils.DefaultValue(template, defaultOutNameTemplate)
+	if strings.Contains(template, "{db}") {
+		return template
+	}
+
+	// keep the extension of the template last, e.g. "nightly.sql" becomes "nightly_{db}.sql"
+	return SuffixedOutName(template, "_{db}")
+}
+
+// SuffixedOutName adds the suffix to the backup name template, before its extension,
+// e.g. "nightly.sql" becomes "nightly.globals.sql" with the ".globals" suffix.
+func SuffixedOutName(template, suffix string) string {
+	template = utils.DefaultValue(template, defaultOutNameTemplate)
+
+	base, ext := utils.SplitExt(template)
+	if strings.ContainsAny(ext, "{}") {
+		base, ext = template, "" // the dot belongs to a placeholder, e.g. {timestamp:2006.01.02}
+	}
+
+	return base + suffix + ext
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeStorage is a Storage reporting the resources of its map as existing
+type fakeStorage struct {
+	existing map[string]bool
+}
+
+func (f *fakeStorage) GetBackupPath(string) (string, error)      { return "", nil }
+func (f *fakeStorage) WriteBackup([]byte, string) error          { return nil }
+func (f *fakeStorage) WriteBackupStream(io.Reader, string) error { return nil }
+func (f *fakeStorage) Exists(resource string) (bool, error)      { return f.existing[resource], nil }
+func (f *fakeStorage) ReadBackup(string) ([]byte, error)         { return nil, nil }
+func (f *fakeStorage) DeleteBackup(string) error                 { return nil }
+
+func TestRenderOutName(t *testing.T) {
+	now := time.Date(2024, time.March, 7, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		args    *Params
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Default name",
+			args: &Params{},
+			want: "SENTINEL_2024-03-07T10-30-00",
+		},
+		{
+			name: "All placeholders",
+			args: &Params{OutName: "{job}-{engine}-{host}-{db}-{timestamp:20060102}", Job: "nightly", Engine: "postgres", Host: "db1", Database: "app"},
+			want: "nightly-postgres-db1-app-20240307",
+		},
+		{
+			name: "Name with extension",
+			args: &Params{OutName: "{db}_{timestamp}.sql", Database: "shop"},
+			want: "shop_2024-03-07T10-30-00.sql",
+		},
+		{
+			name: "Timestamp format with a path separator",
+			args: &Params{OutName: "{db}_{timestamp:2006/01/02}", Database: "shop"},
+			want: "shop_2024_03_07",
+		},
+		{
+			name:    "Relative name - error expected",
+			args:    &Params{OutName: "{timestamp:..}"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown placeholder - error expected",
+			args:    &Params{OutName: "{database}"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderOutName(tt.args, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenderOutName() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RenderOutName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveCollision(t *testing.T) {
+	s := &fakeStorage{existing: map[string]bool{
+		"/backups/app.sql.gz":   true,
+		"/backups/app_1.sql.gz": true,
+		"/backups/app.sql":      true,
+	}}
+
+	tests := []struct {
+		name     string
+		resource string
+		policy   string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "No collision",
+			resource: "/backups/shop.sql",
+			policy:   "fail",
+			want:     "/backups/shop.sql",
+		},
+		{
+			name:     "Suffix by default",
+			resource: "/backups/app.sql",
+			want:     "/backups/app_1.sql",
+		},
+		{
+			name:     "Suffix keeps compressed extension",
+			resource: "/backups/app.sql.gz",
+			policy:   "suffix",
+			want:     "/backups/app_2.sql.gz",
+		},
+		{
+			name:     "Overwrite",
+			resource: "/backups/app.sql",
+			policy:   "overwrite",
+			want:     "/backups/app.sql",
+		},
+		{
+			name:     "Fail - error expected",
+			resource: "/backups/app.sql",
+			policy:   "fail",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveCollision(s, tt.resource, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveCollision() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ResolveCollision() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerDatabaseOutName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "Default template", template: "", want: "SENTINEL_{timestamp}_{db}"},
+		{name: "Template with the database", template: "{engine}_{db}_{timestamp}", want: "{engine}_{db}_{timestamp}"},
+		{name: "Template with an extension", template: "nightly.sql.gz", want: "nightly_{db}.sql.gz"},
+		{name: "Template with a dotted placeholder", template: "nightly_{timestamp:2006.01.02}", want: "nightly_{timestamp:2006.01.02}_{db}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PerDatabaseOutName(tt.template); got != tt.want {
+				t.Errorf("PerDatabaseOutName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuffixedOutName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "Default template", template: "", want: "SENTINEL_{timestamp}.globals"},
+		{name: "Template with the database", template: "{db}_{timestamp}", want: "{db}_{timestamp}.globals"},
+		{name: "Template with an extension", template: "nightly.sql.gz", want: "nightly.globals.sql.gz"},
+		{name: "Template with a dotted placeholder", template: "nightly_{timestamp:2006.01.02}", want: "nightly_{timestamp:2006.01.02}.globals"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SuffixedOutName(tt.template, ".globals"); got != tt.want {
+				t.Errorf("SuffixedOutName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"github.com/denisakp/sentinel/internal/credentials"
+	"strings"
+)
+
+// ResolveSecrets resolves the secret references ("env:NAME", "file:/path", "vault:kv/path#key")
+// of the sensitive storage parameters, removing the "literal:" prefix of escaped literal values.
+// The Google service account may reference the key itself, e.g. a Kubernetes secret or a Vault key,
+// instead of the path of the key file.
+//
+// Returns an error if a referenced secret cannot be resolved.
+func ResolveSecrets(p *Params) error {
+	for _, field := range []*string{&p.AWSAccessKeyID, &p.AWSSecretAccessKey, &p.AWSSSECustomerKey} {
+		secret, err := credentials.Resolve(*field)
+		if err != nil {
+			return err
+		}
+		*field = secret
+	}
+
+	if !credentials.IsReference(p.GoogleServiceAccount) {
+		p.GoogleServiceAccount = strings.TrimPrefix(p.GoogleServiceAccount, credentials.LiteralPrefix)
+		return nil
+	}
+
+	key, err := credentials.Resolve(p.GoogleServiceAccount)
+	if err != nil {
+		return err
+	}
+	p.GoogleServiceAccountKey = key
+
+	return nil
+}
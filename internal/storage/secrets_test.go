@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("SENTINEL_TEST_AWS_KEY", "key-id")
+	t.Setenv("SENTINEL_TEST_AWS_SECRET", "secret")
+	t.Setenv("SENTINEL_TEST_SA", `{"type":"service_account"}`)
+
+	tests := []struct {
+		name    string
+		params  *Params
+		want    Params
+		wantErr bool
+	}{
+		{
+			name:   "Literal values",
+			params: &Params{AWSAccessKeyID: "key-id", AWSSecretAccessKey: "secret", GoogleServiceAccount: "/sa.json"},
+			want:   Params{AWSAccessKeyID: "key-id", AWSSecretAccessKey: "secret", GoogleServiceAccount: "/sa.json"},
+		},
+		{
+			name:   "Environment references",
+			params: &Params{AWSAccessKeyID: "env:SENTINEL_TEST_AWS_KEY", AWSSecretAccessKey: "env:SENTINEL_TEST_AWS_SECRET"},
+			want:   Params{AWSAccessKeyID: "key-id", AWSSecretAccessKey: "secret"},
+		},
+		{
+			name:   "Service account key reference",
+			params: &Params{GoogleServiceAccount: "env:SENTINEL_TEST_SA"},
+			want:   Params{GoogleServiceAccount: "env:SENTINEL_TEST_SA", GoogleServiceAccountKey: `{"type":"service_account"}`},
+		},
+		{
+			name:    "Missing secret",
+			params:  &Params{AWSSSECustomerKey: "env:SENTINEL_TEST_MISSING"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ResolveSecrets(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveSecrets() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && *tt.params != tt.want {
+				t.Errorf("ResolveSecrets() got = %+v, want %+v", *tt.params, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package sentinel_s3
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// uploadOptions holds the object settings applied to every uploaded object
+type uploadOptions struct {
+	sse            types.ServerSideEncryption // SSE-S3 or SSE-KMS encryption mode
+	kmsKeyId       string                     // KMS key used with SSE-KMS
+	customerKey    string                     // base64 encoded SSE-C key
+	customerKeyMD5 string                     // base64 encoded MD5 digest of the SSE-C key
+	storageClass   types.StorageClass         // storage class of the uploaded objects
+	tagging        string                     // URL encoded object tags
+	lockMode       types.ObjectLockMode       // object lock retention mode
+	lockDays       int                        // object lock retention period in days
+}
+
+// newUploadOptions validates the object settings provided by the user
+// and converts them into the values expected by the S3 API.
+//
+// Returns an error if an encryption mode, storage class or object lock setting is not supported.
+func newUploadOptions(s *AmazonS3Storage) (*uploadOptions, error) {
+	opts := &uploadOptions{}
+
+	switch strings.ToUpper(s.SSE) {
+	case "":
+	case "SSE-S3":
+		opts.sse = types.ServerSideEncryptionAes256
+	case "SSE-KMS":
+		opts.sse = types.ServerSideEncryptionAwsKms
+		opts.kmsKeyId = s.SSEKMSKeyId
+	case "SSE-C":
+		key, err := base64.StdEncoding.DecodeString(s.SSECustomerKey)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("SSE-C key must be a base64 encoded 256-bit key")
+		}
+		digest := md5.Sum(key)
+		opts.customerKey = s.SSECustomerKey
+		opts.customerKeyMD5 = base64.StdEncoding.EncodeToString(digest[:])
+	default:
+		return nil, fmt.Errorf("unsupported server-side encryption: %s", s.SSE)
+	}
+
+	if s.SSEKMSKeyId != "" && opts.sse != types.ServerSideEncryptionAwsKms {
+		return nil, fmt.Errorf("a KMS key ID requires SSE-KMS encryption")
+	}
+
+	if s.StorageClass != "" {
+		validStorageClass := map[string]bool{
+			"STANDARD":            true,
+			"STANDARD_IA":         true,
+			"ONEZONE_IA":          true,
+			"INTELLIGENT_TIERING": true,
+			"GLACIER_IR":          true,
+			"GLACIER":             true,
+			"DEEP_ARCHIVE":        true,
+		}
+
+		storageClass := strings.ToUpper(s.StorageClass)
+		if _, ok := validStorageClass[storageClass]; !ok {
+			return nil, fmt.Errorf("unsupported storage class: %s", s.StorageClass)
+		}
+		opts.storageClass = types.StorageClass(storageClass)
+	}
+
+	if len(s.Tags) > 0 {
+		tags := url.Values{}
+		for key, value := range s.Tags {
+			if value != "" {
+				tags.Set(key, value)
+			}
+		}
+		opts.tagging = tags.Encode()
+	}
+
+	switch strings.ToUpper(s.ObjectLockMode) {
+	case "":
+		if s.ObjectLockDays != 0 {
+			return nil, fmt.Errorf("object lock retention period requires an object lock mode")
+		}
+	case "GOVERNANCE", "COMPLIANCE":
+		if s.ObjectLockDays <= 0 {
+			return nil, fmt.Errorf("object lock retention period must be at least one day")
+		}
+		opts.lockMode = types.ObjectLockMode(strings.ToUpper(s.ObjectLockMode))
+		opts.lockDays = s.ObjectLockDays
+	default:
+		return nil, fmt.Errorf("unsupported object lock mode: %s", s.ObjectLockMode)
+	}
+
+	return opts, nil
+}
+
+// applyPutObject sets the encryption, storage class, tags and retention of a new object
+func (o *uploadOptions) applyPutObject(input *s3.PutObjectInput) {
+	if o.sse != "" {
+		input.ServerSideEncryption = o.sse
+	}
+
+	if o.kmsKeyId != "" {
+		input.SSEKMSKeyId = aws.String(o.kmsKeyId)
+	}
+
+	if o.customerKey != "" {
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = aws.String(o.customerKey)
+		input.SSECustomerKeyMD5 = aws.String(o.customerKeyMD5)
+	}
+
+	if o.storageClass != "" {
+		input.StorageClass = o.storageClass
+	}
+
+	if o.tagging != "" {
+		input.Tagging = aws.String(o.tagging)
+	}
+
+	if o.lockMode != "" {
+		// S3 rejects object lock requests that do not carry an integrity checksum
+		input.ObjectLockMode = o.lockMode
+		input.ObjectLockRetainUntilDate = aws.Time(time.Now().UTC().AddDate(0, 0, o.lockDays))
+		input.ChecksumAlgorithm = types.ChecksumAlgorithmCrc32
+	}
+}
+
+// applyGetObject sets the SSE-C headers required to download an encrypted object
+func (o *uploadOptions) applyGetObject(input *s3.GetObjectInput) {
+	if o.customerKey != "" {
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = aws.String(o.customerKey)
+		input.SSECustomerKeyMD5 = aws.String(o.customerKeyMD5)
+	}
+}
+
+// applyHeadObject sets the SSE-C headers required to read the metadata of an encrypted object
+func (o *uploadOptions) applyHeadObject(input *s3.HeadObjectInput) {
+	if o.customerKey != "" {
+		input.SSECustomerAlgorithm = aws.String("AES256")
+		input.SSECustomerKey = aws.String(o.customerKey)
+		input.SSECustomerKeyMD5 = aws.String(o.customerKeyMD5)
+	}
+}
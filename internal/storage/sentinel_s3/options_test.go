@@ -0,0 +1,111 @@
+package sentinel_s3
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"testing"
+)
+
+func Test_newUploadOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *AmazonS3Storage
+		wantErr bool
+	}{
+		{
+			name:    "No options",
+			args:    &AmazonS3Storage{},
+			wantErr: false,
+		},
+		{
+			name:    "SSE-KMS with key",
+			args:    &AmazonS3Storage{SSE: "SSE-KMS", SSEKMSKeyId: "alias/backups"},
+			wantErr: false,
+		},
+		{
+			name:    "KMS key without SSE-KMS - error expected",
+			args:    &AmazonS3Storage{SSE: "SSE-S3", SSEKMSKeyId: "alias/backups"},
+			wantErr: true,
+		},
+		{
+			name:    "SSE-C with a 256-bit key",
+			args:    &AmazonS3Storage{SSE: "sse-c", SSECustomerKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+			wantErr: false,
+		},
+		{
+			name:    "SSE-C with a short key - error expected",
+			args:    &AmazonS3Storage{SSE: "SSE-C", SSECustomerKey: "c2hvcnQ="},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported encryption - error expected",
+			args:    &AmazonS3Storage{SSE: "rot13"},
+			wantErr: true,
+		},
+		{
+			name:    "Cold storage class",
+			args:    &AmazonS3Storage{StorageClass: "deep_archive"},
+			wantErr: false,
+		},
+		{
+			name:    "Unsupported storage class - error expected",
+			args:    &AmazonS3Storage{StorageClass: "FREEZER"},
+			wantErr: true,
+		},
+		{
+			name:    "Object lock with retention",
+			args:    &AmazonS3Storage{ObjectLockMode: "COMPLIANCE", ObjectLockDays: 30},
+			wantErr: false,
+		},
+		{
+			name:    "Object lock without retention - error expected",
+			args:    &AmazonS3Storage{ObjectLockMode: "GOVERNANCE"},
+			wantErr: true,
+		},
+		{
+			name:    "Retention without object lock mode - error expected",
+			args:    &AmazonS3Storage{ObjectLockDays: 30},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newUploadOptions(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("newUploadOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_applyPutObject(t *testing.T) {
+	opts, err := newUploadOptions(&AmazonS3Storage{
+		SSE:            "SSE-KMS",
+		SSEKMSKeyId:    "alias/backups",
+		StorageClass:   "GLACIER_IR",
+		Tags:           map[string]string{"engine": "postgres", "database": "app", "job": ""},
+		ObjectLockMode: "COMPLIANCE",
+		ObjectLockDays: 7,
+	})
+	if err != nil {
+		t.Fatalf("newUploadOptions() error = %v", err)
+	}
+
+	input := &s3.PutObjectInput{}
+	opts.applyPutObject(input)
+
+	if input.ServerSideEncryption != types.ServerSideEncryptionAwsKms || *input.SSEKMSKeyId != "alias/backups" {
+		t.Errorf("applyPutObject() encryption = %v %v", input.ServerSideEncryption, *input.SSEKMSKeyId)
+	}
+	if input.StorageClass != types.StorageClassGlacierIr {
+		t.Errorf("applyPutObject() storage class = %v", input.StorageClass)
+	}
+	if *input.Tagging != "database=app&engine=postgres" {
+		t.Errorf("applyPutObject() tagging = %v", *input.Tagging)
+	}
+	if input.ObjectLockMode != types.ObjectLockModeCompliance || input.ObjectLockRetainUntilDate == nil {
+		t.Errorf("applyPutObject() object lock = %v %v", input.ObjectLockMode, input.ObjectLockRetainUntilDate)
+	}
+	if input.ChecksumAlgorithm == "" {
+		t.Errorf("applyPutObject() object lock requires a checksum algorithm")
+	}
+}
@@ -8,26 +8,39 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 	transport "github.com/aws/smithy-go/endpoints"
 	"github.com/denisakp/sentinel/internal/utils"
+	"io"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 )
 
 type MyS3Client struct {
-	Client *s3.Client
-	Bucket string
+	Client  *s3.Client
+	Bucket  string
+	prefix  string
+	options *uploadOptions
 }
 
 type AmazonS3Storage struct {
-	Bucket    string
-	Region    string
-	EndPoint  string
-	AccessKey string
-	SecretKey string
+	Bucket         string
+	Region         string
+	EndPoint       string
+	AccessKey      string
+	SecretKey      string
+	SSE            string            // server-side encryption (SSE-S3, SSE-KMS, SSE-C)
+	SSEKMSKeyId    string            // KMS key ID used with SSE-KMS
+	SSECustomerKey string            // base64 encoded 256-bit key used with SSE-C
+	StorageClass   string            // storage class (STANDARD, STANDARD_IA, GLACIER_IR, DEEP_ARCHIVE, ...)
+	Tags           map[string]string // tags added to every uploaded object
+	ObjectLockMode string            // object lock retention mode (GOVERNANCE, COMPLIANCE)
+	ObjectLockDays int               // object lock retention period in days
+	Prefix         string            // key prefix of the uploaded objects
 }
 
 type Resolver struct {
@@ -48,6 +61,11 @@ func NewS3Storage(s *AmazonS3Storage) (*MyS3Client, error) {
 
 	s.Region = utils.DefaultValue(s.Region, "us-east-1")
 
+	options, err := newUploadOptions(s)
+	if err != nil {
+		return nil, err
+	}
+
 	client := s3.New(s3.Options{
 		UsePathStyle:       true,
 		Region:             s.Region,
@@ -60,7 +78,7 @@ func NewS3Storage(s *AmazonS3Storage) (*MyS3Client, error) {
 		}),
 	})
 
-	return &MyS3Client{Client: client, Bucket: s.Bucket}, nil
+	return &MyS3Client{Client: client, Bucket: s.Bucket, prefix: s.Prefix, options: options}, nil
 }
 
 func (clt *MyS3Client) GetBackupPath(outName string) (string, error) {
@@ -78,31 +96,107 @@ func (clt *MyS3Client) WriteBackup(fileData []byte, resourcePath string) error {
 	return clt.putObject(resourcePath, fileData)
 }
 
+// WriteBackupStream uploads the backup read from the reader until EOF under the key of the
+// resource, part by part, without staging it on the disk. The multipart upload is aborted
+// if reading fails.
+func (clt *MyS3Client) WriteBackupStream(reader io.Reader, resourcePath string) error {
+	objectKey := path.Join(clt.prefix, filepath.Base(resourcePath))
+
+	return clt.uploadObject(context.Background(), clt.Bucket, objectKey, reader)
+}
+
+// Exists checks if an object, or a directory of objects, already exists under the key of the resource
+func (clt *MyS3Client) Exists(resourcePath string) (bool, error) {
+	ctx := context.Background()
+	objectKey := path.Join(clt.prefix, filepath.Base(resourcePath))
+
+	headInput := &s3.HeadObjectInput{Bucket: &clt.Bucket, Key: &objectKey}
+	clt.options.applyHeadObject(headInput)
+
+	_, err := clt.Client.HeadObject(ctx, headInput)
+	if err == nil {
+		return true, nil
+	}
+
+	var notFound *types.NotFound
+	if !errors.As(err, &notFound) {
+		return false, fmt.Errorf("error while checking object %s: %w", objectKey, err)
+	}
+
+	// directory backups are stored as several objects sharing the key as prefix
+	list, err := clt.Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  &clt.Bucket,
+		Prefix:  aws.String(objectKey + "/"),
+		MaxKeys: aws.Int32(1),
+	})
+	if err != nil {
+		return false, fmt.Errorf("error while listing objects under %s: %w", objectKey, err)
+	}
+
+	return len(list.Contents) > 0, nil
+}
+
+// ReadBackup downloads the object stored under the key of the resource
+func (clt *MyS3Client) ReadBackup(resourcePath string) ([]byte, error) {
+	objectKey := path.Join(clt.prefix, filepath.Base(resourcePath))
+
+	getInput := &s3.GetObjectInput{Bucket: &clt.Bucket, Key: &objectKey}
+	clt.options.applyGetObject(getInput)
+
+	output, err := clt.Client.GetObject(context.Background(), getInput)
+	if err != nil {
+		return nil, fmt.Errorf("error while downloading object %s: %w", objectKey, err)
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading object %s: %w", objectKey, err)
+	}
+
+	return data, nil
+}
+
+// DeleteBackup deletes the object stored under the key of the resource
+func (clt *MyS3Client) DeleteBackup(resourcePath string) error {
+	objectKey := path.Join(clt.prefix, filepath.Base(resourcePath))
+
+	if _, err := clt.Client.DeleteObject(context.Background(), &s3.DeleteObjectInput{Bucket: &clt.Bucket, Key: &objectKey}); err != nil {
+		return fmt.Errorf("error while deleting object %s: %w", objectKey, err)
+	}
+
+	return nil
+}
+
 // uploadObject uploads a single file to the specified S3 bucket.
 // It uses multipart upload for large files, with a default part size of 10 MB.
+// The encryption, storage class, tags and object lock retention configured
+// on the client are applied to the uploaded object.
 // If the object already exists, it waits until the object is confirmed to be accessible.
 //
 // Parameters:
 // - ctx: Context for request management.
 // - bucketName: Name of the S3 bucket where the file will be uploaded.
 // - objectKey: Key for the file in the S3 bucket, defining its location within the bucket.
-// - object: Byte slice containing the file data to be uploaded.
+// - object: Reader of the file data to be uploaded, read until EOF.
 //
 // Returns an error if the upload or confirmation fails.
-func (clt *MyS3Client) uploadObject(ctx context.Context, bucketName, objectKey string, object []byte) error {
-	objectBuffer := bytes.NewReader(object)
+func (clt *MyS3Client) uploadObject(ctx context.Context, bucketName, objectKey string, object io.Reader) error {
 	var partMiBs int64 = 10
 	uploader := manager.NewUploader(clt.Client, func(u *manager.Uploader) {
 		u.PartSize = partMiBs * 1024 * 1024
 		u.Concurrency = 10
 	})
 
-	rst, err := uploader.Upload(ctx, &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket:      &bucketName,
 		Key:         &objectKey,
-		Body:        objectBuffer,
+		Body:        object,
 		ContentType: aws.String("application/octet-stream"),
-	})
+	}
+	clt.options.applyPutObject(input)
+
+	rst, err := uploader.Upload(ctx, input)
 
 	if err != nil {
 		var apiErr smithy.APIError
@@ -112,7 +206,10 @@ func (clt *MyS3Client) uploadObject(ctx context.Context, bucketName, objectKey s
 		return fmt.Errorf("error while uploading object to %s: %w", bucketName, err)
 	}
 
-	if err = s3.NewObjectExistsWaiter(clt.Client).Wait(ctx, &s3.HeadObjectInput{Bucket: &bucketName, Key: &objectKey}, time.Minute); err != nil {
+	headInput := &s3.HeadObjectInput{Bucket: &bucketName, Key: &objectKey}
+	clt.options.applyHeadObject(headInput)
+
+	if err = s3.NewObjectExistsWaiter(clt.Client).Wait(ctx, headInput, time.Minute); err != nil {
 		return fmt.Errorf("error while waiting for object to be uploaded to %s: %w", bucketName, err)
 	}
 
@@ -137,7 +234,7 @@ func (clt *MyS3Client) uploadDirectory(localDir, bucketPrefix string) error {
 
 	for _, entry := range entries {
 		localPath := filepath.Join(localDir, entry.Name())
-		objectKey := filepath.Join(bucketPrefix, entry.Name())
+		objectKey := path.Join(bucketPrefix, entry.Name())
 
 		if entry.IsDir() {
 			if err := clt.uploadDirectory(localPath, objectKey); err != nil {
@@ -149,7 +246,7 @@ func (clt *MyS3Client) uploadDirectory(localDir, bucketPrefix string) error {
 				return fmt.Errorf("error while reading file data: %w", err)
 			}
 
-			if err := clt.uploadObject(context.Background(), clt.Bucket, objectKey, fileData); err != nil {
+			if err := clt.uploadObject(context.Background(), clt.Bucket, objectKey, bytes.NewReader(fileData)); err != nil {
 				return err
 			}
 		}
@@ -159,6 +256,7 @@ func (clt *MyS3Client) uploadDirectory(localDir, bucketPrefix string) error {
 }
 
 // putObject uploads a specified file or directory to the S3 bucket.
+// The object key is the base name of the resource under the client key prefix.
 // If the resource path points to a directory, it recursively uploads
 // all files and subdirectories, preserving the local structure.
 // If it is a single file, it uploads the file directly.
@@ -169,11 +267,11 @@ func (clt *MyS3Client) uploadDirectory(localDir, bucketPrefix string) error {
 //
 // Returns an error if the upload fails.
 func (clt *MyS3Client) putObject(resourcePath string, object []byte) error {
-	objectKey := filepath.Base(resourcePath)
+	objectKey := path.Join(clt.prefix, filepath.Base(resourcePath))
 
 	if utils.IsDirectory(resourcePath) {
 		return clt.uploadDirectory(resourcePath, objectKey)
 	}
 
-	return clt.uploadObject(context.Background(), clt.Bucket, objectKey, object)
+	return clt.uploadObject(context.Background(), clt.Bucket, objectKey, bytes.NewReader(object))
 }
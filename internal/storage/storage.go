@@ -6,25 +6,51 @@ import (
 	"github.com/denisakp/sentinel/internal/storage/local"
 	"github.com/denisakp/sentinel/internal/storage/sentinel_s3"
 	"github.com/denisakp/sentinel/internal/utils"
+	"io"
+	"time"
 )
 
 // Storage interface defines the methods that a storage type must implement
 type Storage interface {
-	GetBackupPath(outName string) (string, error)  // GetBackupPath returns the path to store the backup
-	WriteBackup(data []byte, outName string) error // WriteBackup writes the backup data to the specified path
+	GetBackupPath(outName string) (string, error)             // GetBackupPath returns the path to store the backup
+	WriteBackup(data []byte, outName string) error            // WriteBackup writes the backup data to the specified path
+	WriteBackupStream(reader io.Reader, outName string) error // WriteBackupStream writes the backup read until EOF, nothing is stored if reading fails
+	Exists(outName string) (bool, error)                      // Exists checks if a backup already exists at the specified path
+	ReadBackup(outName string) ([]byte, error)                // ReadBackup reads the backup data stored at the specified path
+	DeleteBackup(outName string) error                        // DeleteBackup deletes the backup stored at the specified path
 }
 
 type Params struct {
-	OutName              string
-	StorageType          string
-	LocalPath            string
-	GoogleDriveFolderId  string
-	GoogleServiceAccount string
-	AWSSecretAccessKey   string
-	AWSAccessKeyID       string
-	AWSRegion            string
-	AWSBucket            string
-	AWSBucketEndpoint    string
+	OutName                 string
+	StorageType             string
+	LocalPath               string
+	GoogleDriveFolderId     string
+	GoogleServiceAccount    string
+	GoogleServiceAccountKey string // service account key resolved from a secret reference
+	GoogleOAuthClient       string
+	GoogleOAuthToken        string
+	GoogleSharedDriveId     string
+	GoogleChunkSizeMiB      int
+	AWSSecretAccessKey      string
+	AWSAccessKeyID          string
+	AWSRegion               string
+	AWSBucket               string
+	AWSBucketEndpoint       string
+	AWSSSE                  string
+	AWSSSEKMSKeyId          string
+	AWSSSECustomerKey       string
+	AWSStorageClass         string
+	AWSObjectLockMode       string
+	AWSObjectLockDays       int
+	Job                     string // name of the backup job
+	Engine                  string // database engine being backed up
+	Database                string // name of the database being backed up
+	Host                    string // host of the database being backed up
+	Env                     string // environment the database belongs to
+	Layout                  string // folder layout template of the backups
+	OnCollision             string // policy applied when a backup with the same name exists (suffix, overwrite, fail)
+	Compression             string // algorithm Sentinel compresses single-file dumps with (gzip, zstd, lz4, none)
+	CompressionLevel        int    // compression level, 0 being the algorithm default
 }
 
 // NewStorage returns a new storage based on the storage type
@@ -32,16 +58,34 @@ func NewStorage(p *Params) (Storage, error) {
 	// set the default storage type to local if not provided
 	storageType := utils.DefaultValue(p.StorageType, "local")
 
+	// render the folder layout shared by all the storage types
+	prefix, err := RenderLayout(p, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	switch storageType {
 	case "local":
-		return &local.LocalStorage{}, nil // return a new instance of LocalStorage
+		return &local.LocalStorage{Prefix: prefix}, nil // return a new instance of LocalStorage
 	case "s3":
 		s3Clt, err := sentinel_s3.NewS3Storage(&sentinel_s3.AmazonS3Storage{
-			Bucket:    p.AWSBucket,
-			Region:    p.AWSRegion,
-			EndPoint:  p.AWSBucketEndpoint,
-			AccessKey: p.AWSAccessKeyID,
-			SecretKey: p.AWSSecretAccessKey,
+			Bucket:         p.AWSBucket,
+			Region:         p.AWSRegion,
+			EndPoint:       p.AWSBucketEndpoint,
+			AccessKey:      p.AWSAccessKeyID,
+			SecretKey:      p.AWSSecretAccessKey,
+			SSE:            p.AWSSSE,
+			SSEKMSKeyId:    p.AWSSSEKMSKeyId,
+			SSECustomerKey: p.AWSSSECustomerKey,
+			StorageClass:   p.AWSStorageClass,
+			Tags: map[string]string{
+				"job":      p.Job,
+				"engine":   p.Engine,
+				"database": p.Database,
+			},
+			ObjectLockMode: p.AWSObjectLockMode,
+			ObjectLockDays: p.AWSObjectLockDays,
+			Prefix:         prefix,
 		})
 
 		if err != nil {
@@ -53,6 +97,12 @@ func NewStorage(p *Params) (Storage, error) {
 		gDriveStorage, err := gdrive.NewGoogleDriveStorage(&gdrive.GoogleDriveStorage{
 			FolderId:           p.GoogleDriveFolderId,
 			ServiceAccountFile: p.GoogleServiceAccount,
+			ServiceAccountKey:  []byte(p.GoogleServiceAccountKey),
+			OAuthClientFile:    p.GoogleOAuthClient,
+			OAuthTokenFile:     p.GoogleOAuthToken,
+			SharedDriveId:      p.GoogleSharedDriveId,
+			ChunkSizeMiB:       p.GoogleChunkSizeMiB,
+			Prefix:             prefix,
 		})
 
 		if err != nil {
@@ -0,0 +1,49 @@
+// Package storagetest provides a storage keeping the backups in memory, for the tests of the
+// packages writing backups
+package storagetest
+
+import (
+	"io"
+	"path/filepath"
+)
+
+// Memory keeps the backups written to it in memory, by the base name of their path
+type Memory struct {
+	Backups map[string][]byte // backups written, by name
+	Writes  int               // number of backups written
+}
+
+// NewMemory returns an empty storage
+func NewMemory() *Memory {
+	return &Memory{Backups: map[string][]byte{}}
+}
+
+func (m *Memory) GetBackupPath(string) (string, error) { return "", nil }
+
+func (m *Memory) WriteBackup(data []byte, resource string) error {
+	m.Backups[filepath.Base(resource)] = data
+	m.Writes++
+	return nil
+}
+
+func (m *Memory) WriteBackupStream(reader io.Reader, resource string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	return m.WriteBackup(data, resource)
+}
+
+func (m *Memory) Exists(resource string) (bool, error) {
+	_, ok := m.Backups[filepath.Base(resource)]
+	return ok, nil
+}
+
+func (m *Memory) ReadBackup(resource string) ([]byte, error) {
+	return m.Backups[filepath.Base(resource)], nil
+}
+
+func (m *Memory) DeleteBackup(resource string) error {
+	delete(m.Backups, filepath.Base(resource))
+	return nil
+}
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"fmt"
+	"github.com/denisakp/sentinel/internal/compression"
+	"time"
 )
 
 // ValidateStorageType validates the storage type
@@ -20,18 +22,50 @@ func ValidateStorageType(storageType string) error {
 	return nil
 }
 
+// ValidateCollisionPolicy validates the policy applied when a backup already exists
+func ValidateCollisionPolicy(policy string) error {
+	validPolicy := map[string]bool{
+		"":          true,
+		"suffix":    true,
+		"overwrite": true,
+		"fail":      true,
+	}
+
+	if _, ok := validPolicy[policy]; !ok {
+		return fmt.Errorf("unsupported collision policy: %s", policy)
+	}
+
+	return nil
+}
+
 func ValidateStorage(param *Params) error {
 	if err := ValidateStorageType(param.StorageType); err != nil {
 		return err
 	}
 
+	if _, err := RenderLayout(param, time.Now()); err != nil {
+		return err
+	}
+
+	if err := ValidateCollisionPolicy(param.OnCollision); err != nil {
+		return err
+	}
+
+	if err := compression.ValidateAlgorithm(param.Compression); err != nil {
+		return err
+	}
+
+	if err := compression.ValidateLevel(param.Compression, param.CompressionLevel); err != nil {
+		return err
+	}
+
 	if param.StorageType == "google-drive" {
-		if param.GoogleDriveFolderId == "" {
-			return fmt.Errorf("google Drive folder ID is required")
+		if param.GoogleDriveFolderId == "" && param.GoogleSharedDriveId == "" {
+			return fmt.Errorf("google Drive folder ID or Shared Drive ID is required")
 		}
 
-		if param.GoogleServiceAccount == "" {
-			return fmt.Errorf("google Drive service account file is required")
+		if param.GoogleServiceAccount == "" && (param.GoogleOAuthClient == "" || param.GoogleOAuthToken == "") {
+			return fmt.Errorf("google Drive service account file, or OAuth client and token files, are required")
 		}
 	}
 
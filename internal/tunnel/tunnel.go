@@ -0,0 +1,205 @@
+package tunnel
+
+import (
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Options holds the parameters of the SSH connection to the bastion host
+type Options struct {
+	Host           string // bastion host, with an optional port (default 22)
+	User           string // SSH user
+	KeyFile        string // private key file, the SSH agent is used when not provided
+	KeyPassphrase  string // passphrase of the private key
+	KnownHostsFile string // known_hosts file used to verify the bastion (default ~/.ssh/known_hosts)
+}
+
+// Tunnel forwards a local port to a remote address through the bastion host
+type Tunnel struct {
+	client   *ssh.Client
+	listener net.Listener
+	remote   string
+	wg       sync.WaitGroup
+}
+
+// Open connects to the bastion host and listens on a random local port whose
+// connections are forwarded to the remote host and port, as seen from the bastion.
+//
+// Returns the tunnel, to be closed once the database is no longer needed.
+func Open(o *Options, remoteHost, remotePort string) (*Tunnel, error) {
+	config, closeAgent, err := clientConfig(o)
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent() // the agent is only needed to authenticate
+
+	bastion := o.Host
+	if _, _, err := net.SplitHostPort(bastion); err != nil {
+		bastion = net.JoinHostPort(bastion, "22")
+	}
+
+	client, err := ssh.Dial("tcp", bastion, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SSH bastion %s: %w", o.Host, err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to listen on a local port: %w", err)
+	}
+
+	t := &Tunnel{
+		client:   client,
+		listener: listener,
+		remote:   net.JoinHostPort(remoteHost, remotePort),
+	}
+
+	t.wg.Add(1)
+	go t.serve()
+
+	return t, nil
+}
+
+// Addr returns the local host and port the remote address is reachable at
+func (t *Tunnel) Addr() (string, string) {
+	addr := t.listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), fmt.Sprintf("%d", addr.Port)
+}
+
+// Close stops listening, closes the forwarded connections and disconnects from the bastion
+func (t *Tunnel) Close() error {
+	err := t.listener.Close()
+	if clientErr := t.client.Close(); err == nil {
+		err = clientErr
+	}
+
+	t.wg.Wait()
+
+	return err
+}
+
+// serve accepts the local connections until the tunnel is closed
+func (t *Tunnel) serve() {
+	defer t.wg.Done()
+
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			return // the listener is closed
+		}
+
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.forward(local)
+		}()
+	}
+}
+
+// forward copies the data between the local connection and the remote address until one side closes
+func (t *Tunnel) forward(local net.Conn) {
+	defer local.Close()
+
+	remote, err := t.client.Dial("tcp", t.remote)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "SSH tunnel failed to reach %s: %v\n", t.remote, err)
+		return
+	}
+	defer remote.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+
+	<-done
+}
+
+// clientConfig builds the SSH client configuration, authenticating with the private key
+// and the SSH agent and verifying the bastion against the known_hosts file.
+//
+// Returns the configuration and the function closing the connection to the agent.
+func clientConfig(o *Options) (*ssh.ClientConfig, func(), error) {
+	if o.User == "" {
+		return nil, nil, fmt.Errorf("SSH user is required")
+	}
+
+	var auth []ssh.AuthMethod
+	closeAgent := func() {}
+
+	if o.KeyFile != "" {
+		signer, err := loadKey(o.KeyFile, o.KeyPassphrase)
+		if err != nil {
+			return nil, nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+			closeAgent = func() { _ = conn.Close() }
+		}
+	}
+
+	if len(auth) == 0 {
+		return nil, nil, fmt.Errorf("SSH key file or agent (SSH_AUTH_SOCK) is required")
+	}
+
+	knownHostsFile := o.KnownHostsFile
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			closeAgent()
+			return nil, nil, fmt.Errorf("failed to locate the known_hosts file: %w", err)
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		closeAgent()
+		return nil, nil, fmt.Errorf("failed to read known_hosts file: %w", err)
+	}
+
+	return &ssh.ClientConfig{
+		User:            o.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}, closeAgent, nil
+}
+
+// loadKey reads the private key, decrypting it with the passphrase when provided
+func loadKey(keyFile, passphrase string) (ssh.Signer, error) {
+	b, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key file: %w", err)
+	}
+
+	var signer ssh.Signer
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(b, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", keyFile, err)
+	}
+
+	return signer, nil
+}
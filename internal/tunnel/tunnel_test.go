@@ -0,0 +1,246 @@
+package tunnel
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// startEchoServer starts a TCP server echoing what it receives, standing in for the database
+func startEchoServer(t *testing.T) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return listener
+}
+
+// startSSHServer starts an in-process SSH server accepting the user key and
+// serving direct-tcpip channels, standing in for the bastion host
+func startSSHServer(t *testing.T, userKey ssh.PublicKey) (net.Listener, ssh.PublicKey) {
+	t.Helper()
+
+	_, hostPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "sentinel" && bytes.Equal(key.Marshal(), userKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key for %s", conn.User())
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, config)
+		}
+	}()
+
+	return listener, hostSigner.PublicKey()
+}
+
+// serveSSH forwards the direct-tcpip channels of an SSH connection
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+
+		var target struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+
+		remote, err := net.Dial("tcp", net.JoinHostPort(target.Host, fmt.Sprintf("%d", target.Port)))
+		if err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			_ = remote.Close()
+			continue
+		}
+		go ssh.DiscardRequests(channelRequests)
+
+		go func() {
+			defer channel.Close()
+			defer remote.Close()
+			go func() { _, _ = io.Copy(remote, channel) }()
+			_, _ = io.Copy(channel, remote)
+		}()
+	}
+}
+
+// writeUserKey writes a new private key and returns its path and public key
+func writeUserKey(t *testing.T) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(private, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keyFile, signer.PublicKey()
+}
+
+// writeKnownHosts writes a known_hosts file trusting the key for the address
+func writeKnownHosts(t *testing.T, address string, key ssh.PublicKey) string {
+	t.Helper()
+
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(address)}, key) + "\n"
+	if err := os.WriteFile(knownHostsFile, []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return knownHostsFile
+}
+
+func TestOpen(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "") // only authenticate with the key file
+
+	database := startEchoServer(t)
+	databaseHost, databasePort, _ := net.SplitHostPort(database.Addr().String())
+
+	keyFile, userKey := writeUserKey(t)
+	bastion, hostKey := startSSHServer(t, userKey)
+	_, otherHostKey := startSSHServer(t, userKey)
+
+	tests := []struct {
+		name    string
+		options *Options
+		wantErr bool
+	}{
+		{
+			name:    "Forwards through the bastion",
+			options: &Options{Host: bastion.Addr().String(), User: "sentinel", KeyFile: keyFile, KnownHostsFile: writeKnownHosts(t, bastion.Addr().String(), hostKey)},
+		},
+		{
+			name:    "Unknown bastion host key",
+			options: &Options{Host: bastion.Addr().String(), User: "sentinel", KeyFile: keyFile, KnownHostsFile: writeKnownHosts(t, bastion.Addr().String(), otherHostKey)},
+			wantErr: true,
+		},
+		{
+			name:    "Rejected user",
+			options: &Options{Host: bastion.Addr().String(), User: "root", KeyFile: keyFile, KnownHostsFile: writeKnownHosts(t, bastion.Addr().String(), hostKey)},
+			wantErr: true,
+		},
+		{
+			name:    "No key nor agent",
+			options: &Options{Host: bastion.Addr().String(), User: "sentinel"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tunnel, err := Open(tt.options, databaseHost, databasePort)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			host, port := tunnel.Addr()
+			conn, err := net.Dial("tcp", net.JoinHostPort(host, port))
+			if err != nil {
+				t.Fatalf("failed to connect to the tunnel: %v", err)
+			}
+
+			if _, err := conn.Write([]byte("ping")); err != nil {
+				t.Fatal(err)
+			}
+			reply := make([]byte, 4)
+			if _, err := io.ReadFull(conn, reply); err != nil {
+				t.Fatalf("failed to read through the tunnel: %v", err)
+			}
+			if string(reply) != "ping" {
+				t.Errorf("tunnel reply = %q, want %q", reply, "ping")
+			}
+			_ = conn.Close()
+
+			if err := tunnel.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+
+			if _, err := net.Dial("tcp", net.JoinHostPort(host, port)); err == nil {
+				t.Errorf("tunnel still listening after Close()")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -32,3 +33,18 @@ func FinalOutName(outName string) string {
 func FullPath(path, fileName string) string {
 	return filepath.Join(path, fileName)
 }
+
+// SplitExt splits a file name into its base and its extension. Compression
+// extensions are kept together with the extension they wrap, so that
+// "backup.sql.gz" is split into "backup" and ".sql.gz".
+func SplitExt(name string) (string, string) {
+	ext := filepath.Ext(name)
+
+	switch ext {
+	case ".gz", ".zst", ".lz4":
+		base := strings.TrimSuffix(name, ext)
+		ext = filepath.Ext(base) + ext
+	}
+
+	return strings.TrimSuffix(name, ext), ext
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// placeholderRegex matches the {placeholder} tokens of a template
+var placeholderRegex = regexp.MustCompile(`\{([^{}]+)}`)
+
+// RenderTemplate replaces every {placeholder} of the template with its value.
+// Values containing a path separator are sanitized so that a placeholder
+// always renders to a single path segment.
+//
+// Returns an error if the template references an unknown placeholder.
+func RenderTemplate(template string, values map[string]string) (string, error) {
+	var unknown []string
+
+	rendered := placeholderRegex.ReplaceAllStringFunc(template, func(token string) string {
+		key := token[1 : len(token)-1]
+
+		value, ok := values[key]
+		if !ok {
+			unknown = append(unknown, token)
+			return token
+		}
+
+		return strings.NewReplacer("/", "_", "\\", "_").Replace(value)
+	})
+
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown placeholder %s in template %q", strings.Join(unknown, ", "), template)
+	}
+
+	return rendered, nil
+}
+
+// CleanPathSegments removes empty and relative segments from a slash separated path,
+// so that templates with empty placeholders never produce "//" or escape their root.
+//
+// Returns the cleaned path, without leading or trailing slash.
+func CleanPathSegments(path string) string {
+	var segments []string
+
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	return strings.Join(segments, "/")
+}
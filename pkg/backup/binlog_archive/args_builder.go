@@ -0,0 +1,83 @@
+package binlog_archive
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"path/filepath"
+	"time"
+)
+
+type BinlogArchiveArgs struct {
+	Type           string            // database type, mysql or mariadb
+	Host           string            // server host
+	Port           string            // server port
+	Username       string            // replication user
+	Password       string            // replication user password
+	TLS            backup.TLSOptions // TLS options
+	StartFile      string            // first binary log to archive, e.g. the one of the last dump
+	ServerID       int               // server ID the archiver registers with, the tool default when 0
+	Interval       time.Duration     // how often the binary log being written is uploaded
+	AdditionalArgs string            // Additional arguments for the mysqlbinlog command
+	Storage        *storage.Params   // Storage parameters
+}
+
+// binlogTools maps the database types to their binary log tool
+var binlogTools = map[string]string{
+	"mysql":   "mysqlbinlog",
+	"mariadb": "mariadb-binlog",
+}
+
+// argsBuilder builds the arguments of the mysqlbinlog command streaming the binary logs
+// of the server, from the start file on, as raw files into the directory
+func argsBuilder(baa *BinlogArchiveArgs, dir string) ([]string, error) {
+	if err := validateRequiredArgs(baa); err != nil {
+		return nil, err
+	}
+
+	baa.Host = utils.DefaultValue(baa.Host, "127.0.0.1") // set the default host to 127.0.0.1 if not provided
+	baa.Port = utils.DefaultValue(baa.Port, "3306")      // set the default port to 3306 if not provided
+
+	if baa.Interval <= 0 {
+		baa.Interval = time.Minute
+	} // upload the binary log being written every minute by default
+
+	args := backup.MySQLHostArgs(baa.Host, baa.Port) // connect through the host and port, or the unix socket
+	args = append(args, fmt.Sprintf("--user=%s", baa.Username))
+
+	if baa.Type == "mariadb" {
+		args = append(args, baa.TLS.MariaDBArgs()...)
+	} else {
+		args = append(args, baa.TLS.MySQLArgs()...)
+	} // add the TLS arguments if provided
+
+	if baa.Password == "" && baa.Type == "mysql" {
+		args = append(args, "--skip-password")
+	} // skip password prompt if password is not provided
+
+	args = append(args,
+		"--read-from-remote-server",
+		"--raw",
+		"--stop-never",
+		fmt.Sprintf("--result-file=%s%c", dir, filepath.Separator), // the binary logs keep their name in the directory
+	)
+
+	if baa.ServerID > 0 {
+		if baa.Type == "mariadb" {
+			args = append(args, fmt.Sprintf("--stop-never-slave-server-id=%d", baa.ServerID))
+		} else {
+			args = append(args, fmt.Sprintf("--connection-server-id=%d", baa.ServerID))
+		}
+	} // register with a server ID not used by the replicas
+
+	if baa.AdditionalArgs != "" {
+		additionalArgs := backup.ParseAdditionalArgs(baa.AdditionalArgs)
+		args = append(args, additionalArgs...)
+	} // handle additional arguments
+
+	args = backup.RemoveArgsDuplicate(args) // remove duplicate arguments
+	args = append(args, baa.StartFile)      // add the first binary log
+
+	return args, nil
+}
@@ -0,0 +1,72 @@
+package binlog_archive
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/storage"
+	"reflect"
+	"testing"
+)
+
+func TestArgsBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *BinlogArchiveArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Unsupported database type",
+			args:    &BinlogArchiveArgs{Type: "postgres", Username: "repl", StartFile: "binlog.000012"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Missing start binary log",
+			args:    &BinlogArchiveArgs{Type: "mysql", Username: "repl"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Start binary log with a path",
+			args:    &BinlogArchiveArgs{Type: "mysql", Username: "repl", StartFile: "../binlog.000012"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Dated layout - error expected",
+			args:    &BinlogArchiveArgs{Type: "mysql", Username: "repl", StartFile: "binlog.000012", Storage: &storage.Params{Layout: "binlogs/{yyyy}/{mm}"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "MySQL defaults",
+			args:    &BinlogArchiveArgs{Type: "mysql", Username: "repl", Password: "secret", StartFile: "binlog.000012"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=repl", "--read-from-remote-server", "--raw", "--stop-never", "--result-file=/tmp/binlogs/", "binlog.000012"},
+			wantErr: false,
+		},
+		{
+			name:    "MySQL with a server ID and TLS",
+			args:    &BinlogArchiveArgs{Type: "mysql", Host: "db", Port: "3307", Username: "repl", StartFile: "binlog.000012", ServerID: 4242, TLS: backup.TLSOptions{Mode: "require"}},
+			want:    []string{"--host=db", "--port=3307", "--user=repl", "--ssl-mode=REQUIRED", "--skip-password", "--read-from-remote-server", "--raw", "--stop-never", "--result-file=/tmp/binlogs/", "--connection-server-id=4242", "binlog.000012"},
+			wantErr: false,
+		},
+		{
+			name:    "MariaDB with a server ID",
+			args:    &BinlogArchiveArgs{Type: "mariadb", Username: "repl", StartFile: "mariadb-bin.000001", ServerID: 4242},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=repl", "--read-from-remote-server", "--raw", "--stop-never", "--result-file=/tmp/binlogs/", "--stop-never-slave-server-id=4242", "mariadb-bin.000001"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := argsBuilder(tt.args, "/tmp/binlogs")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("argsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
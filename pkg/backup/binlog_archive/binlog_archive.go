@@ -0,0 +1,199 @@
+package binlog_archive
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Archive streams the binary logs of a MySQL or MariaDB server into the storage, from the start
+// file on, until Sentinel is interrupted. Each binary log is uploaded once the server rotates
+// to the next one, the binary log being written being uploaded again at every interval.
+func Archive(baa *BinlogArchiveArgs) error {
+	// mysqlbinlog writes the binary logs in a directory of its own
+	dir, err := os.MkdirTemp("", "sentinel-binlog-*")
+	if err != nil {
+		return fmt.Errorf("failed to create binlog directory - %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	args, err := argsBuilder(baa, dir)
+	if err != nil {
+		return fmt.Errorf("failed to build %s args - %w", binlogTools[baa.Type], err)
+	}
+
+	// check database connectivity
+	if ok, err := sql.CheckConnectivity(baa.Type, &sql.Connection{
+		Host:     baa.Host,
+		Port:     baa.Port,
+		User:     baa.Username,
+		Password: baa.Password,
+		TLS:      &baa.TLS,
+	}); !ok {
+		return err
+	}
+
+	// get storage handler
+	storageHandler, err := storage.NewStorage(baa.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get backup path
+	backupPath, err := storageHandler.GetBackupPath(baa.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	// pass the password through a temporary option file, removed once the binary logs stream
+	removePassword := func() {}
+	if baa.Password != "" {
+		optionFile, cleanup, err := credentials.MySQLOptionFile(baa.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		removePassword = cleanup
+		args = append([]string{"--defaults-extra-file=" + optionFile}, args...) // must be the first argument
+	}
+
+	cmd := exec.Command(binlogTools[baa.Type], args...)
+
+	// capture command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s command - %w", binlogTools[baa.Type], err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	ticker := time.NewTicker(baa.Interval)
+	defer ticker.Stop()
+
+	fmt.Printf("Archiving binary logs from %s, press Ctrl+C to stop\n", baa.StartFile)
+
+	a := &archiver{dir: dir, storage: storageHandler, backupPath: backupPath, uploaded: map[string]int64{}}
+	for {
+		select {
+		case <-ticker.C:
+			// the option file is read when mysqlbinlog starts, it is not needed once the binary logs stream
+			if a.streaming() {
+				removePassword()
+			}
+
+			if err := a.sync(); err != nil {
+				_ = cmd.Process.Kill()
+				<-exited
+				return err
+			}
+		case <-stop:
+			_ = cmd.Process.Signal(os.Interrupt)
+			<-exited
+
+			// upload what was streamed until the interruption
+			if err := a.sync(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Binlog archiving stopped !\n")
+
+			return nil
+		case err := <-exited:
+			// upload what was streamed until the connection was lost
+			if syncErr := a.sync(); syncErr != nil {
+				return syncErr
+			}
+
+			return fmt.Errorf("%s command stopped - %v, %s", binlogTools[baa.Type], err, stdErr.String())
+		}
+	}
+}
+
+// archiver uploads the binary logs written by mysqlbinlog in its directory to the storage
+type archiver struct {
+	dir        string
+	storage    storage.Storage
+	backupPath string
+	uploaded   map[string]int64 // size of the binary logs when they were last uploaded
+}
+
+// streaming reports whether mysqlbinlog started writing binary logs
+func (a *archiver) streaming() bool {
+	names, _ := a.binlogs()
+	return len(names) > 0
+}
+
+// sync uploads the binary logs completed since the last sync, and the binary log being
+// written when it grew. The completed binary logs are removed once uploaded.
+func (a *archiver) sync() error {
+	names, err := a.binlogs()
+	if err != nil {
+		return err
+	}
+
+	for i, name := range names {
+		path := filepath.Join(a.dir, name)
+		current := i == len(names)-1 // the last binary log is still being written
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to read binary log %s - %w", name, err)
+		}
+
+		if size, ok := a.uploaded[name]; !ok || size != info.Size() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read binary log %s - %w", name, err)
+			}
+
+			if err := a.storage.WriteBackup(data, utils.FullPath(a.backupPath, name)); err != nil {
+				return fmt.Errorf("failed to write binary log %s to storage - %w", name, err)
+			}
+			a.uploaded[name] = int64(len(data))
+		}
+
+		if !current {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove binary log %s - %w", name, err)
+			}
+			delete(a.uploaded, name)
+		}
+	}
+
+	return nil
+}
+
+// binlogs returns the names of the binary logs of the directory, in order
+func (a *archiver) binlogs() ([]string, error) {
+	entries, err := os.ReadDir(a.dir) // sorted by name, the binary log numbers are zero-padded
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binlog directory - %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,66 @@
+package binlog_archive
+
+import (
+	"github.com/denisakp/sentinel/internal/storage/storagetest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArchiver_sync(t *testing.T) {
+	dir := t.TempDir()
+	store := storagetest.NewMemory()
+	a := &archiver{dir: dir, storage: store, uploaded: map[string]int64{}}
+
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// nothing streamed yet
+	if a.streaming() {
+		t.Errorf("streaming() got = true, want false")
+	}
+	if err := a.sync(); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+
+	// the binary log being written is uploaded, and kept locally
+	write("binlog.000012", "events")
+	if err := a.sync(); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	if !a.streaming() {
+		t.Errorf("streaming() got = false, want true")
+	}
+
+	// unchanged binary logs are not uploaded again
+	if err := a.sync(); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	if store.Writes != 1 {
+		t.Errorf("sync() writes = %d, want 1", store.Writes)
+	}
+
+	// once the server rotates, the completed binary log is uploaded in full and removed
+	write("binlog.000012", "events and more events")
+	write("binlog.000013", "new")
+	if err := a.sync(); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+
+	want := map[string][]byte{"binlog.000012": []byte("events and more events"), "binlog.000013": []byte("new")}
+	if !reflect.DeepEqual(store.Backups, want) {
+		t.Errorf("sync() backups = %v, want %v", store.Backups, want)
+	}
+
+	names, err := a.binlogs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"binlog.000013"}) {
+		t.Errorf("binlogs() got = %v, want [binlog.000013]", names)
+	}
+}
@@ -0,0 +1,37 @@
+package binlog_archive
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/storage"
+	"strings"
+)
+
+// validateRequiredArgs validates required arguments for the binary log archiving
+func validateRequiredArgs(baa *BinlogArchiveArgs) error {
+	if _, ok := binlogTools[baa.Type]; !ok {
+		return fmt.Errorf("binary log archiving is only supported for mysql and mariadb, got: %s", baa.Type)
+	}
+
+	if baa.Username == "" {
+		return fmt.Errorf("username is missing")
+	}
+
+	if baa.StartFile == "" {
+		return fmt.Errorf("start binary log is missing")
+	}
+
+	if strings.ContainsAny(baa.StartFile, `/\`) {
+		return fmt.Errorf("start binary log must be a file name, got: %s", baa.StartFile)
+	}
+
+	if baa.ServerID < 0 {
+		return fmt.Errorf("invalid server ID: %d", baa.ServerID)
+	}
+
+	// the layout is rendered once when the archiving starts, it would hold the date of the start
+	if baa.Storage != nil && storage.IsDatedLayout(baa.Storage.Layout) {
+		return fmt.Errorf("the binary logs cannot be archived in a layout depending on the date: %s", baa.Storage.Layout)
+	}
+
+	return nil
+}
@@ -8,13 +8,20 @@ import (
 )
 
 type MariaDBDumpArgs struct {
-	Host           string          // MariaDB host
-	Port           string          // MariaDB port
-	Username       string          // MariaDB username
-	Password       string          // MariaDB password
-	Database       string          // MariaDB database name
-	AdditionalArgs string          // Additional arguments for the mariadb_dump command
-	Storage        *storage.Params // Storage parameters
+	Host           string                  // MariaDB host
+	Port           string                  // MariaDB port
+	Username       string                  // MariaDB username
+	Password       string                  // MariaDB password
+	Database       string                  // MariaDB database name
+	TLS            backup.TLSOptions       // TLS options
+	Filters        backup.Filters          // Table filters
+	AdditionalArgs string                  // Additional arguments for the mariadb_dump command
+	SkipSnapshot   bool                    // skip the consistent snapshot profile, keeping the dump tool defaults
+	SkipBinlog     bool                    // skip the binary log coordinates of the consistent snapshot
+	tables         []string                // tables of the database, listed when table filters are set
+	server         *backup.MySQLServer     // server inspected before the dump, for the binlog coordinates
+	client         *backup.MySQLDumpClient // dump tool inspected before the dump, for the binlog coordinates options
+	Storage        *storage.Params         // Storage parameters
 }
 
 // ArgsBuilder builds the arguments for the mariadb_dump command
@@ -28,15 +35,20 @@ func ArgsBuilder(mda *MariaDBDumpArgs) ([]string, error) {
 	mda.Port = utils.DefaultValue(mda.Port, "3306")
 
 	// build the required arguments
-	args := []string{
-		fmt.Sprintf("--host=%s", mda.Host),
-		fmt.Sprintf("--port=%s", mda.Port),
-		fmt.Sprintf("--user=%s", mda.Username),
-	}
+	args := connectionArgs(mda)
+
+	if !mda.SkipSnapshot {
+		args = append(args, backup.MySQLSnapshotArgs(mda.server, mda.client, !mda.SkipBinlog)...)
+	} // dump a consistent snapshot with the routines, triggers and events
 
-	if mda.Password != "" {
-		args = append(args, fmt.Sprintf("--password=%s", mda.Password))
-	} // add the password argument if provided
+	// skip the tables filtered out
+	if mda.Filters.HasTables() {
+		tableArgs, _, err := mda.Filters.MySQLTableArgs(mda.Database, mda.tables)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, tableArgs...)
+	}
 
 	if mda.AdditionalArgs != "" {
 		additionalArgs := backup.ParseAdditionalArgs(mda.AdditionalArgs)
@@ -48,3 +60,30 @@ func ArgsBuilder(mda *MariaDBDumpArgs) ([]string, error) {
 
 	return args, nil
 }
+
+// noDataArgsBuilder builds the arguments of the mariadb_dump command dumping the schema
+// of the tables backed up without their data
+func noDataArgsBuilder(mda *MariaDBDumpArgs, tables []string) []string {
+	args := connectionArgs(mda)
+
+	if mda.AdditionalArgs != "" {
+		additionalArgs := backup.ParseAdditionalArgs(mda.AdditionalArgs)
+		args = append(args, additionalArgs...)
+	} // add additional arguments if provided
+
+	args = append(args, "--no-data")
+	args = backup.RemoveArgsDuplicate(args) // remove duplicated arguments
+	args = append(args, mda.Database)       // add the database name to the arguments
+	args = append(args, tables...)          // add the table names to the arguments
+
+	return args
+}
+
+// connectionArgs builds the connection arguments of the mariadb_dump command
+func connectionArgs(mda *MariaDBDumpArgs) []string {
+	args := backup.MySQLHostArgs(mda.Host, mda.Port) // connect through the host and port, or the unix socket
+	args = append(args, fmt.Sprintf("--user=%s", mda.Username))
+	args = append(args, mda.TLS.MariaDBArgs()...) // add the TLS arguments if provided
+
+	return args
+}
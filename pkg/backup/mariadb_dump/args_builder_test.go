@@ -1,6 +1,7 @@
 package mariadb_dump
 
 import (
+	"github.com/denisakp/sentinel/internal/backup"
 	"reflect"
 	"testing"
 )
@@ -33,33 +34,81 @@ func TestArgsBuilder(t *testing.T) {
 		{
 			name:    "Default Host and Port",
 			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test"},
-			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--password=root", "test"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
 			wantErr: false,
 		},
 		{
 			name:    "Provided host and port",
 			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test", Host: "us-west1.mysql.domain.com", Port: "3319"},
-			want:    []string{"--host=us-west1.mysql.domain.com", "--port=3319", "--user=root", "--password=root", "test"},
+			want:    []string{"--host=us-west1.mysql.domain.com", "--port=3319", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
 			wantErr: false,
 		},
 		{
 			name:    "Empty password",
 			args:    &MariaDBDumpArgs{Username: "root", Database: "test"},
-			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "test"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
 			wantErr: false,
 		},
 		{
 			name:    "Additional Arguments",
 			args:    &MariaDBDumpArgs{Username: "root", Database: "test", AdditionalArgs: "--flush-privileges"},
-			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--flush-privileges", "test"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "--flush-privileges", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "TLS options",
+			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test", TLS: backup.TLSOptions{Mode: "verify-full", CAFile: "/certs/ca.pem"}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--ssl", "--ssl-verify-server-cert", "--ssl-ca=/certs/ca.pem", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "TLS disabled",
+			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test", TLS: backup.TLSOptions{Mode: "disable"}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--skip-ssl", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "IPv6 host",
+			args:    &MariaDBDumpArgs{Username: "root", Database: "test", Host: "[2001:db8::1]", Port: "3307"},
+			want:    []string{"--host=2001:db8::1", "--port=3307", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
 			wantErr: false,
 		},
 		{
 			name:    "Remove duplicate",
 			args:    &MariaDBDumpArgs{Username: "root", Database: "test", AdditionalArgs: "--port=3306"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Table filters",
+			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test", Filters: backup.Filters{IncludeTables: []string{"users", "*_logs"}, ExcludeTableData: []string{"*_logs"}}, tables: []string{"users", "audit_logs", "tmp_import"}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "--ignore-table=test.audit_logs", "--ignore-table=test.tmp_import", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Snapshot profile skipped",
+			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test", SkipSnapshot: true},
 			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "test"},
 			wantErr: false,
 		},
+		{
+			name:    "Snapshot profile without the binlog coordinates",
+			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test", SkipBinlog: true, server: &backup.MySQLServer{Version: "10.11.6-MariaDB", MariaDB: true, LogBin: true, BinlogPrivileges: true}, client: &backup.MySQLDumpClient{Version: "10.11.6", MariaDB: true}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Snapshot profile with the binlog coordinates",
+			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test", server: &backup.MySQLServer{Version: "10.11.6-MariaDB", MariaDB: true, LogBin: true, BinlogPrivileges: true}, client: &backup.MySQLDumpClient{Version: "10.11.6", MariaDB: true}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "--master-data=2", "--gtid", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Snapshot profile against a MySQL server",
+			args:    &MariaDBDumpArgs{Username: "root", Password: "root", Database: "test", server: &backup.MySQLServer{Version: "8.0.36", LogBin: true, BinlogPrivileges: true}, client: &backup.MySQLDumpClient{Version: "10.11.6", MariaDB: true}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "--master-data=2", "test"},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -3,38 +3,72 @@ package mariadb_dump
 import (
 	"bytes"
 	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
 	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/credentials"
 	"github.com/denisakp/sentinel/internal/storage"
 	"github.com/denisakp/sentinel/internal/utils"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 func Backup(mda *MariaDBDumpArgs) error {
-	// Validate the required arguments
-	args, err := ArgsBuilder(mda)
-	if err != nil {
+	if err := validateRequiredArgs(mda); err != nil {
 		return fmt.Errorf("failed to build arguments: %w", err)
 	}
 
-	// check connectivity
-	if ok, err := sql.CheckConnectivity("mysql", mda.Host, mda.Port, mda.Username, mda.Password, mda.Database); !ok {
+	conn := &sql.Connection{
+		Host:     utils.DefaultValue(mda.Host, "127.0.0.1"),
+		Port:     utils.DefaultValue(mda.Port, "3306"),
+		User:     mda.Username,
+		Password: mda.Password,
+		Database: mda.Database,
+		TLS:      &mda.TLS,
+	}
+
+	// check database connectivity
+	if ok, err := sql.CheckConnectivity("mysql", conn); !ok {
 		return err
 	}
 
-	// execute mariadb-dump command
-	cmd := exec.Command("mariadb-dump", args...)
+	var err error
 
-	// capture command error
-	var stdErr bytes.Buffer
-	cmd.Stderr = &stdErr
+	// mariadb-dump has no pattern support, the table filters are applied to the tables of the database
+	if mda.Filters.HasTables() {
+		if mda.tables, err = sql.ListTables("mysql", conn); err != nil {
+			return err
+		}
+	}
 
-	// capture command output
-	var stdOut bytes.Buffer
-	cmd.Stdout = &stdOut
+	// inspect the server to capture the binlog position with the consistent snapshot
+	if !mda.SkipSnapshot {
+		if mda.server, err = sql.InspectMySQL("mysql", conn); err != nil {
+			return err
+		}
 
-	err = cmd.Run()
+		// the binlog coordinates options depend on the vendor and version of the dump tool, not of the server
+		if mda.client, err = backup.InspectMySQLDump("mariadb-dump"); err != nil {
+			return err
+		}
+
+		if len(mda.server.NonTransactional) > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: the dump of tables using a non-transactional engine is not consistent: %s\n", strings.Join(mda.server.NonTransactional, ", "))
+		}
+
+		// the dump is still taken, without the coordinates a point-in-time restore starts from
+		if mda.server.LogBin && !mda.server.BinlogPrivileges && !mda.SkipBinlog {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: the user lacks the RELOAD and REPLICATION CLIENT privileges, the dump is taken without the binary log coordinates and cannot be used for a point-in-time restore (--mysql-skip-binlog-position silences this warning)\n")
+		}
+	}
+
+	args, err := ArgsBuilder(mda)
 	if err != nil {
-		return fmt.Errorf("failed to execute maridb-dump command - %w, %s", err, stdErr.String())
+		return fmt.Errorf("failed to build arguments: %w", err)
 	}
 
 	// get the storage handler
@@ -45,18 +79,95 @@ func Backup(mda *MariaDBDumpArgs) error {
 
 	// get the backup path
 	backupPath, err := storageHandler.GetBackupPath(mda.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
 
 	// set output name with customizable extension (default is .sql)
 	mda.Storage.OutName = utils.FinalOutName(mda.Storage.OutName)
+	mda.Storage.OutName = compression.AppendExtension(mda.Storage.OutName, mda.Storage.Compression)
 
 	// get the full path
 	fullPath := utils.FullPath(backupPath, mda.Storage.OutName)
 
+	// avoid collisions with existing backups
+	fullPath, err = storage.ResolveCollision(storageHandler, fullPath, mda.Storage.OnCollision)
+	if err != nil {
+		return err
+	}
+
+	// pass the password through a temporary option file
+	var optionArgs []string
+	if mda.Password != "" {
+		optionFile, cleanup, err := credentials.MySQLOptionFile(mda.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		optionArgs = []string{"--defaults-extra-file=" + optionFile} // must be the first argument
+		args = append(optionArgs, args...)
+	}
+
+	// execute mariadb-dump command
+	cmd := exec.Command("mariadb-dump", args...)
+
+	// capture command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	// capture command output, compressed on the fly
+	var stdOut bytes.Buffer
+	out, err := compression.NewWriter(&stdOut, mda.Storage.Compression, mda.Storage.CompressionLevel)
+	if err != nil {
+		return err
+	}
+
+	// keep the head of the dump, where the binlog coordinates are written
+	head := &backup.HeadWriter{Limit: 64 * 1024}
+	cmd.Stdout = io.MultiWriter(out, head)
+
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("failed to execute maridb-dump command - %w, %s", err, stdErr.String())
+	}
+
+	// append the schema of the tables backed up without their data
+	if _, withoutData := mda.Filters.SelectTables(mda.tables); len(withoutData) > 0 {
+		noDataCmd := exec.Command("mariadb-dump", append(optionArgs, noDataArgsBuilder(mda, withoutData)...)...)
+		noDataCmd.Stderr = &stdErr
+		noDataCmd.Stdout = out
+
+		if err := noDataCmd.Run(); err != nil {
+			return fmt.Errorf("failed to execute mariadb-dump command - %w, %s", err, stdErr.String())
+		}
+	}
+
+	// flush the compressed stream
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup - %w", err)
+	}
+
 	// write backup to storage
 	if err := storageHandler.WriteBackup(stdOut.Bytes(), fullPath); err != nil {
 		return fmt.Errorf("failed to write backup to storage - %w", err)
 	}
 
+	// write the metadata next to the backup, with the binlog position it is consistent with
+	metadata := &backup.Metadata{
+		Engine:    "mariadb",
+		Database:  mda.Database,
+		Backup:    filepath.Base(fullPath),
+		CreatedAt: time.Now().UTC(),
+		Binlog:    backup.ParseBinlogPosition(head.Bytes()),
+	}
+	data, err := metadata.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := storageHandler.WriteBackup(data, fullPath+backup.MetadataSuffix); err != nil {
+		return fmt.Errorf("failed to write backup metadata to storage - %w", err)
+	}
+
 	fmt.Printf("Backup complete !\n")
 
 	return nil
@@ -3,32 +3,91 @@ package mongo_dump
 import (
 	"fmt"
 	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/compression"
 	"github.com/denisakp/sentinel/internal/storage"
 	"github.com/denisakp/sentinel/internal/utils"
+	"slices"
 )
 
 type DumpMongoArgs struct {
-	Uri            string          // MongoDB URI
-	Compress       bool            // Compress the backup file
-	AdditionalArgs string          // Additional arguments for the mongo_dump command
-	Storage        *storage.Params // Storage parameters
+	Uri            string            // MongoDB URI
+	Database       string            // database to dump, all the databases when empty
+	TLS            backup.TLSOptions // TLS options
+	Compress       bool              // Compress the backup file
+	Archive        bool              // dump as a single archive streamed to the storage, instead of a directory
+	Parallel       int               // Number of collections dumped in parallel, mongodump default when 0
+	Filters        backup.Filters    // Collection filters
+	SkipOplog      bool              // do not capture the oplog of replica sets during the dump
+	AdditionalArgs string            // Additional arguments for the mongo_dump command
+	collections    []string          // collections of the database, listed when collection filters are set
+	replicaSet     string            // name of the replica set of the instance, empty when it is not a member
+	Storage        *storage.Params   // Storage parameters
 }
 
 func argsBuilder(da *DumpMongoArgs, backupPath string) ([]string, error) {
 	// set default values
 	da.Uri = utils.DefaultValue(da.Uri, "mongodb://localhost:27017")
 
+	// mongodump writes a directory, only archive dumps can be compressed by Sentinel
+	if compression.Enabled(da.Storage.Compression) {
+		if !da.Archive {
+			return nil, fmt.Errorf("mongodb directory dumps cannot be compressed by Sentinel, use --compress or the archive mode instead")
+		}
+		if da.Compress {
+			return nil, fmt.Errorf("mongodb archive dumps are compressed either by mongodump or by Sentinel, not both")
+		}
+	}
+
+	if da.Parallel < 0 {
+		return nil, fmt.Errorf("invalid number of parallel collections: %d", da.Parallel)
+	}
+
 	// handle output name
-	outName := utils.DefaultValue(da.Storage.OutName, utils.DefaultBackupOutName())
-	da.Storage.OutName = utils.FullPath(backupPath, outName)
+	outName := archiveOutName(da)
+	var output string
+	if da.Archive {
+		// the archive is written to the standard output, then to the storage
+		da.Storage.OutName = utils.FullPath(backupPath, outName)
+		output = "--archive"
+	} else if utils.DefaultValue(da.Storage.StorageType, "local") != "local" {
+		// remote storages upload the dump from the temporary directory
+		da.Storage.OutName = utils.FormatResourceValue(outName)
+		output = fmt.Sprintf("--out=%s", da.Storage.OutName)
+	} else {
+		da.Storage.OutName = utils.FullPath(backupPath, outName)
+		output = fmt.Sprintf("--out=%s", da.Storage.OutName)
+	}
 
 	args := []string{
 		fmt.Sprintf("--uri=%s", da.Uri),
-		fmt.Sprintf("--out=%s", da.Storage.OutName),
+		output,
 		"--quiet",
 	}
+	args = append(args, da.TLS.MongoArgs()...) // add the TLS arguments if provided
+
+	if da.Database != "" {
+		args = append(args, fmt.Sprintf("--db=%s", da.Database))
+	} // dump a single database if provided
+
+	if da.Parallel > 0 {
+		args = append(args, fmt.Sprintf("--numParallelCollections=%d", da.Parallel))
+	}
 
-	// Handle compression
+	if captureOplog(da) {
+		args = append(args, "--oplog")
+	} // capture the writes made during the dump, for a point-in-time consistent dump
+
+	// skip the collections filtered out, mongodump has no pattern support
+	if da.Filters.HasCollections() {
+		excludeArgs, err := excludeCollectionArgs(da)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, excludeArgs...)
+	}
+
+	// Handle compression, mongodump compresses the collections, inside the archive in the archive mode
 	if da.Compress {
 		args = append(args, "--gzip")
 	}
@@ -42,3 +101,42 @@ func argsBuilder(da *DumpMongoArgs, backupPath string) ([]string, error) {
 
 	return args, nil
 }
+
+// archiveOutName returns the backup name, with the extensions of archive dumps in the archive mode.
+// The extensions are only added when the name does not already end with them.
+func archiveOutName(da *DumpMongoArgs) string {
+	outName := utils.DefaultValue(da.Storage.OutName, utils.DefaultBackupOutName())
+	if !da.Archive {
+		return outName
+	}
+
+	return mongo.ArchiveName(outName, da.Compress, da.Storage.Compression)
+}
+
+// captureOplog reports whether the oplog is captured during the dump. mongodump only
+// captures it on replica set members, for dumps of the whole instance.
+func captureOplog(da *DumpMongoArgs) bool {
+	return da.replicaSet != "" && !da.SkipOplog && da.Database == ""
+}
+
+// excludeCollectionArgs translates the collection filters into --excludeCollection arguments,
+// applying them to the collections of the database
+func excludeCollectionArgs(da *DumpMongoArgs) ([]string, error) {
+	if da.Database == "" {
+		return nil, fmt.Errorf("collection filters require a database")
+	}
+
+	selected := da.Filters.SelectCollections(da.collections)
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no collection of %s matches the collection filters", da.Database)
+	}
+
+	var args []string
+	for _, collection := range da.collections {
+		if !slices.Contains(selected, collection) {
+			args = append(args, fmt.Sprintf("--excludeCollection=%s", collection))
+		}
+	}
+
+	return args, nil
+}
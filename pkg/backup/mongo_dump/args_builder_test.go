@@ -1,6 +1,8 @@
 package mongo_dump
 
 import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/storage"
 	"reflect"
 	"testing"
 )
@@ -15,37 +17,121 @@ func Test_argsBuilder(t *testing.T) {
 	}{
 		{
 			name:    "Args with default URI",
-			args:    &DumpMongoArgs{Compress: false, OutName: "test.archive"},
+			args:    &DumpMongoArgs{Compress: false, Storage: &storage.Params{OutName: "test.archive"}},
 			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet"},
 			wantErr: false,
 		},
 		{
 			name: "Args with custom URI",
-			args: &DumpMongoArgs{Uri: "mongodb://username@password:192.168.1.34:27017/?timeoutMS=5000", Compress: false, OutName: "test.archive"},
+			args: &DumpMongoArgs{Uri: "mongodb://username@password:192.168.1.34:27017/?timeoutMS=5000", Compress: false, Storage: &storage.Params{OutName: "test.archive"}},
 			want: []string{"--uri=mongodb://username@password:192.168.1.34:27017/?timeoutMS=5000", "--out=test.archive", "--quiet"},
 		},
 		{
 			name:    "Args with compression enabled",
-			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Compress: true, OutName: "test.archive"},
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Compress: true, Storage: &storage.Params{OutName: "test.archive"}},
 			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--gzip"},
 			wantErr: false,
 		},
 		{
 			name:    "Args with additional arguments",
-			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Compress: false, AdditionalArgs: "--authenticationDatabase=admin", OutName: "test.archive"},
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Compress: false, AdditionalArgs: "--authenticationDatabase=admin", Storage: &storage.Params{OutName: "test.archive"}},
 			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--authenticationDatabase=admin"},
 			wantErr: false,
 		},
+		{
+			name:    "Args with TLS options",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", TLS: backup.TLSOptions{Mode: "verify-ca", CAFile: "/certs/ca.pem", CertFile: "/certs/client.pem"}, Storage: &storage.Params{OutName: "test.archive"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--tls", "--tlsCAFile=/certs/ca.pem", "--tlsCertificateKeyFile=/certs/client.pem", "--tlsAllowInvalidHostnames"},
+			wantErr: false,
+		},
+		{
+			name:    "Args with a single database",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Database: "app", Storage: &storage.Params{OutName: "test.archive"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--db=app"},
+			wantErr: false,
+		},
 		{
 			name:    "Remove duplicate arguments",
-			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Compress: false, AdditionalArgs: "--authenticationDatabase=admin --authenticationDatabase=admin", OutName: "test.archive"},
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Compress: false, AdditionalArgs: "--authenticationDatabase=admin --authenticationDatabase=admin", Storage: &storage.Params{OutName: "test.archive"}},
 			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--authenticationDatabase=admin"},
 			wantErr: false,
 		},
+		{
+			name:    "Args with parallel collections",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Parallel: 8, Compress: true, Storage: &storage.Params{OutName: "test.archive"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--numParallelCollections=8", "--gzip"},
+			wantErr: false,
+		},
+		{
+			name:    "Negative parallel collections - error expected",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Parallel: -1, Storage: &storage.Params{OutName: "test.archive"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Sentinel compression - error expected",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Storage: &storage.Params{OutName: "test.archive", Compression: "gzip"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Args with collection filters",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Database: "app", Filters: backup.Filters{ExcludeCollections: []string{"*_cache"}}, collections: []string{"users", "sessions_cache", "orders"}, Storage: &storage.Params{OutName: "test.archive"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--db=app", "--excludeCollection=sessions_cache"},
+			wantErr: false,
+		},
+		{
+			name:    "Replica set dumped with its oplog",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", replicaSet: "rs0", Storage: &storage.Params{OutName: "test.archive"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--oplog"},
+			wantErr: false,
+		},
+		{
+			name:    "Replica set database dumped without oplog",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Database: "app", replicaSet: "rs0", Storage: &storage.Params{OutName: "test.archive"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet", "--db=app"},
+			wantErr: false,
+		},
+		{
+			name:    "Replica set with oplog skipped",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", SkipOplog: true, replicaSet: "rs0", Storage: &storage.Params{OutName: "test.archive"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--out=test.archive", "--quiet"},
+			wantErr: false,
+		},
+		{
+			name:    "Archive mode",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Archive: true, Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--archive", "--quiet"},
+			wantErr: false,
+		},
+		{
+			name:    "Archive mode compressed by mongodump",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Archive: true, Compress: true, Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--archive", "--quiet", "--gzip"},
+			wantErr: false,
+		},
+		{
+			name:    "Archive mode compressed by Sentinel",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Archive: true, Storage: &storage.Params{OutName: "test", Compression: "zstd"}},
+			want:    []string{"--uri=mongodb://localhost:27017", "--archive", "--quiet"},
+			wantErr: false,
+		},
+		{
+			name:    "Archive mode compressed twice - error expected",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Archive: true, Compress: true, Storage: &storage.Params{OutName: "test", Compression: "zstd"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Collection filters without database - error expected",
+			args:    &DumpMongoArgs{Uri: "mongodb://localhost:27017", Filters: backup.Filters{IncludeCollections: []string{"users"}}, Storage: &storage.Params{OutName: "test.archive"}},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := argsBuilder(tt.args)
+			got, err := argsBuilder(tt.args, "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -56,3 +142,25 @@ func Test_argsBuilder(t *testing.T) {
 		})
 	}
 }
+
+func Test_archiveOutName(t *testing.T) {
+	tests := []struct {
+		name string
+		args *DumpMongoArgs
+		want string
+	}{
+		{name: "Directory dump", args: &DumpMongoArgs{Compress: true, Storage: &storage.Params{OutName: "test"}}, want: "test"},
+		{name: "Archive", args: &DumpMongoArgs{Archive: true, Storage: &storage.Params{OutName: "test"}}, want: "test.archive"},
+		{name: "Archive compressed by mongodump", args: &DumpMongoArgs{Archive: true, Compress: true, Storage: &storage.Params{OutName: "test"}}, want: "test.gz.archive"},
+		{name: "Archive compressed by Sentinel", args: &DumpMongoArgs{Archive: true, Storage: &storage.Params{OutName: "test.archive", Compression: "lz4"}}, want: "test.archive.lz4"},
+		{name: "Extensions not repeated", args: &DumpMongoArgs{Archive: true, Compress: true, Storage: &storage.Params{OutName: "test.gz.archive"}}, want: "test.gz.archive"},
+		{name: "Gzip extension of older names replaced", args: &DumpMongoArgs{Archive: true, Compress: true, Storage: &storage.Params{OutName: "test.archive.gz"}}, want: "test.gz.archive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := archiveOutName(tt.args); got != tt.want {
+				t.Errorf("archiveOutName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,43 @@ package mongo_dump
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
 	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/compression"
 	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"time"
 )
 
 // Backup backs up a MongoDB database using mongo_dump
 func Backup(da *DumpMongoArgs) error {
+	da.Uri = utils.DefaultValue(da.Uri, "mongodb://localhost:27017")
+
+	// check connectivity, replica set members are dumped with their oplog
+	server, err := mongo.CheckConnectivity(da.Uri, &da.TLS)
+	if err != nil {
+		return err
+	}
+	da.replicaSet = server.ReplicaSet
+	if da.replicaSet != "" && !da.SkipOplog && da.Database != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: the oplog is only captured when dumping all the databases, the dump of %s is not point-in-time consistent\n", da.Database)
+	}
+
+	// the collection filters are applied to the collections of the database
+	if da.Filters.HasCollections() && da.Database != "" {
+		collections, err := mongo.ListCollections(da.Uri, &da.TLS, da.Database)
+		if err != nil {
+			return err
+		}
+		da.collections = collections
+	}
+
 	// get the storage handler
 	storageHandler, err := storage.NewStorage(da.Storage)
 	if err != nil {
@@ -22,14 +51,16 @@ func Backup(da *DumpMongoArgs) error {
 		return err
 	}
 
-	args, err := argsBuilder(da, backupPath) // build mongo_dump arguments
+	// avoid collisions with existing backups
+	resource, err := storage.ResolveCollision(storageHandler, utils.FullPath(backupPath, archiveOutName(da)), da.Storage.OnCollision)
 	if err != nil {
-		return fmt.Errorf("failed to build mongo_dump arguments: %w", err)
+		return err
 	}
+	da.Storage.OutName = filepath.Base(resource)
 
-	// check connectivity
-	if err := mongo.CheckConnectivity(da.Uri); err != nil {
-		return err
+	args, err := argsBuilder(da, backupPath) // build mongo_dump arguments
+	if err != nil {
+		return fmt.Errorf("failed to build mongo_dump arguments: %w", err)
 	}
 
 	cmd := exec.Command("mongodump", args...) // run mongo_dump command
@@ -38,22 +69,143 @@ func Backup(da *DumpMongoArgs) error {
 	var stdErr bytes.Buffer
 	cmd.Stderr = &stdErr
 
-	// capture the command output
-	var stdOut bytes.Buffer
-	cmd.Stdout = &stdOut
+	// the oplog captured in an archive cannot be read back, the position recorded is the one of the
+	// last oplog entry written before the dump: replaying the oplog from there is idempotent
+	var oplogStart *backup.OplogPosition
+	if da.Archive && captureOplog(da) {
+		if oplogStart, err = mongo.LastOplogEntry(da.Uri, &da.TLS); err != nil {
+			return err
+		}
+	}
 
-	// run the command
-	err = cmd.Run()
+	metadata := &backup.Metadata{
+		Engine:    "mongodb",
+		Database:  da.Database,
+		Backup:    filepath.Base(da.Storage.OutName),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if da.Archive {
+		// stream the archive into the storage
+		if err := streamArchive(cmd, &stdErr, storageHandler, da); err != nil {
+			return err
+		}
+		metadata.Oplog = oplogStart
+	} else {
+		// run the command, which writes the dump directory
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run mongo_dump: %w, %s", err, stdErr.String())
+		}
+
+		// read the oplog position the dump is consistent with, before remote storages clean the dump up
+		if captureOplog(da) {
+			if metadata.Oplog, err = readOplogPosition(da.Storage.OutName); err != nil {
+				return err
+			}
+		}
+
+		// write backup to storage
+		if err := storageHandler.WriteBackup(nil, da.Storage.OutName); err != nil {
+			return fmt.Errorf("failed to write backup to storage: %w", err)
+		}
+	}
+
+	// write the metadata next to the backup, with the oplog position it is consistent with
+	data, err := metadata.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := storageHandler.WriteBackup(data, da.Storage.OutName+backup.MetadataSuffix); err != nil {
+		return fmt.Errorf("failed to write backup metadata to storage: %w", err)
+	}
+
+	fmt.Printf("Backup complete !\n")
+
+	return nil
+}
+
+// streamArchive runs mongodump and streams its archive into the storage, compressed on the fly,
+// without holding it in memory. The upload is aborted, nothing being stored, if mongodump exits
+// with an error once its output is copied.
+func streamArchive(cmd *exec.Cmd, stdErr *bytes.Buffer, storageHandler storage.Storage, da *DumpMongoArgs) error {
+	reader, writer := io.Pipe()
+
+	stored := make(chan error, 1)
+	go func() {
+		err := storageHandler.WriteBackupStream(reader, da.Storage.OutName)
+		_ = reader.CloseWithError(err) // stops the copy when the storage gives up
+		stored <- err
+	}()
+
+	err := copyArchive(cmd, stdErr, writer, da)
+	_ = writer.CloseWithError(err) // the storage reads EOF on success, the error otherwise
+
+	// a copy failing because the storage gave up reports the storage error
+	if storeErr := <-stored; storeErr != nil && (err == nil || errors.Is(err, storeErr)) {
+		return fmt.Errorf("failed to write backup to storage: %w", storeErr)
+	}
+
+	return err
+}
+
+// copyArchive runs mongodump and copies its archive, compressed, to the writer.
+//
+// Returns an error if the archive cannot be copied or mongodump exits with an error.
+func copyArchive(cmd *exec.Cmd, stdErr *bytes.Buffer, w io.Writer, da *DumpMongoArgs) error {
+	out, err := compression.NewWriter(w, da.Storage.Compression, da.Storage.CompressionLevel)
+	if err != nil {
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to run mongo_dump: %w", err)
 	}
 
-	// write backup to storage
-	if err := storageHandler.WriteBackup(stdOut.Bytes(), da.Storage.OutName); err != nil {
-		return fmt.Errorf("failed to write backup to storage: %w", err)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run mongo_dump: %w", err)
 	}
 
-	fmt.Printf("Backup complete !\n")
+	_, copyErr := io.Copy(out, stdout)
+	if copyErr != nil {
+		_ = cmd.Process.Kill() // mongodump would block on its output
+	}
+
+	// the exit status tells whether the archive copied is complete
+	waitErr := cmd.Wait()
+	if copyErr != nil {
+		return fmt.Errorf("failed to stream the archive: %w", copyErr)
+	}
+	if waitErr != nil {
+		return fmt.Errorf("failed to run mongo_dump: %w, %s", waitErr, stdErr.String())
+	}
+
+	// flush the compressed stream
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup: %w", err)
+	}
 
 	return nil
 }
+
+// readOplogPosition reads the position of the last oplog entry captured by mongodump --oplog,
+// from the oplog.bson file of the dump directory, compressed when the dump is
+func readOplogPosition(dir string) (*backup.OplogPosition, error) {
+	name := filepath.Join(dir, "oplog.bson")
+	if !utils.PathExists(name) {
+		name += compression.Extension("gzip")
+	}
+
+	reader, err := compression.OpenFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the oplog of the dump: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the oplog of the dump: %w", err)
+	}
+
+	return mongo.LastOplogPosition(data)
+}
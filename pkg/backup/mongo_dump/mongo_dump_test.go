@@ -0,0 +1,61 @@
+package mongo_dump
+
+import (
+	"bytes"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/storage"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func Test_streamArchive_gzip(t *testing.T) {
+	dir := t.TempDir()
+	da := &DumpMongoArgs{Archive: true, Compress: true, Storage: &storage.Params{OutName: "test", LocalPath: dir}}
+
+	args, err := argsBuilder(da, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(args, "--gzip") {
+		t.Errorf("argsBuilder() got = %v, want --gzip", args)
+	}
+
+	storageHandler, err := storage.NewStorage(da.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a mongodump stand-in writing an archive, its collections being gzipped inside it
+	archive := "\x6d\xe2\x99\x81prelude\x1f\x8bcollection"
+	cmd := exec.Command("sh", "-c", `printf '\155\342\231\201prelude\037\213collection'`)
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+	if err := streamArchive(cmd, &stdErr, storageHandler, da); err != nil {
+		t.Fatalf("streamArchive() error = %v", err)
+	}
+
+	// the archive is no gzip stream, it is restored as is with mongorestore --gzip
+	file := filepath.Join(dir, "test"+mongo.GzipArchiveExtension)
+	if da.Storage.OutName != file {
+		t.Errorf("streamArchive() stored %v, want %v", da.Storage.OutName, file)
+	}
+	if !mongo.IsGzipArchive(file) {
+		t.Errorf("IsGzipArchive() got = false, want true")
+	}
+
+	reader, err := mongo.OpenArchive(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != archive {
+		t.Errorf("OpenArchive() got = %q, want %q", data, archive)
+	}
+}
@@ -8,13 +8,20 @@ import (
 )
 
 type MySqlDumpArgs struct {
-	Host           string          // MySQL host
-	Port           string          // MySQL port
-	Username       string          // MySQL username
-	Password       string          // MySQL password
-	Database       string          // MySQL database name
-	AdditionalArgs string          // Additional arguments for the mysql_dump command
-	Storage        *storage.Params // Storage parameters
+	Host           string                  // MySQL host
+	Port           string                  // MySQL port
+	Username       string                  // MySQL username
+	Password       string                  // MySQL password
+	Database       string                  // MySQL database name
+	TLS            backup.TLSOptions       // TLS options
+	Filters        backup.Filters          // Table filters
+	AdditionalArgs string                  // Additional arguments for the mysql_dump command
+	SkipSnapshot   bool                    // skip the consistent snapshot profile, keeping the dump tool defaults
+	SkipBinlog     bool                    // skip the binary log coordinates of the consistent snapshot
+	tables         []string                // tables of the database, listed when table filters are set
+	server         *backup.MySQLServer     // server inspected before the dump, for the binlog coordinates
+	client         *backup.MySQLDumpClient // dump tool inspected before the dump, for the binlog coordinates options
+	Storage        *storage.Params         // Storage parameters
 }
 
 // argsBuilder builds the arguments for the mysql_dump command
@@ -26,15 +33,19 @@ func argsBuilder(mda *MySqlDumpArgs) ([]string, error) {
 	mda.Host = utils.DefaultValue(mda.Host, "127.0.0.1") // set the default host to 127.0.0.1 if not provided
 	mda.Port = utils.DefaultValue(mda.Port, "3306")      // set the default port to 3306 if not provided
 
-	args := []string{
-		fmt.Sprintf("--host=%s", mda.Host),
-		fmt.Sprintf("--port=%s", mda.Port),
-		fmt.Sprintf("--user=%s", mda.Username),
-	}
+	args := connectionArgs(mda)
 
-	if mda.Password == "" {
-		args = append(args, "--skip-password")
-	} // skip password prompt if password is not provided
+	if !mda.SkipSnapshot {
+		args = append(args, backup.MySQLSnapshotArgs(mda.server, mda.client, !mda.SkipBinlog)...)
+	} // dump a consistent snapshot with the routines, triggers and events
+
+	if mda.Filters.HasTables() {
+		tableArgs, _, err := mda.Filters.MySQLTableArgs(mda.Database, mda.tables)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, tableArgs...)
+	} // skip the tables filtered out
 
 	if mda.AdditionalArgs != "" {
 		additionalArgs := backup.ParseAdditionalArgs(mda.AdditionalArgs)
@@ -46,3 +57,34 @@ func argsBuilder(mda *MySqlDumpArgs) ([]string, error) {
 
 	return args, nil
 }
+
+// noDataArgsBuilder builds the arguments of the mysql_dump command dumping the schema
+// of the tables backed up without their data
+func noDataArgsBuilder(mda *MySqlDumpArgs, tables []string) []string {
+	args := connectionArgs(mda)
+
+	if mda.AdditionalArgs != "" {
+		additionalArgs := backup.ParseAdditionalArgs(mda.AdditionalArgs)
+		args = append(args, additionalArgs...)
+	} // handle additional arguments
+
+	args = append(args, "--no-data")
+	args = backup.RemoveArgsDuplicate(args) // remove duplicate arguments
+	args = append(args, mda.Database)       // add database name
+	args = append(args, tables...)          // add the table names
+
+	return args
+}
+
+// connectionArgs builds the connection arguments of the mysql_dump command
+func connectionArgs(mda *MySqlDumpArgs) []string {
+	args := backup.MySQLHostArgs(mda.Host, mda.Port) // connect through the host and port, or the unix socket
+	args = append(args, fmt.Sprintf("--user=%s", mda.Username))
+	args = append(args, mda.TLS.MySQLArgs()...) // add the TLS arguments if provided
+
+	if mda.Password == "" {
+		args = append(args, "--skip-password")
+	} // skip password prompt if password is not provided
+
+	return args
+}
@@ -1,6 +1,7 @@
 package mysql_dump
 
 import (
+	"github.com/denisakp/sentinel/internal/backup"
 	"reflect"
 	"testing"
 )
@@ -33,31 +34,79 @@ func TestArgsBuilder(t *testing.T) {
 		{
 			name:    "Default Host and Port",
 			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "testdb"},
-			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "testdb"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "testdb"},
 			wantErr: false,
 		},
 		{
 			name:    "Provided host and port",
 			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "test", Host: "us-west1.mysql.domain.com", Port: "3319"},
-			want:    []string{"--host=us-west1.mysql.domain.com", "--port=3319", "--user=root", "test"},
+			want:    []string{"--host=us-west1.mysql.domain.com", "--port=3319", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
 			wantErr: false,
 		},
 		{
 			name:    "Skip password",
 			args:    &MySqlDumpArgs{Username: "root", Database: "test"},
-			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--skip-password", "test"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--skip-password", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
 			wantErr: false,
 		},
 		{
 			name:    "Additional Arguments",
 			args:    &MySqlDumpArgs{Username: "root", Database: "test", AdditionalArgs: "--flush-privileges"},
-			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--skip-password", "--flush-privileges", "test"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--skip-password", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "--flush-privileges", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "TLS options",
+			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "test", TLS: backup.TLSOptions{Mode: "verify-full", CAFile: "/certs/ca.pem", CertFile: "/certs/client.pem", KeyFile: "/certs/client.key"}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--ssl-mode=VERIFY_IDENTITY", "--ssl-ca=/certs/ca.pem", "--ssl-cert=/certs/client.pem", "--ssl-key=/certs/client.key", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Unix socket",
+			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "test", Host: "/var/run/mysqld/mysqld.sock"},
+			want:    []string{"--socket=/var/run/mysqld/mysqld.sock", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
 			wantErr: false,
 		},
 		{
 			name:    "Remove duplicate",
 			args:    &MySqlDumpArgs{Username: "root", Database: "test", AdditionalArgs: "--port=3306"},
-			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--skip-password", "test"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--skip-password", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Table filters",
+			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "test", Filters: backup.Filters{ExcludeTables: []string{"tmp_*"}, ExcludeTableData: []string{"*_logs"}}, tables: []string{"users", "audit_logs", "tmp_import"}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "--ignore-table=test.audit_logs", "--ignore-table=test.tmp_import", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Table filters matching nothing - error expected",
+			args:    &MySqlDumpArgs{Username: "root", Database: "test", Filters: backup.Filters{IncludeTables: []string{"missing"}}, tables: []string{"users"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Snapshot profile skipped",
+			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "test", SkipSnapshot: true},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Snapshot profile without the binlog coordinates",
+			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "test", SkipBinlog: true, server: &backup.MySQLServer{Version: "8.0.36", MariaDB: false, LogBin: true, BinlogPrivileges: true}, client: &backup.MySQLDumpClient{Version: "8.0.36"}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Snapshot profile with the binlog coordinates",
+			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "test", server: &backup.MySQLServer{Version: "8.0.36", MariaDB: false, LogBin: true, BinlogPrivileges: true}, client: &backup.MySQLDumpClient{Version: "8.0.36"}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "--source-data=2", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Snapshot profile of the mysqldump of MariaDB",
+			args:    &MySqlDumpArgs{Username: "root", Password: "root", Database: "test", server: &backup.MySQLServer{Version: "8.0.36", MariaDB: false, LogBin: true, BinlogPrivileges: true}, client: &backup.MySQLDumpClient{Version: "10.11.6", MariaDB: true}},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--routines", "--triggers", "--events", "--hex-blob", "--master-data=2", "test"},
 			wantErr: false,
 		},
 	}
@@ -74,3 +123,12 @@ func TestArgsBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestNoDataArgsBuilder(t *testing.T) {
+	mda := &MySqlDumpArgs{Host: "127.0.0.1", Port: "3306", Username: "root", Password: "root", Database: "test", AdditionalArgs: "--single-transaction"}
+
+	want := []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--single-transaction", "--no-data", "test", "audit_logs", "event_logs"}
+	if got := noDataArgsBuilder(mda, []string{"audit_logs", "event_logs"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("noDataArgsBuilder() got = %v, want %v", got, want)
+	}
+}
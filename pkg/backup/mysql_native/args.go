@@ -0,0 +1,26 @@
+package mysql_native
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+)
+
+type MySqlNativeArgs struct {
+	Type     string            // database type, mysql or mariadb
+	Host     string            // MySQL or MariaDB host
+	Port     string            // MySQL or MariaDB port
+	Username string            // MySQL or MariaDB username
+	Password string            // MySQL or MariaDB password
+	Database string            // MySQL or MariaDB database name
+	TLS      backup.TLSOptions // TLS options
+	Filters  backup.Filters    // Table filters
+	Storage  *storage.Params   // Storage parameters
+}
+
+// outName returns the name of the backup, with the .sql extension by default
+// and the extension of the compression algorithm
+func outName(mna *MySqlNativeArgs) string {
+	return compression.AppendExtension(utils.FinalOutName(mna.Storage.OutName), mna.Storage.Compression)
+}
@@ -0,0 +1,111 @@
+package mysql_native
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backup backs up a MySQL or MariaDB database without the dump tools, reading it through the
+// driver within a consistent snapshot transaction and writing a SQL script restored with the
+// mysql client
+func Backup(mna *MySqlNativeArgs) error {
+	if err := validateRequiredArgs(mna); err != nil {
+		return fmt.Errorf("failed to build native dump args - %w", err)
+	}
+
+	conn := &sql.Connection{
+		Host:     utils.DefaultValue(mna.Host, "127.0.0.1"),
+		Port:     utils.DefaultValue(mna.Port, "3306"),
+		User:     mna.Username,
+		Password: mna.Password,
+		Database: mna.Database,
+		TLS:      &mna.TLS,
+	}
+
+	// inspect the server, which checks the connectivity too
+	server, err := sql.InspectMySQL("mysql", conn)
+	if err != nil {
+		return err
+	}
+
+	if len(server.NonTransactional) > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: the dump of tables using a non-transactional engine is not consistent: %s\n", strings.Join(server.NonTransactional, ", "))
+	}
+
+	// get storage handler
+	storageHandler, err := storage.NewStorage(mna.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get backup path
+	backupPath, err := storageHandler.GetBackupPath(mna.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	// set outName with customizable extension (default is .sql)
+	mna.Storage.OutName = outName(mna)
+
+	// avoid collisions with existing backups
+	fullPath, err := storage.ResolveCollision(storageHandler, utils.FullPath(backupPath, mna.Storage.OutName), mna.Storage.OnCollision)
+	if err != nil {
+		return err
+	}
+
+	// dump the database, compressed on the fly
+	var dump bytes.Buffer
+	out, err := compression.NewWriter(&dump, mna.Storage.Compression, mna.Storage.CompressionLevel)
+	if err != nil {
+		return err
+	}
+
+	position, err := sql.DumpMySQL("mysql", conn, &mna.Filters, out)
+	if err != nil {
+		return fmt.Errorf("failed to dump database - %w", err)
+	}
+
+	// only MariaDB exposes the binlog position of the snapshot without locking the tables
+	if position == nil && server.LogBin {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: the binary log position of the snapshot is not exposed by %s, the dump cannot be used for a point-in-time restore\n", server.Version)
+	}
+
+	// flush the compressed stream
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup - %w", err)
+	}
+
+	// write backup to storage
+	if err := storageHandler.WriteBackup(dump.Bytes(), fullPath); err != nil {
+		return fmt.Errorf("failed to write backup to storage - %w", err)
+	}
+
+	// write the metadata next to the backup, with the binlog position it is consistent with
+	metadata := &backup.Metadata{
+		Engine:    mna.Type,
+		Database:  mna.Database,
+		Backup:    filepath.Base(fullPath),
+		CreatedAt: time.Now().UTC(),
+		Binlog:    position,
+	}
+	data, err := metadata.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := storageHandler.WriteBackup(data, fullPath+backup.MetadataSuffix); err != nil {
+		return fmt.Errorf("failed to write backup metadata to storage - %w", err)
+	}
+
+	fmt.Printf("Backup complete !\n")
+
+	return nil
+}
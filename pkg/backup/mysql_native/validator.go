@@ -0,0 +1,20 @@
+package mysql_native
+
+import "fmt"
+
+// validateRequiredArgs validates required arguments for the native MySQL and MariaDB dump
+func validateRequiredArgs(mna *MySqlNativeArgs) error {
+	if mna.Type != "mysql" && mna.Type != "mariadb" {
+		return fmt.Errorf("the native dump is only supported with mysql and mariadb")
+	}
+
+	if mna.Database == "" {
+		return fmt.Errorf("database name is missing")
+	}
+
+	if mna.Username == "" {
+		return fmt.Errorf("username is missing")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package mysql_native
+
+import "testing"
+
+func Test_validateRequiredArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *MySqlNativeArgs
+		wantErr bool
+	}{
+		{
+			name:    "Missing required args",
+			args:    &MySqlNativeArgs{},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported database type",
+			args:    &MySqlNativeArgs{Type: "postgres", Username: "root", Database: "test"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing database name",
+			args:    &MySqlNativeArgs{Type: "mysql", Username: "root"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing username",
+			args:    &MySqlNativeArgs{Type: "mariadb", Database: "test"},
+			wantErr: true,
+		},
+		{
+			name:    "Valid args",
+			args:    &MySqlNativeArgs{Type: "mysql", Username: "root", Database: "test"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRequiredArgs(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("validateRequiredArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
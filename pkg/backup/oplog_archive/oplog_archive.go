@@ -0,0 +1,168 @@
+package oplog_archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type OplogArchiveArgs struct {
+	Uri      string                // MongoDB URI of the replica set
+	TLS      backup.TLSOptions     // TLS options
+	Start    *backup.OplogPosition // position to archive the oplog from, e.g. the one of the last dump, the last entry when nil
+	Interval time.Duration         // how often the oplog entries are stored as a slice
+	Storage  *storage.Params       // Storage parameters
+}
+
+// Archive tails the oplog of a MongoDB replica set between full dumps, storing the entries
+// as slices replayable with mongorestore --oplogReplay, until Sentinel is interrupted
+func Archive(oaa *OplogArchiveArgs) error {
+	if oaa.Interval <= 0 {
+		oaa.Interval = time.Minute
+	}
+	oaa.Uri = utils.DefaultValue(oaa.Uri, "mongodb://localhost:27017")
+
+	// validate the compression of the slices
+	if err := compression.ValidateLevel(oaa.Storage.Compression, oaa.Storage.CompressionLevel); err != nil {
+		return err
+	}
+
+	// the layout is rendered once when the archiving starts, it would hold the date of the start
+	if storage.IsDatedLayout(oaa.Storage.Layout) {
+		return fmt.Errorf("the oplog cannot be archived in a layout depending on the date: %s", oaa.Storage.Layout)
+	}
+
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(oaa.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(oaa.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	oplog, err := mongo.TailOplog(oaa.Uri, &oaa.TLS, oaa.Start)
+	if err != nil {
+		return err
+	}
+	defer oplog.Close()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	fmt.Printf("Archiving the oplog, press Ctrl+C to stop\n")
+
+	s := &slicer{storage: storageHandler, backupPath: backupPath, algorithm: oaa.Storage.Compression, level: oaa.Storage.CompressionLevel}
+	flushed := time.Now()
+	for {
+		select {
+		case <-stop:
+			// store what was tailed until the interruption
+			if err := s.flush(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Oplog archiving stopped !\n")
+
+			return nil
+		default:
+		}
+
+		entry, err := oplog.Next(context.TODO())
+		if err != nil {
+			// store what was tailed until the cursor died
+			if flushErr := s.flush(); flushErr != nil {
+				return flushErr
+			}
+			return err
+		}
+
+		if entry != nil {
+			if err := s.add(entry); err != nil {
+				return err
+			}
+		}
+
+		if time.Since(flushed) >= oaa.Interval {
+			if err := s.flush(); err != nil {
+				return err
+			}
+			flushed = time.Now()
+		}
+	}
+}
+
+// slicer stores the oplog entries added to it as slices, written to the storage when flushed
+type slicer struct {
+	storage    storage.Storage
+	backupPath string
+	algorithm  string               // compression algorithm of the slices
+	level      int                  // compression level of the slices
+	entries    bytes.Buffer         // BSON documents of the entries, one after the other as in oplog.bson
+	first      backup.OplogPosition // position of the first entry of the slice
+	last       backup.OplogPosition // position of the last entry of the slice
+}
+
+// add appends the entry to the current slice
+func (s *slicer) add(entry bson.Raw) error {
+	position, err := mongo.EntryPosition(entry)
+	if err != nil {
+		return err
+	}
+
+	if s.entries.Len() == 0 {
+		s.first = position
+	}
+	s.last = position
+	s.entries.Write(entry)
+
+	return nil
+}
+
+// flush writes the current slice to the storage, named after the positions of its first and last entries
+func (s *slicer) flush() error {
+	if s.entries.Len() == 0 {
+		return nil
+	}
+
+	var compressed bytes.Buffer
+	out, err := compression.NewWriter(&compressed, s.algorithm, s.level)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(s.entries.Bytes()); err != nil {
+		return fmt.Errorf("failed to compress oplog slice - %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress oplog slice - %w", err)
+	}
+
+	name := compression.AppendExtension(sliceName(s.first, s.last), s.algorithm)
+	if err := s.storage.WriteBackup(compressed.Bytes(), utils.FullPath(s.backupPath, name)); err != nil {
+		return fmt.Errorf("failed to write oplog slice %s to storage - %w", name, err)
+	}
+
+	s.entries.Reset()
+
+	return nil
+}
+
+// sliceName names a slice after the positions of its first and last entries, zero-padded so
+// that the names sort in the order of the oplog
+func sliceName(first, last backup.OplogPosition) string {
+	return fmt.Sprintf("%s%010d-%010d_%010d-%010d.bson", backup.OplogSlicePrefix, first.T, first.I, last.T, last.I)
+}
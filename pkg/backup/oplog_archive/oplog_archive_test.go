@@ -0,0 +1,86 @@
+package oplog_archive
+
+import (
+	"bytes"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/storage/storagetest"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSlicer(t *testing.T) {
+	store := storagetest.NewMemory()
+	s := &slicer{storage: store, algorithm: "gzip"}
+
+	// nothing tailed yet
+	if err := s.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+	if len(store.Backups) != 0 {
+		t.Fatalf("flush() wrote %d slices, want 0", len(store.Backups))
+	}
+
+	for _, ts := range []bson.Timestamp{{T: 1714521600, I: 1}, {T: 1714521600, I: 2}, {T: 1714521660, I: 1}} {
+		entry, err := bson.Marshal(bson.D{{Key: "ts", Value: ts}, {Key: "op", Value: "i"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.add(entry); err != nil {
+			t.Fatalf("add() error = %v", err)
+		}
+	}
+
+	if err := s.add(bson.Raw{5, 0, 0, 0, 0}); err == nil {
+		t.Errorf("add() of an entry without timestamp, error expected")
+	}
+
+	if err := s.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	name := "oplog_1714521600-0000000001_1714521660-0000000001.bson.gz"
+	if names := keys(store.Backups); !reflect.DeepEqual(names, []string{name}) {
+		t.Fatalf("flush() wrote %v, want %v", names, []string{name})
+	}
+
+	reader, err := compression.NewReader(bytes.NewReader(store.Backups[name]), "gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := mongo.LastOplogPosition(data)
+	if err != nil {
+		t.Fatalf("LastOplogPosition() error = %v", err)
+	}
+	if last.T != 1714521660 || last.I != 1 {
+		t.Errorf("slice ends at %d:%d, want 1714521660:1", last.T, last.I)
+	}
+
+	// the slice is reset once written
+	if err := s.flush(); err != nil || len(store.Backups) != 1 {
+		t.Errorf("flush() of an empty slice error = %v, slices = %d", err, len(store.Backups))
+	}
+}
+
+func keys(m map[string][]byte) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestArchive_datedLayout(t *testing.T) {
+	err := Archive(&OplogArchiveArgs{Storage: &storage.Params{StorageType: "local", Layout: "oplog/{yyyy}/{mm}/{dd}"}})
+	if err == nil {
+		t.Errorf("Archive() error = %v, wantErr true", err)
+	}
+}
@@ -0,0 +1,78 @@
+package pg_basebackup
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+)
+
+type PgBaseBackupArgs struct {
+	Host           string            // PostgresSQL host
+	Port           string            // PostgresSQL port
+	Username       string            // PostgresSQL user, with the REPLICATION attribute
+	Password       string            // PostgresSQL password
+	TLS            backup.TLSOptions // TLS options
+	AdditionalArgs string            // Additional arguments for the pg_basebackup command
+	Storage        *storage.Params   // Storage parameters
+}
+
+// argsBuilder builds the arguments of the pg_basebackup command. The cluster is copied as tar
+// archives into the backup directory, with the WAL written during the copy streamed next to
+// them so that the backup can be started on its own, and replayed further with wal-fetch.
+func argsBuilder(pba *PgBaseBackupArgs, backupPath string) ([]string, error) {
+	if err := validateRequiredArgs(pba); err != nil {
+		return nil, err
+	}
+
+	initializeDefaultArgs(pba)
+
+	// pg_basebackup writes the directory itself, remote storages upload it afterward
+	if pba.Storage.StorageType != "local" {
+		pba.Storage.OutName = utils.FormatResourceValue(pba.Storage.OutName)
+	} else {
+		pba.Storage.OutName = utils.FullPath(backupPath, pba.Storage.OutName)
+	}
+
+	args := []string{
+		fmt.Sprintf("--host=%s", pba.TLS.PgHost(pba.Host)),
+		fmt.Sprintf("--port=%s", pba.Port),
+		fmt.Sprintf("--username=%s", pba.Username),
+		fmt.Sprintf("--pgdata=%s", pba.Storage.OutName),
+		"--format=tar",
+		"--wal-method=stream",
+		"--checkpoint=fast",
+		"--label=sentinel",
+	}
+
+	// pg_basebackup takes the TLS options through a connection string, the database name being ignored
+	if pba.TLS.Enabled() {
+		args = append(args, fmt.Sprintf("--dbname=%s", pba.TLS.PgConnString("postgres", pba.Host)))
+	}
+
+	// the tar archives are compressed by pg_basebackup, Sentinel cannot compress a directory
+	if compression.Enabled(pba.Storage.Compression) {
+		compress := pba.Storage.Compression
+		if pba.Storage.CompressionLevel > 0 {
+			compress = fmt.Sprintf("%s:%d", compress, pba.Storage.CompressionLevel)
+		}
+		args = append(args, fmt.Sprintf("--compress=%s", compress))
+	}
+
+	// handle additional arguments
+	if pba.AdditionalArgs != "" {
+		args = append(args, backup.ParseAdditionalArgs(pba.AdditionalArgs)...)
+	}
+
+	// remove duplicated arguments
+	args = backup.RemoveArgsDuplicate(args)
+
+	return args, nil
+}
+
+func initializeDefaultArgs(pba *PgBaseBackupArgs) {
+	pba.Host = utils.DefaultValue(pba.Host, "127.0.0.1")
+	pba.Port = utils.DefaultValue(pba.Port, "5432")
+	pba.Storage.OutName = utils.DefaultValue(pba.Storage.OutName, utils.DefaultBackupOutName())
+}
@@ -0,0 +1,65 @@
+package pg_basebackup
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/storage"
+	"reflect"
+	"testing"
+)
+
+func TestArgsBuilder(t *testing.T) {
+	base := []string{"--format=tar", "--wal-method=stream", "--checkpoint=fast", "--label=sentinel"}
+
+	tests := []struct {
+		name    string
+		args    *PgBaseBackupArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Username missing - error expected",
+			args:    &PgBaseBackupArgs{Storage: &storage.Params{StorageType: "local", OutName: "base"}},
+			wantErr: true,
+		},
+		{
+			name: "Default Host and Port",
+			args: &PgBaseBackupArgs{Username: "replicator", Storage: &storage.Params{StorageType: "local", OutName: "base"}},
+			want: append([]string{"--host=127.0.0.1", "--port=5432", "--username=replicator", "--pgdata=/backups/base"}, base...),
+		},
+		{
+			name: "TLS options are passed in the connection string",
+			args: &PgBaseBackupArgs{Host: "db", Username: "replicator", TLS: backup.TLSOptions{Mode: "require"}, Storage: &storage.Params{StorageType: "local", OutName: "base"}},
+			want: append(append([]string{"--host=db", "--port=5432", "--username=replicator", "--pgdata=/backups/base"}, base...),
+				"--dbname=dbname='postgres' sslmode='require'"),
+		},
+		{
+			name: "Compression",
+			args: &PgBaseBackupArgs{Username: "replicator", Storage: &storage.Params{StorageType: "local", OutName: "base", Compression: "zstd", CompressionLevel: 3}},
+			want: append(append([]string{"--host=127.0.0.1", "--port=5432", "--username=replicator", "--pgdata=/backups/base"}, base...),
+				"--compress=zstd:3"),
+		},
+		{
+			name:    "Invalid compression level - error expected",
+			args:    &PgBaseBackupArgs{Username: "replicator", Storage: &storage.Params{StorageType: "local", OutName: "base", Compression: "gzip", CompressionLevel: 12}},
+			wantErr: true,
+		},
+		{
+			name: "Additional Arguments",
+			args: &PgBaseBackupArgs{Username: "replicator", AdditionalArgs: "--max-rate=32M --checkpoint=fast", Storage: &storage.Params{StorageType: "local", OutName: "base"}},
+			want: append(append([]string{"--host=127.0.0.1", "--port=5432", "--username=replicator", "--pgdata=/backups/base"}, base...),
+				"--max-rate=32M"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := argsBuilder(tt.args, "/backups")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("argsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
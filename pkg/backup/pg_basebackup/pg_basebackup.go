@@ -0,0 +1,94 @@
+package pg_basebackup
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Backup takes a physical backup of a PostgresSQL cluster using pg_basebackup.
+// Unlike pg_dump backups, it can be rolled forward to a point in time with the
+// WAL archived by wal-push.
+func Backup(pba *PgBaseBackupArgs) error {
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(pba.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(pba.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	// set the backup name, avoiding collisions with existing backups
+	pba.Storage.OutName = utils.DefaultValue(pba.Storage.OutName, utils.DefaultBackupOutName())
+	resource, err := storage.ResolveCollision(storageHandler, utils.FullPath(backupPath, pba.Storage.OutName), pba.Storage.OnCollision)
+	if err != nil {
+		return err
+	}
+	pba.Storage.OutName = filepath.Base(resource)
+
+	// build pg_basebackup arguments
+	args, err := argsBuilder(pba, backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to build pg_basebackup args - %w", err)
+	}
+
+	// pg_basebackup refuses to write into a directory which is not empty
+	if utils.IsDirectory(pba.Storage.OutName) {
+		if err := os.RemoveAll(pba.Storage.OutName); err != nil {
+			return fmt.Errorf("failed to remove existing backup directory - %w", err)
+		}
+	}
+
+	// check connectivity
+	if ok, err := sql.CheckConnectivity("postgres", &sql.Connection{
+		Host:     pba.Host,
+		Port:     pba.Port,
+		User:     pba.Username,
+		Password: pba.Password,
+		Database: "postgres",
+		TLS:      &pba.TLS,
+	}); !ok {
+		return err
+	}
+
+	// run pg_basebackup command
+	cmd := exec.Command("pg_basebackup", args...)
+
+	// capture the command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	// pass the password through a temporary password file, keeping the inherited environment
+	cmd.Env = credentials.Environ()
+	if pba.Password != "" {
+		passFile, cleanup, err := credentials.PgPassFile(pba.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		cmd.Env = credentials.Environ("PGPASSFILE=" + passFile)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute pg_basebackup command - %w, %s", err, stdErr.String())
+	}
+
+	// write the backup directory to the storage
+	if err := storageHandler.WriteBackup(nil, pba.Storage.OutName); err != nil {
+		return fmt.Errorf("failed to write backup to storage - %w", err)
+	}
+
+	fmt.Printf("Backup complete !\n")
+
+	return nil
+}
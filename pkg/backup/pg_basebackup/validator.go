@@ -0,0 +1,19 @@
+package pg_basebackup
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/compression"
+)
+
+func validateRequiredArgs(pba *PgBaseBackupArgs) error {
+	if pba.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+
+	// pg_basebackup supports the same algorithms as Sentinel
+	if err := compression.ValidateAlgorithm(pba.Storage.Compression); err != nil {
+		return err
+	}
+
+	return compression.ValidateLevel(pba.Storage.Compression, pba.Storage.CompressionLevel)
+}
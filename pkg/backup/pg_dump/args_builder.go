@@ -8,17 +8,21 @@ import (
 )
 
 type PgDumpArgs struct {
-	Host                 string          // PostgresSQL host
-	Port                 string          // PostgresSQL port
-	Username             string          // PostgresSQL username
-	Password             string          // PostgresSQL password
-	Database             string          // PostgresSQL database name
-	PgOutFormat          string          // Output format for the backup file
-	Compress             bool            // Enable compression
-	CompressionAlgorithm string          // Compression algorithm
-	CompressionLevel     int             // Compression level
-	AdditionalArgs       string          // Additional arguments for the pg_dump command
-	Storage              *storage.Params // Storage parameters
+	Host                 string            // PostgresSQL host
+	Port                 string            // PostgresSQL port
+	Username             string            // PostgresSQL username
+	Password             string            // PostgresSQL password
+	Database             string            // PostgresSQL database name
+	TLS                  backup.TLSOptions // TLS options
+	PgOutFormat          string            // Output format for the backup file
+	Compress             bool              // Enable compression
+	CompressionAlgorithm string            // Compression algorithm
+	CompressionLevel     int               // Compression level
+	Jobs                 int               // Number of tables dumped in parallel, directory format only
+	Filters              backup.Filters    // Table and schema filters
+	AdditionalArgs       string            // Additional arguments for the pg_dump command
+	CrdbBackupURI        string            // CockroachDB backup collection the nodes write to, e.g. s3://bucket/crdb?AUTH=implicit
+	Storage              *storage.Params   // Storage parameters
 }
 
 // argsBuilder builds the arguments for the pg_dump command
@@ -45,14 +49,15 @@ func argsBuilder(pda *PgDumpArgs, backupPath string) ([]string, error) {
 	}
 
 	args := []string{
-		fmt.Sprintf("--host=%s", pda.Host),
+		fmt.Sprintf("--host=%s", pda.TLS.PgHost(pda.Host)),
 		fmt.Sprintf("--port=%s", pda.Port),
 		fmt.Sprintf("--username=%s", pda.Username),
-		fmt.Sprintf("--dbname=%s", pda.Database),
+		fmt.Sprintf("--dbname=%s", pda.TLS.PgConnString(pda.Database, pda.Host)),
 		fmt.Sprintf("--format=%s", pda.PgOutFormat),
 	}
 
-	if pda.Compress {
+	// plain and tar dumps are compressed by Sentinel, see setOutName
+	if pda.Compress && (pda.PgOutFormat == "c" || pda.PgOutFormat == "d") {
 		if err := addCompression(&args, pda); err != nil {
 			return nil, err
 		}
@@ -67,12 +72,24 @@ func argsBuilder(pda *PgDumpArgs, backupPath string) ([]string, error) {
 		args = append(args, fmt.Sprintf("--file=%s", pda.Storage.OutName))
 	}
 
+	args = append(args, pda.Filters.PgArgs()...) // add the table and schema filters if provided
+
 	// handle additional arguments
+	var additionalArgs []string
 	if pda.AdditionalArgs != "" {
-		additionalArgs := backup.ParseAdditionalArgs(pda.AdditionalArgs)
-		args = append(args, additionalArgs...)
+		additionalArgs = backup.ParseAdditionalArgs(pda.AdditionalArgs)
+	}
+
+	// validate the parallel jobs, including the ones passed as additional arguments
+	if err := validatePgJobs(pda.PgOutFormat, pda.Jobs, additionalArgs); err != nil {
+		return nil, err
+	}
+	if pda.Jobs > 1 {
+		args = append(args, fmt.Sprintf("--jobs=%d", pda.Jobs))
 	}
 
+	args = append(args, additionalArgs...)
+
 	// remove duplicated arguments
 	args = backup.RemoveArgsDuplicate(args) // remove duplicated arguments
 
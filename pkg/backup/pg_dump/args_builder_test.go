@@ -1,6 +1,8 @@
 package pg_dump
 
 import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/storage"
 	"reflect"
 	"testing"
 )
@@ -14,49 +16,109 @@ func TestPgDumpArgsBuilder(t *testing.T) {
 	}{
 		{
 			name:    "Valid args without compression",
-			args:    &PgDumpArgs{Host: "192.168.1.26", Port: "5423", Username: "test", Database: "test", OutName: "test.sql", PgOutFormat: "p", Compress: false},
-			want:    []string{"--host=192.168.1.26", "--port=5423", "--username=test", "--dbname=test", "--file=test.sql", "--format=p"},
+			args:    &PgDumpArgs{Host: "192.168.1.26", Port: "5423", Username: "test", Database: "test", PgOutFormat: "p", Compress: false, Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--host=192.168.1.26", "--port=5423", "--username=test", "--dbname=test", "--format=p"},
+			wantErr: false,
+		},
+		{
+			name:    "TLS options are passed in the connection string",
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "p", TLS: backup.TLSOptions{Mode: "verify-ca", CAFile: "/certs/ca.pem"}, Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=dbname='test' sslmode='verify-ca' sslrootcert='/certs/ca.pem'", "--format=p"},
+			wantErr: false,
+		},
+		{
+			name:    "Verify full through an SSH tunnel",
+			args:    &PgDumpArgs{Host: "127.0.0.1", Port: "40321", Username: "test", Database: "test", PgOutFormat: "p", TLS: backup.TLSOptions{Mode: "verify-full", CAFile: "/certs/ca.pem", ServerName: "db.internal"}, Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--host=db.internal", "--port=40321", "--username=test", "--dbname=dbname='test' sslmode='verify-full' sslrootcert='/certs/ca.pem' hostaddr='127.0.0.1'", "--format=p"},
+			wantErr: false,
+		},
+		{
+			name:    "IPv6 host",
+			args:    &PgDumpArgs{Host: "[::1]", Username: "test", Database: "test", PgOutFormat: "p", Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--host=::1", "--port=5432", "--username=test", "--dbname=test", "--format=p"},
+			wantErr: false,
+		},
+		{
+			name:    "Unix socket directory",
+			args:    &PgDumpArgs{Host: "/var/run/postgresql", Username: "test", Database: "test", PgOutFormat: "p", Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--host=/var/run/postgresql", "--port=5432", "--username=test", "--dbname=test", "--format=p"},
 			wantErr: false,
 		},
 		{
 			name:    "Database missing - error expected",
-			args:    &PgDumpArgs{Username: "test", OutName: "test.sql", PgOutFormat: "p", Compress: false},
+			args:    &PgDumpArgs{Username: "test", PgOutFormat: "p", Compress: false, Storage: &storage.Params{OutName: "test"}},
 			want:    nil,
 			wantErr: true,
 		},
 		{
 			name:    "Username missing - error expected",
-			args:    &PgDumpArgs{Host: "localhost", Port: "5432", Database: "test", OutName: "test.sql", PgOutFormat: "p", Compress: false},
+			args:    &PgDumpArgs{Host: "localhost", Port: "5432", Database: "test", PgOutFormat: "p", Compress: false, Storage: &storage.Params{OutName: "test"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Parallel jobs with the directory format",
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "d", Jobs: 4, Storage: &storage.Params{StorageType: "local", OutName: "test"}},
+			want:    []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=d", "--file=/backups/test", "--jobs=4"},
+			wantErr: false,
+		},
+		{
+			name:    "Parallel jobs with the custom format - error expected",
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "c", Jobs: 4, Storage: &storage.Params{OutName: "test"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Parallel jobs in additional arguments with the plain format - error expected",
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "p", AdditionalArgs: "--jobs=4", Storage: &storage.Params{OutName: "test"}},
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:    "Table and schema filters",
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "c", Filters: backup.Filters{ExcludeSchemas: []string{"audit"}, ExcludeTableData: []string{"public.*_logs"}}, Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=c", "--exclude-schema=audit", "--exclude-table-data=public.*_logs"},
+			wantErr: false,
+		},
 		{
 			name:    "Default host and port with compression",
-			args:    &PgDumpArgs{Username: "test", Database: "test", OutName: "test.backup", PgOutFormat: "c", Compress: true, CompressionAlgorithm: "gzip", CompressionLevel: 4},
-			want:    []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--file=test.backup", "--format=c", "--compress=gzip:4"},
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "c", Compress: true, CompressionAlgorithm: "gzip", CompressionLevel: 4, Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=c", "--compress=gzip:4"},
 			wantErr: false,
 		},
 		{
 			name:    "Invalid compression algorithm - error expected",
-			args:    &PgDumpArgs{Username: "test", Database: "test", OutName: "test.backup", PgOutFormat: "c", Compress: true, CompressionAlgorithm: "invalid"},
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "c", Compress: true, CompressionAlgorithm: "invalid", Storage: &storage.Params{OutName: "test"}},
 			want:    nil,
 			wantErr: true,
 		},
 		{
 			name:    "invalid compression level - error expected",
-			args:    &PgDumpArgs{Username: "user", Database: "test", OutName: "test.backup", PgOutFormat: "c", Compress: true, CompressionAlgorithm: "gzip", CompressionLevel: 10},
+			args:    &PgDumpArgs{Username: "user", Database: "test", PgOutFormat: "c", Compress: true, CompressionAlgorithm: "gzip", CompressionLevel: 10, Storage: &storage.Params{OutName: "test"}},
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:    "Directory format writes into the backup path",
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "d", Storage: &storage.Params{OutName: "test", StorageType: "local"}},
+			want:    []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=d", "--file=/backups/test"},
+			wantErr: false,
+		},
+		{
+			name:    "Plain format compression is left to Sentinel",
+			args:    &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "p", Compress: true, CompressionAlgorithm: "gzip", CompressionLevel: 4, Storage: &storage.Params{OutName: "test"}},
+			want:    []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=p"},
+			wantErr: false,
+		},
 		{
 			name: "Additional args with no duplicates",
-			args: &PgDumpArgs{Username: "test", Database: "test", OutName: "test.backup", PgOutFormat: "c", Compress: false, AdditionalArgs: "--attribute-inserts --no-privileges"},
-			want: []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--file=test.backup", "--format=c", "--attribute-inserts", "--no-privileges"},
+			args: &PgDumpArgs{Username: "test", Database: "test", PgOutFormat: "c", Compress: false, AdditionalArgs: "--attribute-inserts --no-privileges", Storage: &storage.Params{OutName: "test"}},
+			want: []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=c", "--attribute-inserts", "--no-privileges"},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := argsBuilder(tt.args)
+			got, err := argsBuilder(tt.args, "/backups")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,63 @@
+package pg_dump
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"time"
+)
+
+// backupCockroach backs a CockroachDB database up with BACKUP INTO, which pg_dump does not
+// support. The nodes write the backup to the backup collection, Sentinel writes its metadata
+// to the storage, which the restore reads to find the backup in the collection.
+func backupCockroach(pda *PgDumpArgs, conn *sql.Connection) error {
+	if pda.CrdbBackupURI == "" {
+		return fmt.Errorf("CockroachDB is backed up with BACKUP INTO, the backup collection URI is required")
+	}
+
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(pda.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(pda.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	// avoid collisions with the metadata of existing backups
+	name := utils.DefaultValue(pda.Storage.OutName, utils.DefaultBackupOutName())
+	resource, err := storage.ResolveCollision(storageHandler, utils.FullPath(backupPath, name+backup.MetadataSuffix), pda.Storage.OnCollision)
+	if err != nil {
+		return err
+	}
+
+	result, err := sql.CockroachBackup(conn, pda.CrdbBackupURI)
+	if err != nil {
+		return err
+	}
+
+	metadata := &backup.Metadata{
+		Engine:    "postgres",
+		Flavor:    backup.FlavorCockroachDB,
+		Database:  pda.Database,
+		Backup:    result.Path,
+		CreatedAt: time.Now().UTC(),
+		Cockroach: result,
+	}
+	data, err := metadata.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := storageHandler.WriteBackup(data, resource); err != nil {
+		return fmt.Errorf("failed to write backup metadata to storage - %w", err)
+	}
+
+	fmt.Printf("Backup complete !\n")
+
+	return nil
+}
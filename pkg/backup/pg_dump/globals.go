@@ -0,0 +1,120 @@
+package pg_dump
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os/exec"
+)
+
+// globalsArgsBuilder builds the arguments of the pg_dumpall command dumping the
+// cluster-wide objects: roles, role memberships and tablespaces
+func globalsArgsBuilder(pda *PgDumpArgs) ([]string, error) {
+	if pda.Username == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+
+	pda.Host = utils.DefaultValue(pda.Host, "127.0.0.1")
+	pda.Port = utils.DefaultValue(pda.Port, "5432")
+
+	args := []string{
+		fmt.Sprintf("--host=%s", pda.TLS.PgHost(pda.Host)),
+		fmt.Sprintf("--port=%s", pda.Port),
+		fmt.Sprintf("--username=%s", pda.Username),
+		"--globals-only",
+	}
+
+	// pg_dumpall takes the TLS options through a connection string, the database name being ignored
+	if pda.TLS.Enabled() {
+		args = append(args, fmt.Sprintf("--dbname=%s", pda.TLS.PgConnString("postgres", pda.Host)))
+	}
+
+	return args, nil
+}
+
+// BackupGlobals backs up the roles and tablespaces of the PostgresSQL cluster using
+// pg_dumpall --globals-only, as a plain SQL backup stored next to the database backups.
+// They are not part of pg_dump backups, and must be restored before them.
+func BackupGlobals(pda *PgDumpArgs) error {
+	args, err := globalsArgsBuilder(pda)
+	if err != nil {
+		return fmt.Errorf("failed to build pg_dumpall args - %w", err)
+	}
+
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(pda.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(pda.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	// globals are always dumped as plain SQL
+	pda.PgOutFormat = "p"
+	if err := resolveOutName(pda, storageHandler, backupPath); err != nil {
+		return err
+	}
+
+	// check connectivity on the maintenance database
+	if ok, err := sql.CheckConnectivity("postgres", &sql.Connection{
+		Host:     pda.Host,
+		Port:     pda.Port,
+		User:     pda.Username,
+		Password: pda.Password,
+		Database: utils.DefaultValue(pda.Database, "postgres"),
+		TLS:      &pda.TLS,
+	}); !ok {
+		return err
+	}
+
+	cmd := exec.Command("pg_dumpall", args...)
+
+	// capture the command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	// capture the command output, compressed on the fly
+	var stdOut bytes.Buffer
+	out, err := compression.NewWriter(&stdOut, pda.Storage.Compression, pda.Storage.CompressionLevel)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = out
+
+	// pass the password through a temporary password file, keeping the inherited environment
+	cmd.Env = credentials.Environ()
+	if pda.Password != "" {
+		passFile, cleanup, err := credentials.PgPassFile(pda.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		cmd.Env = credentials.Environ("PGPASSFILE=" + passFile)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute pg_dumpall command - %w, %s", err, stdErr.String())
+	}
+
+	// flush the compressed stream
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup - %w", err)
+	}
+
+	// write the backup to the storage
+	if err := storageHandler.WriteBackup(stdOut.Bytes(), utils.FullPath(backupPath, pda.Storage.OutName)); err != nil {
+		return fmt.Errorf("failed to write backup to storage - %w", err)
+	}
+
+	fmt.Printf("Globals backup complete !\n")
+
+	return nil
+}
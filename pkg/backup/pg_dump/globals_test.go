@@ -0,0 +1,47 @@
+package pg_dump
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"reflect"
+	"testing"
+)
+
+func TestGlobalsArgsBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *PgDumpArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Default host and port",
+			args:    &PgDumpArgs{Username: "postgres"},
+			want:    []string{"--host=127.0.0.1", "--port=5432", "--username=postgres", "--globals-only"},
+			wantErr: false,
+		},
+		{
+			name:    "TLS options are passed in the connection string",
+			args:    &PgDumpArgs{Host: "db.internal", Port: "5433", Username: "postgres", TLS: backup.TLSOptions{Mode: "require"}},
+			want:    []string{"--host=db.internal", "--port=5433", "--username=postgres", "--globals-only", "--dbname=dbname='postgres' sslmode='require'"},
+			wantErr: false,
+		},
+		{
+			name:    "Username missing - error expected",
+			args:    &PgDumpArgs{},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := globalsArgsBuilder(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("globalsArgsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("globalsArgsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,32 +2,65 @@ package pg_dump
 
 import (
 	"fmt"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
 	"github.com/denisakp/sentinel/internal/utils"
+	"path/filepath"
+	"strings"
 )
 
-// setOutName Helper function to set output name based on compression and format
+// setOutName Helper function to set output name based on compression and format.
+// The extensions are only added when the name does not already end with them.
 func setOutName(pda *PgDumpArgs) error {
 	pda.Storage.OutName = utils.DefaultValue(pda.Storage.OutName, utils.DefaultBackupOutName())
 
-	if pda.Compress && pda.PgOutFormat == "p" {
-		return fmt.Errorf("plain format does not support compression")
+	// pg_dump cannot compress plain and tar dumps, Sentinel compresses them instead
+	if pda.Compress && (pda.PgOutFormat == "p" || pda.PgOutFormat == "t") && !compression.Enabled(pda.Storage.Compression) {
+		pda.Storage.Compression = utils.DefaultValue(pda.CompressionAlgorithm, "gzip")
+		pda.Storage.CompressionLevel = pda.CompressionLevel
 	}
-	if pda.Compress && pda.PgOutFormat == "t" {
-		return fmt.Errorf("tar format does not support compression")
+	if pda.PgOutFormat == "d" && compression.Enabled(pda.Storage.Compression) {
+		return fmt.Errorf("directory format cannot be compressed by Sentinel, use pg_dump compression instead")
 	}
 
+	var ext string
 	switch pda.PgOutFormat {
 	case "c":
-		pda.Storage.OutName += ".backup"
+		ext = ".backup"
 	case "d":
 	// Directory format if empty and compression is enabled
 	case "t":
-		pda.Storage.OutName += ".tar"
+		ext = ".tar"
 	case "p":
-		pda.Storage.OutName += ".sql"
+		ext = ".sql"
 	default:
 		return fmt.Errorf("unsupported output format: %s", pda.PgOutFormat)
 	}
 
+	outName := compression.TrimExtension(pda.Storage.OutName)
+	if !strings.HasSuffix(outName, ext) {
+		outName += ext
+	}
+	pda.Storage.OutName = compression.AppendExtension(outName, pda.Storage.Compression)
+
+	return nil
+}
+
+// resolveOutName sets the final backup name and applies the collision policy
+// of the storage to it, before pg_dump writes anything.
+func resolveOutName(pda *PgDumpArgs, storageHandler storage.Storage, backupPath string) error {
+	initializeDefaultArgs(pda)
+
+	if err := setOutName(pda); err != nil {
+		return err
+	}
+
+	resource, err := storage.ResolveCollision(storageHandler, utils.FullPath(backupPath, pda.Storage.OutName), pda.Storage.OnCollision)
+	if err != nil {
+		return err
+	}
+
+	pda.Storage.OutName = filepath.Base(resource)
+
 	return nil
 }
@@ -1,6 +1,7 @@
 package pg_dump
 
 import (
+	"github.com/denisakp/sentinel/internal/storage"
 	"testing"
 )
 
@@ -13,52 +14,79 @@ func Test_setOutName(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name:    "No outName, no compress, no format",
-			args:    &PgDumpArgs{Storage.OutName: "", Compress: false, PgOutFormat: "", Database: "test"},
-			wantOut: "test_backup.backup",
+			name:    "Unsupported format - error expected",
+			args:    &PgDumpArgs{Compress: false, PgOutFormat: "x", Database: "test", Storage: &storage.Params{OutName: "test"}},
+			wantErr: true,
+		},
+		{
+			name:    "Custom format with compression",
+			args:    &PgDumpArgs{Compress: true, PgOutFormat: "c", Database: "test", Storage: &storage.Params{OutName: "test"}},
+			wantOut: "test.backup",
 			wantErr: false,
 		},
 		{
-			name:    "Compress, no outFormat, adds custom format",
-			args:    &PgDumpArgs{OutName: "", Compress: true, PgOutFormat: "", Database: "test"},
-			wantOut: "test_backup.backup",
+			name:    "Tar format with compression enabled is compressed by Sentinel",
+			args:    &PgDumpArgs{Compress: true, PgOutFormat: "t", Database: "test", Storage: &storage.Params{OutName: "test"}},
+			wantOut: "test.tar.gz",
 			wantErr: false,
 		},
 		{
-			name:    "Tar format with compression enabled - error expected",
-			args:    &PgDumpArgs{OutName: "test", Compress: true, PgOutFormat: "t", Database: "test"},
-			wantOut: "test.tar",
-			wantErr: true,
+			name:    "Plain format with compression enabled is compressed by Sentinel",
+			args:    &PgDumpArgs{Compress: true, PgOutFormat: "p", CompressionAlgorithm: "zstd", Database: "test", Storage: &storage.Params{OutName: "test"}},
+			wantOut: "test.sql.zst",
+			wantErr: false,
 		},
 		{
-			name:    "Plain format with compression enabled - error expected",
-			args:    &PgDumpArgs{OutName: "test", Compress: true, PgOutFormat: "p", Database: "test"},
-			wantOut: "test.sql",
+			name:    "Plain format with Sentinel compression",
+			args:    &PgDumpArgs{PgOutFormat: "p", Database: "test", Storage: &storage.Params{OutName: "test.sql.lz4", Compression: "lz4"}},
+			wantOut: "test.sql.lz4",
+			wantErr: false,
+		},
+		{
+			name:    "Directory format with Sentinel compression - error expected",
+			args:    &PgDumpArgs{PgOutFormat: "d", Database: "test", Storage: &storage.Params{OutName: "test", Compression: "gzip"}},
 			wantErr: true,
 		},
 		{
 			name:    "Custom format without compression",
-			args:    &PgDumpArgs{OutName: "test", Compress: false, PgOutFormat: "c", Database: "test"},
+			args:    &PgDumpArgs{Compress: false, PgOutFormat: "c", Database: "test", Storage: &storage.Params{OutName: "test"}},
 			wantOut: "test.backup",
 			wantErr: false,
 		},
+		{
+			name:    "Directory format keeps the name",
+			args:    &PgDumpArgs{Compress: true, PgOutFormat: "d", Database: "test", Storage: &storage.Params{OutName: "test"}},
+			wantOut: "test",
+			wantErr: false,
+		},
 		{
 			name:    "Tar format without compression",
-			args:    &PgDumpArgs{OutName: "test", Compress: false, PgOutFormat: "t", Database: "test"},
+			args:    &PgDumpArgs{Compress: false, PgOutFormat: "t", Database: "test", Storage: &storage.Params{OutName: "test"}},
 			wantOut: "test.tar",
 			wantErr: false,
 		},
+		{
+			name:    "Extension already set",
+			args:    &PgDumpArgs{Compress: false, PgOutFormat: "c", Database: "test", Storage: &storage.Params{OutName: "test.backup"}},
+			wantOut: "test.backup",
+			wantErr: false,
+		},
 		{
 			name:    "Plain format without compression",
-			args:    &PgDumpArgs{OutName: "test", Compress: false, PgOutFormat: "p", Database: "test"},
+			args:    &PgDumpArgs{Compress: false, PgOutFormat: "p", Database: "test", Storage: &storage.Params{OutName: "test"}},
 			wantOut: "test.sql",
 			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := setOutName(tt.args); (err != nil) != tt.wantErr {
+			err := setOutName(tt.args)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("setOutName() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.args.Storage.OutName != tt.wantOut {
+				t.Errorf("setOutName() got = %v, want %v", tt.args.Storage.OutName, tt.wantOut)
 			}
 		})
 	}
@@ -3,13 +3,46 @@ package pg_dump
 import (
 	"bytes"
 	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
 	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/credentials"
 	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"time"
 )
 
-// Backup backs up a PostgresSQL database using pg_dump
+// Backup backs up a PostgresSQL database using pg_dump. The flavor of the server is detected
+// first: CockroachDB, which pg_dump does not support, is backed up with BACKUP INTO instead,
+// and the flavor is recorded in the metadata for the restore to adapt to it.
 func Backup(pda *PgDumpArgs) error {
+	initializeDefaultArgs(pda)
+
+	conn := &sql.Connection{
+		Host:     pda.Host,
+		Port:     pda.Port,
+		User:     pda.Username,
+		Password: pda.Password,
+		Database: pda.Database,
+		TLS:      &pda.TLS,
+	}
+
+	// check connectivity and detect the flavor of the server
+	server, err := sql.InspectPostgres(conn)
+	if err != nil {
+		return err
+	}
+
+	if server.Flavor == backup.FlavorCockroachDB {
+		return backupCockroach(pda, conn)
+	}
+	if pda.CrdbBackupURI != "" {
+		return fmt.Errorf("the backup collection URI only applies to CockroachDB")
+	}
+
 	// get the storage handler
 	storageHandler, err := storage.NewStorage(pda.Storage)
 	if err != nil {
@@ -22,15 +55,22 @@ func Backup(pda *PgDumpArgs) error {
 		return err
 	}
 
+	// set the backup name, avoiding collisions with existing backups
+	if err := resolveOutName(pda, storageHandler, backupPath); err != nil {
+		return err
+	}
+
 	// build pg_dump arguments
 	args, err := argsBuilder(pda, backupPath)
 	if err != nil {
 		return fmt.Errorf("failed to build pg_dump args - %w", err)
 	}
 
-	// check connectivity
-	if ok, err := sql.CheckConnectivity("postgres", pda.Host, pda.Port, pda.Username, pda.Password, pda.Database); !ok {
-		return err
+	// pg_dump refuses to write a directory dump into an existing directory
+	if pda.PgOutFormat == "d" && utils.IsDirectory(pda.Storage.OutName) {
+		if err := os.RemoveAll(pda.Storage.OutName); err != nil {
+			return fmt.Errorf("failed to remove existing backup directory - %w", err)
+		}
 	}
 
 	// run pg_dump command
@@ -40,26 +80,62 @@ func Backup(pda *PgDumpArgs) error {
 	var stdErr bytes.Buffer
 	cmd.Stderr = &stdErr
 
-	// capture the command output
+	// capture the command output, compressed on the fly
 	var stdOut bytes.Buffer
-	cmd.Stdout = &stdOut
+	out, err := compression.NewWriter(&stdOut, pda.Storage.Compression, pda.Storage.CompressionLevel)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = out
 
-	// remove the password from the environment after the command is done
-	cmd.Env = append(cmd.Env, fmt.Sprintf("PGPASSWORD=%s", pda.Password)) // set the password in the environment
-	defer func() {
-		cmd.Env = cmd.Env[:len(cmd.Env)-1]
-	}()
+	// pass the password through a temporary password file, keeping the inherited environment
+	cmd.Env = credentials.Environ()
+	if pda.Password != "" {
+		passFile, cleanup, err := credentials.PgPassFile(pda.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		cmd.Env = credentials.Environ("PGPASSFILE=" + passFile)
+	}
 
 	err = cmd.Run()
 	if err != nil {
 		return fmt.Errorf("failed to execute pg_dump command - %w, %s", err, stdErr.String())
 	}
 
+	// flush the compressed stream
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup - %w", err)
+	}
+
+	// directory dumps are written by pg_dump itself, other formats are written next to the backup path
+	resource := pda.Storage.OutName
+	if pda.PgOutFormat != "d" {
+		resource = utils.FullPath(backupPath, pda.Storage.OutName)
+	}
+
 	// write the backup to the storage
-	if err := storageHandler.WriteBackup(stdOut.Bytes(), pda.Storage.OutName); err != nil {
+	if err := storageHandler.WriteBackup(stdOut.Bytes(), resource); err != nil {
 		return fmt.Errorf("failed to write backup to storage - %w", err)
 	}
 
+	// write the metadata next to the backup, with the flavor of the server
+	metadata := &backup.Metadata{
+		Engine:    "postgres",
+		Flavor:    server.Flavor,
+		Database:  pda.Database,
+		Backup:    filepath.Base(pda.Storage.OutName),
+		CreatedAt: time.Now().UTC(),
+	}
+	data, err := metadata.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := storageHandler.WriteBackup(data, resource+backup.MetadataSuffix); err != nil {
+		return fmt.Errorf("failed to write backup metadata to storage - %w", err)
+	}
+
 	fmt.Printf("Backup complete !\n")
 
 	return nil
@@ -2,6 +2,8 @@ package pg_dump
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 func validatePgOutFormat(format string) error {
@@ -42,6 +44,42 @@ func validatePgCompressionLevel(level int) error {
 	return nil
 }
 
+// validatePgJobs validates the number of parallel jobs. pg_dump only dumps in parallel
+// to the directory format, each job opening its own connection to the database.
+func validatePgJobs(format string, jobs int, additionalArgs []string) error {
+	if jobs < 0 {
+		return fmt.Errorf("invalid number of jobs: %d", jobs)
+	}
+
+	parallel := jobs > 1
+	for i, arg := range additionalArgs {
+		var value string
+		switch {
+		case arg == "--jobs" || arg == "-j":
+			if i+1 < len(additionalArgs) {
+				value = additionalArgs[i+1]
+			}
+		case strings.HasPrefix(arg, "--jobs="):
+			value = strings.TrimPrefix(arg, "--jobs=")
+		case strings.HasPrefix(arg, "-j"):
+			value = strings.TrimPrefix(arg, "-j")
+		default:
+			continue
+		}
+
+		// a single job is not parallel, invalid values are left to pg_dump to report
+		if n, err := strconv.Atoi(value); err == nil && n > 1 {
+			parallel = true
+		}
+	}
+
+	if parallel && format != "d" {
+		return fmt.Errorf("parallel jobs require the directory format (d), got: %s", format)
+	}
+
+	return nil
+}
+
 func validateRequiredArgs(pda *PgDumpArgs) error {
 	if pda.Database == "" {
 		return fmt.Errorf("database name is required")
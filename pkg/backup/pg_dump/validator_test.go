@@ -31,10 +31,11 @@ func TestValidatePgCompressionLevel(t *testing.T) {
 		level   int
 		wantErr bool
 	}{
-		{0, false}, // minimum valid compression level
-		{9, false}, // maximum valid compression level
-		{-1, true}, // below minimum valid compression level
-		{10, true}, // above maximum valid compression level
+		{1, false},  // minimum valid compression level
+		{9, false},  // maximum valid compression level
+		{-1, false}, // default compression level
+		{0, true},   // below minimum valid compression level
+		{10, true},  // above maximum valid compression level
 	}
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("Level%d", tt.level), func(t *testing.T) {
@@ -102,3 +103,31 @@ func Test_validateRequiredArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePgJobs(t *testing.T) {
+	tests := []struct {
+		name           string
+		format         string
+		jobs           int
+		additionalArgs []string
+		wantErr        bool
+	}{
+		{"No jobs with the plain format", "p", 0, nil, false},
+		{"Single job with the custom format", "c", 1, nil, false},
+		{"Parallel jobs with the directory format", "d", 8, nil, false},
+		{"Parallel jobs with the tar format", "t", 2, nil, true},
+		{"Short jobs argument with the custom format", "c", 0, []string{"-j", "4"}, true},
+		{"Jobs argument with the directory format", "d", 0, []string{"--jobs=4"}, false},
+		{"Single job argument with the custom format", "c", 0, []string{"--jobs=1"}, false},
+		{"Attached short jobs argument with the tar format", "t", 0, []string{"-j2"}, true},
+		{"Separate single job argument with the plain format", "p", 0, []string{"--jobs", "1"}, false},
+		{"Negative jobs", "d", -1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePgJobs(tt.format, tt.jobs, tt.additionalArgs); (err != nil) != tt.wantErr {
+				t.Errorf("validatePgJobs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package redis_backup
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/redis"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"path/filepath"
+	"time"
+)
+
+type RedisBackupArgs struct {
+	Host         string            // Redis host
+	Port         string            // Redis port
+	Username     string            // Redis ACL user, empty for the default user
+	Password     string            // Redis password
+	TLS          backup.TLSOptions // TLS options
+	BgSave       bool              // trigger BGSAVE and read the RDB file written by the server, instead of transferring it like a replica
+	RdbPath      string            // path of the RDB file written by BGSAVE, when the data directory of the server is mounted elsewhere
+	PollInterval time.Duration     // interval the completion of BGSAVE is polled at
+	SaveTimeout  time.Duration     // time BGSAVE is waited for, an hour when not set
+	Storage      *storage.Params   // Storage parameters
+}
+
+// options returns the connection options of the Redis server, with the default host and port
+func options(rba *RedisBackupArgs) *redis.Options {
+	return &redis.Options{
+		Host:     utils.DefaultValue(rba.Host, "127.0.0.1"),
+		Port:     utils.DefaultValue(rba.Port, "6379"),
+		Username: rba.Username,
+		Password: rba.Password,
+		TLS:      &rba.TLS,
+	}
+}
+
+// outName returns the name of the backup, with the .rdb extension when the name has none
+// and the extension of the compression algorithm
+func outName(rba *RedisBackupArgs) string {
+	outName := compression.TrimExtension(utils.DefaultValue(rba.Storage.OutName, utils.DefaultBackupOutName()))
+	if filepath.Ext(outName) == "" {
+		outName += ".rdb"
+	}
+
+	return compression.AppendExtension(outName, rba.Storage.Compression)
+}
@@ -0,0 +1,192 @@
+package redis_backup
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/redis"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// rdbMagic starts every RDB file
+var rdbMagic = []byte("REDIS")
+
+// Backup backs up every database of a Redis server as an RDB snapshot. By default the
+// snapshot is transferred like a replica does, which works with remote servers; with
+// BgSave, the server writes it to its data directory, which must be readable by Sentinel.
+func Backup(rba *RedisBackupArgs) error {
+	if err := validateRequiredArgs(rba); err != nil {
+		return fmt.Errorf("failed to build arguments - %w", err)
+	}
+
+	// check connectivity and describe the dataset
+	dataset, err := describe(options(rba))
+	if err != nil {
+		return err
+	}
+
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(rba.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(rba.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	// avoid collisions with existing backups
+	fullPath, err := storage.ResolveCollision(storageHandler, utils.FullPath(backupPath, outName(rba)), rba.Storage.OnCollision)
+	if err != nil {
+		return err
+	}
+
+	// take the snapshot, compressed on the fly
+	var data bytes.Buffer
+	out, err := compression.NewWriter(&data, rba.Storage.Compression, rba.Storage.CompressionLevel)
+	if err != nil {
+		return err
+	}
+
+	head := &backup.HeadWriter{Limit: len(rdbMagic)}
+	if rba.BgSave {
+		err = bgSave(rba, io.MultiWriter(out, head))
+	} else {
+		err = syncRDB(rba, io.MultiWriter(out, head))
+	}
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(head.Bytes(), rdbMagic) {
+		return fmt.Errorf("the redis snapshot is not an RDB file")
+	}
+
+	// flush the compressed stream
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup - %w", err)
+	}
+
+	// write backup to storage
+	if err := storageHandler.WriteBackup(data.Bytes(), fullPath); err != nil {
+		return fmt.Errorf("failed to write backup to storage - %w", err)
+	}
+
+	// write the metadata next to the backup, with the databases it holds
+	metadata := &backup.Metadata{
+		Engine:    "redis",
+		Backup:    filepath.Base(fullPath),
+		CreatedAt: time.Now().UTC(),
+		Redis:     dataset,
+	}
+	metadataData, err := metadata.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := storageHandler.WriteBackup(metadataData, fullPath+backup.MetadataSuffix); err != nil {
+		return fmt.Errorf("failed to write backup metadata to storage - %w", err)
+	}
+
+	fmt.Printf("Backup complete !\n")
+
+	return nil
+}
+
+// describe connects to the server and describes its dataset: the version of the server,
+// the number of databases and the number of keys of each of them
+func describe(o *redis.Options) (*backup.RedisDataset, error) {
+	client, err := redis.Dial(o)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	server, err := client.Info("server")
+	if err != nil {
+		return nil, err
+	}
+
+	keyspace, err := client.Info("keyspace")
+	if err != nil {
+		return nil, err
+	}
+
+	dataset := &backup.RedisDataset{Version: server["redis_version"], Keys: redis.Keyspace(keyspace)}
+
+	// CONFIG is often disabled on managed servers, the number of databases is then unknown
+	if databases, err := client.Config("databases"); err == nil {
+		dataset.Databases, _ = strconv.Atoi(databases)
+	}
+
+	return dataset, nil
+}
+
+// syncRDB transfers the snapshot of the server to w like a replica
+func syncRDB(rba *RedisBackupArgs, w io.Writer) error {
+	client, err := redis.Dial(options(rba))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if _, err := client.SyncRDB(w); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bgSave triggers BGSAVE, waits for it to complete and copies the RDB file written by the server to w
+func bgSave(rba *RedisBackupArgs, w io.Writer) error {
+	client, err := redis.Dial(options(rba))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if rba.PollInterval <= 0 {
+		rba.PollInterval = time.Second
+	}
+	if rba.SaveTimeout <= 0 {
+		rba.SaveTimeout = time.Hour
+	}
+
+	path, err := client.BgSave(rba.PollInterval, rba.SaveTimeout)
+	if err != nil {
+		return err
+	}
+
+	// the path is the one on the host of the server, readable when Sentinel runs on the same host,
+	// the data directory of the server may be mounted elsewhere
+	path = utils.DefaultValue(rba.RdbPath, path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("the RDB file %s written by the server is not readable from this host, mount the data directory of the server "+
+			"and pass the path of the RDB file with --redis-rdb-path, or back up without --redis-bgsave - %w", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("the RDB file %s written by the server is not a regular file", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the RDB file written by the server - %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to read the RDB file written by the server - %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package redis_backup
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRedis starts a Redis stand-in serving the snapshot with SYNC
+func fakeRedis(t *testing.T, rdb string) (string, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	bulk := func(s string) string { return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s) }
+	replies := map[string]string{
+		"AUTH":     "+OK\r\n",
+		"INFO":     bulk("# Server\r\nredis_version:7.2.4\r\n# Keyspace\r\ndb0:keys=3,expires=0,avg_ttl=0\r\n"),
+		"CONFIG":   "*2\r\n" + bulk("databases") + bulk("16"),
+		"REPLCONF": "+OK\r\n",
+		"SYNC":     fmt.Sprintf("\n$%d\r\n%s", len(rdb), rdb),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					// commands are arrays of bulk strings, the name is the first one
+					header, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					var count int
+					_, _ = fmt.Sscanf(header, "*%d", &count)
+
+					var name string
+					for i := 0; i < count*2; i++ {
+						line, err := reader.ReadString('\n')
+						if err != nil {
+							return
+						}
+						if i == 1 {
+							name = strings.TrimSpace(line)
+						}
+					}
+
+					if _, err := io.WriteString(conn, replies[name]); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port
+}
+
+func TestBackup(t *testing.T) {
+	rdb := "REDIS0011" + strings.Repeat("\x00data", 1000)
+	host, port := fakeRedis(t, rdb)
+	dir := t.TempDir()
+
+	err := Backup(&RedisBackupArgs{
+		Host:     host,
+		Port:     port,
+		Username: "backup",
+		Password: "secret",
+		Storage:  &storage.Params{OutName: "cache", LocalPath: dir, Compression: "gzip"},
+	})
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	// the snapshot is stored compressed
+	reader, err := compression.OpenFile(filepath.Join(dir, "cache.rdb.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != rdb {
+		t.Errorf("Backup() stored %d bytes, want %d", len(data), len(rdb))
+	}
+
+	// the metadata describes the databases of the snapshot
+	raw, err := os.ReadFile(filepath.Join(dir, "cache.rdb.gz"+backup.MetadataSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := backup.ParseMetadata(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &backup.RedisDataset{Version: "7.2.4", Databases: 16, Keys: map[string]int64{"db0": 3}}
+	if !reflect.DeepEqual(metadata.Redis, want) {
+		t.Errorf("Backup() metadata = %+v, want %+v", metadata.Redis, want)
+	}
+}
+
+func TestOutName(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *storage.Params
+		want   string
+	}{
+		{name: "Default extension", params: &storage.Params{OutName: "cache"}, want: "cache.rdb"},
+		{name: "Name with extension", params: &storage.Params{OutName: "cache.dump"}, want: "cache.dump"},
+		{name: "Compressed", params: &storage.Params{OutName: "cache.zst", Compression: "zstd"}, want: "cache.rdb.zst"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outName(&RedisBackupArgs{Storage: tt.params}); got != tt.want {
+				t.Errorf("outName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
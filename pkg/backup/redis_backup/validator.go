@@ -0,0 +1,18 @@
+package redis_backup
+
+import (
+	"fmt"
+)
+
+// validateRequiredArgs validates required arguments for Redis backup
+func validateRequiredArgs(rba *RedisBackupArgs) error {
+	if rba.Username != "" && rba.Password == "" {
+		return fmt.Errorf("password is missing for user %s", rba.Username)
+	}
+
+	if rba.RdbPath != "" && !rba.BgSave {
+		return fmt.Errorf("the RDB file path only applies to BGSAVE backups")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package search_snapshot
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/search"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"strings"
+	"time"
+)
+
+// takenBy marks the snapshots taken by Sentinel in their metadata, only they are pruned
+const takenBy = "sentinel"
+
+type SearchSnapshotArgs struct {
+	Type               string            // elasticsearch or opensearch
+	Host               string            // cluster host
+	Port               string            // cluster port
+	Username           string            // cluster user, empty when the security is disabled
+	Password           string            // cluster password
+	TLS                backup.TLSOptions // TLS options
+	Repository         string            // snapshot repository
+	RepositoryType     string            // type of the repository to register (fs, s3, ...), empty when it is already registered
+	RepositorySettings string            // comma separated key=value settings of the repository to register
+	Indices            string            // comma separated indices or patterns to snapshot, every index when empty
+	Keep               int               // number of snapshots taken by Sentinel kept in the repository, 0 to keep them all
+	PollInterval       time.Duration     // interval the completion of the snapshot is polled at
+	SnapshotTimeout    time.Duration     // time the snapshot is waited for before it is recorded as failed, 6 hours when not set
+	Storage            *storage.Params   // Storage parameters, the metadata of the snapshot is stored there
+}
+
+// options returns the connection options of the cluster, with the default host and port
+func options(ssa *SearchSnapshotArgs) *search.Options {
+	return &search.Options{
+		Host:     utils.DefaultValue(ssa.Host, "127.0.0.1"),
+		Port:     utils.DefaultValue(ssa.Port, "9200"),
+		Username: ssa.Username,
+		Password: ssa.Password,
+		TLS:      &ssa.TLS,
+	}
+}
+
+// parseSettings parses the comma separated key=value settings of the repository
+func parseSettings(value string) (map[string]string, error) {
+	settings := map[string]string{}
+
+	for _, setting := range strings.Split(value, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(setting, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid repository setting %q, expected key=value", setting)
+		}
+		settings[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+
+	return settings, nil
+}
+
+// parseIndices parses the comma separated indices
+func parseIndices(value string) []string {
+	var indices []string
+
+	for _, index := range strings.Split(value, ",") {
+		if index = strings.TrimSpace(index); index != "" {
+			indices = append(indices, index)
+		}
+	}
+
+	return indices
+}
+
+// snapshotName returns the name of the snapshot from the backup name. Snapshot names
+// must be lowercase and cannot hold some characters, which are replaced.
+func snapshotName(outName string) string {
+	name := strings.ToLower(utils.DefaultValue(outName, utils.DefaultBackupOutName()))
+
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/*?"<>|,# `, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
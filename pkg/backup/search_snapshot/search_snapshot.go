@@ -0,0 +1,198 @@
+package search_snapshot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/search"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
+	"strings"
+	"time"
+)
+
+// Backup takes a snapshot of the indices of an Elasticsearch or OpenSearch cluster into its
+// snapshot repository, registered first when its type is provided, and waits for it to complete.
+// The outcome of each index is printed as a run summary, and the metadata of the snapshot is
+// written to the storage, next to the database backups. Once the snapshot succeeded, the oldest
+// snapshots taken by Sentinel are deleted from the repository beyond the number to keep.
+func Backup(ssa *SearchSnapshotArgs) error {
+	if err := validateRequiredArgs(ssa); err != nil {
+		return fmt.Errorf("failed to build arguments - %w", err)
+	}
+
+	settings, err := parseSettings(ssa.RepositorySettings)
+	if err != nil {
+		return err
+	}
+
+	client, err := search.NewClient(options(ssa))
+	if err != nil {
+		return err
+	}
+
+	// check connectivity
+	cluster, err := client.Info()
+	if err != nil {
+		return err
+	}
+
+	// register the repository, or check the registered one
+	if ssa.RepositoryType != "" {
+		if err := client.RegisterRepository(ssa.Repository, ssa.RepositoryType, settings); err != nil {
+			return err
+		}
+	}
+	if err := client.VerifyRepository(ssa.Repository); err != nil {
+		return err
+	}
+
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(ssa.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(ssa.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	// take the snapshot and wait for it
+	name := snapshotName(ssa.Storage.OutName)
+	if err := client.CreateSnapshot(ssa.Repository, name, parseIndices(ssa.Indices), map[string]string{"taken_by": takenBy}); err != nil {
+		return err
+	}
+
+	if ssa.PollInterval <= 0 {
+		ssa.PollInterval = 5 * time.Second
+	}
+	if ssa.SnapshotTimeout <= 0 {
+		ssa.SnapshotTimeout = 6 * time.Hour
+	}
+
+	// a snapshot not completed in time is recorded as failed, it may still complete in the cluster
+	var timeoutErr error
+	snapshot, err := client.WaitSnapshot(ssa.Repository, name, ssa.PollInterval, ssa.SnapshotTimeout)
+	if errors.Is(err, search.ErrSnapshotTimeout) {
+		timeoutErr = fmt.Errorf("not completed after %s", ssa.SnapshotTimeout)
+		snapshot.State = search.StateFailed
+	} else if err != nil {
+		return err
+	}
+
+	// report the outcome of every index
+	summary := summarize(snapshot, timeoutErr)
+	if err := summary.Print(os.Stdout); err != nil {
+		return err
+	}
+
+	// write the metadata of the snapshot to the storage, whatever its outcome
+	metadata := &backup.Metadata{
+		Engine:    ssa.Type,
+		Database:  ssa.Indices,
+		Backup:    name,
+		CreatedAt: time.Now().UTC(),
+		Snapshot: &backup.SearchSnapshot{
+			Cluster:      cluster.Name,
+			Version:      cluster.Version.Number,
+			Repository:   ssa.Repository,
+			Snapshot:     name,
+			State:        snapshot.State,
+			Indices:      snapshot.Indices,
+			Shards:       snapshot.Shards.Total,
+			FailedShards: snapshot.Shards.Failed,
+		},
+	}
+	data, err := metadata.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := storageHandler.WriteBackup(data, utils.FullPath(backupPath, name+backup.MetadataSuffix)); err != nil {
+		return fmt.Errorf("failed to write snapshot metadata to storage - %w", err)
+	}
+
+	if timeoutErr != nil {
+		return fmt.Errorf("snapshot %s %w", name, timeoutErr)
+	}
+
+	if snapshot.State != search.StateSuccess {
+		return fmt.Errorf("snapshot %s %s: %d of %d shards failed", name, strings.ToLower(snapshot.State), snapshot.Shards.Failed, snapshot.Shards.Total)
+	}
+
+	// prune the oldest snapshots, only once a new one succeeded
+	if err := prune(client, ssa.Repository, ssa.Keep); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backup complete !\n")
+
+	return nil
+}
+
+// summarize builds the run summary of the snapshot, one result per index
+// holding the failures of its shards, or the timeout of the snapshot
+func summarize(snapshot *search.Snapshot, timeoutErr error) *backup.RunSummary {
+	failures := map[string][]string{}
+	for _, failure := range snapshot.Failures {
+		failures[failure.Index] = append(failures[failure.Index], fmt.Sprintf("shard %d: %s", failure.ShardID, failure.Reason))
+	}
+
+	summary := &backup.RunSummary{}
+	for _, index := range snapshot.Indices {
+		err := timeoutErr
+		if reasons := failures[index]; len(reasons) > 0 {
+			err = errors.New(strings.Join(reasons, "; "))
+		}
+		summary.Add(index, snapshot.Duration(), err)
+	}
+
+	// a failed snapshot may not list its indices
+	if len(snapshot.Indices) == 0 && snapshot.State != search.StateSuccess {
+		err := timeoutErr
+		if err == nil {
+			err = fmt.Errorf("snapshot %s", strings.ToLower(snapshot.State))
+		}
+		summary.Add(snapshot.Name, snapshot.Duration(), err)
+	}
+
+	return summary
+}
+
+// prune deletes the snapshots taken by Sentinel older than the successful snapshots to keep,
+// failed ones included. Other snapshots of the repository are left untouched.
+func prune(client *search.Client, repository string, keep int) error {
+	if keep == 0 {
+		return nil
+	}
+
+	snapshots, err := client.ListSnapshots(repository)
+	if err != nil {
+		return err
+	}
+
+	// walk the snapshots from the newest one
+	kept := 0
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := snapshots[i]
+		if snapshot.Metadata["taken_by"] != takenBy || snapshot.State == search.StateInProgress {
+			continue
+		}
+
+		if kept < keep {
+			if snapshot.State == search.StateSuccess {
+				kept++
+			}
+			continue
+		}
+
+		if err := client.DeleteSnapshot(repository, snapshot.Name); err != nil {
+			return err
+		}
+		fmt.Printf("Snapshot %s deleted\n", snapshot.Name)
+	}
+
+	return nil
+}
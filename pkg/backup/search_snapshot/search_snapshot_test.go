@@ -0,0 +1,208 @@
+package search_snapshot
+
+import (
+	"encoding/json"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/storage"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCluster is an Elasticsearch stand-in serving the snapshot API
+type fakeCluster struct {
+	mu         sync.Mutex
+	repository map[string]interface{}   // registered repository
+	snapshots  []map[string]interface{} // snapshots of the repository, oldest first
+	polls      int                      // requests of the snapshot being taken
+	state      string                   // state of the snapshot once completed
+	deleted    []string                 // deleted snapshots
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if user, password, _ := r.BasicAuth(); user != "elastic" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"type":"security_exception","reason":"unable to authenticate user"}}`))
+		return
+	}
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	reply := func(v interface{}) { _ = json.NewEncoder(w).Encode(v) }
+
+	switch {
+	case r.URL.Path == "/":
+		reply(map[string]interface{}{"cluster_name": "logs", "version": map[string]string{"number": "8.15.0"}})
+	case r.Method == http.MethodPut && len(path) == 2:
+		_ = json.NewDecoder(r.Body).Decode(&f.repository)
+		reply(map[string]bool{"acknowledged": true})
+	case r.Method == http.MethodPost && len(path) == 3 && path[2] == "_verify":
+		if f.repository == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"type":"repository_missing_exception","reason":"[backups] missing"}}`))
+			return
+		}
+		reply(map[string]interface{}{"nodes": map[string]interface{}{}})
+	case r.Method == http.MethodPut && len(path) == 3:
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.snapshots = append(f.snapshots, map[string]interface{}{
+			"snapshot":             path[2],
+			"state":                "IN_PROGRESS",
+			"indices":              strings.Split(body["indices"].(string), ","),
+			"metadata":             body["metadata"],
+			"start_time_in_millis": 1000 * (len(f.snapshots) + 1),
+		})
+		reply(map[string]bool{"accepted": true})
+	case r.Method == http.MethodGet && len(path) == 3 && path[2] == "_all":
+		reply(map[string]interface{}{"snapshots": f.snapshots})
+	case r.Method == http.MethodGet && len(path) == 3:
+		snapshot := f.snapshots[len(f.snapshots)-1]
+		if f.polls++; f.polls > 1 {
+			snapshot["state"] = f.state
+			snapshot["shards"] = map[string]int{"total": 2, "successful": 2, "failed": 0}
+			snapshot["end_time_in_millis"] = snapshot["start_time_in_millis"].(int) + 1500
+			if f.state == "PARTIAL" {
+				snapshot["shards"] = map[string]int{"total": 2, "successful": 1, "failed": 1}
+				snapshot["failures"] = []map[string]interface{}{{"index": "logs", "shard_id": 1, "reason": "node left"}}
+			}
+		}
+		reply(map[string]interface{}{"snapshots": []interface{}{snapshot}})
+	case r.Method == http.MethodDelete && len(path) == 3:
+		f.deleted = append(f.deleted, path[2])
+		reply(map[string]bool{"acknowledged": true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestBackup(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		existing    []map[string]interface{}
+		wantDeleted []string
+		wantErr     bool
+	}{
+		{
+			name:  "Snapshot succeeded, older snapshots pruned",
+			state: "SUCCESS",
+			existing: []map[string]interface{}{
+				{"snapshot": "nightly-1", "state": "SUCCESS", "metadata": map[string]string{"taken_by": "sentinel"}},
+				{"snapshot": "manual", "state": "SUCCESS"},
+				{"snapshot": "nightly-2", "state": "FAILED", "metadata": map[string]string{"taken_by": "sentinel"}},
+				{"snapshot": "nightly-3", "state": "SUCCESS", "metadata": map[string]string{"taken_by": "sentinel"}},
+			},
+			wantDeleted: []string{"nightly-2", "nightly-1"},
+		},
+		{
+			name:     "Snapshot not completed in time - error expected, recorded as failed, nothing pruned",
+			state:    "IN_PROGRESS",
+			existing: []map[string]interface{}{{"snapshot": "nightly-1", "state": "SUCCESS", "metadata": map[string]string{"taken_by": "sentinel"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "Snapshot partially failed - error expected, nothing pruned",
+			state:    "PARTIAL",
+			existing: []map[string]interface{}{{"snapshot": "nightly-1", "state": "SUCCESS", "metadata": map[string]string{"taken_by": "sentinel"}}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &fakeCluster{snapshots: tt.existing, state: tt.state}
+			server := httptest.NewServer(cluster)
+			defer server.Close()
+
+			host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+			dir := t.TempDir()
+
+			err := Backup(&SearchSnapshotArgs{
+				Type:               "elasticsearch",
+				Host:               host,
+				Port:               port,
+				Username:           "elastic",
+				Password:           "secret",
+				Repository:         "backups",
+				RepositoryType:     "fs",
+				RepositorySettings: "location=/mnt/snapshots, compress=true",
+				Indices:            "logs,metrics-*",
+				Keep:               2,
+				PollInterval:       1,
+				SnapshotTimeout:    100 * time.Millisecond,
+				Storage:            &storage.Params{OutName: "Nightly_2024", LocalPath: dir},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Backup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			wantRepository := map[string]interface{}{"type": "fs", "settings": map[string]interface{}{"location": "/mnt/snapshots", "compress": "true"}}
+			if !reflect.DeepEqual(cluster.repository, wantRepository) {
+				t.Errorf("Backup() repository = %v, want %v", cluster.repository, wantRepository)
+			}
+			if !reflect.DeepEqual(cluster.deleted, tt.wantDeleted) {
+				t.Errorf("Backup() deleted = %v, want %v", cluster.deleted, tt.wantDeleted)
+			}
+
+			// the outcome is recorded in the storage, whatever it is
+			data, err := os.ReadFile(filepath.Join(dir, "nightly_2024"+backup.MetadataSuffix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			metadata, err := backup.ParseMetadata(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &backup.SearchSnapshot{Cluster: "logs", Version: "8.15.0", Repository: "backups", Snapshot: "nightly_2024", State: tt.state, Indices: []string{"logs", "metrics-*"}, Shards: 2}
+			if tt.state == "PARTIAL" {
+				want.FailedShards = 1
+			}
+			if tt.state == "IN_PROGRESS" {
+				want.State = "FAILED"
+			}
+			if !reflect.DeepEqual(metadata.Snapshot, want) {
+				t.Errorf("Backup() metadata = %+v, want %+v", metadata.Snapshot, want)
+			}
+		})
+	}
+}
+
+func TestParseSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "No settings", value: "", want: map[string]string{}},
+		{name: "Settings", value: "bucket=backups, base_path = es", want: map[string]string{"bucket": "backups", "base_path": "es"}},
+		{name: "Missing value - error expected", value: "bucket", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSettings(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSettings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSettings() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotName(t *testing.T) {
+	if got := snapshotName("Nightly logs/2024"); got != "nightly_logs_2024" {
+		t.Errorf("snapshotName() got = %v, want nightly_logs_2024", got)
+	}
+}
@@ -0,0 +1,26 @@
+package search_snapshot
+
+import (
+	"fmt"
+)
+
+// validateRequiredArgs validates required arguments for Elasticsearch and OpenSearch snapshots
+func validateRequiredArgs(ssa *SearchSnapshotArgs) error {
+	if ssa.Type != "elasticsearch" && ssa.Type != "opensearch" {
+		return fmt.Errorf("invalid search engine type: %s", ssa.Type)
+	}
+
+	if ssa.Repository == "" {
+		return fmt.Errorf("snapshot repository is missing")
+	}
+
+	if ssa.RepositorySettings != "" && ssa.RepositoryType == "" {
+		return fmt.Errorf("repository settings require the repository type")
+	}
+
+	if ssa.Keep < 0 {
+		return fmt.Errorf("number of snapshots to keep cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package sqlite_backup
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"path/filepath"
+	"strings"
+)
+
+type SqliteBackupArgs struct {
+	Database       string          // path of the SQLite database file
+	Vacuum         bool            // copy the database with VACUUM INTO, compacting it, instead of the backup API
+	Dump           bool            // also store a .dump SQL script of the copy
+	AdditionalArgs string          // Additional arguments for the sqlite3 command
+	Storage        *storage.Params // Storage parameters
+}
+
+// argsBuilder builds the arguments of the sqlite3 command copying the database to the copy path.
+// Both the backup API (.backup) and VACUUM INTO take a consistent copy while the database is in use.
+func argsBuilder(sba *SqliteBackupArgs, copyPath string) ([]string, error) {
+	if err := validateRequiredArgs(sba); err != nil {
+		return nil, err
+	}
+
+	args := []string{"-bail"}
+
+	// handle additional arguments, the sqlite3 options come before the database
+	if sba.AdditionalArgs != "" {
+		args = append(args, backup.ParseAdditionalArgs(sba.AdditionalArgs)...)
+	}
+
+	args = append(args, sba.Database)
+
+	if sba.Vacuum {
+		args = append(args, fmt.Sprintf("VACUUM INTO '%s'", strings.ReplaceAll(copyPath, "'", "''")))
+	} else {
+		args = append(args, fmt.Sprintf(".backup '%s'", copyPath))
+	}
+
+	return backup.RemoveArgsDuplicate(args), nil
+}
+
+// dumpArgsBuilder builds the arguments of the sqlite3 command writing the SQL script of the copy
+func dumpArgsBuilder(copyPath string) []string {
+	return []string{"-bail", "-readonly", copyPath, ".dump"}
+}
+
+// outName returns the name of the copy, with the .sqlite extension when the name has none,
+// e.g. to keep a .db extension, and the extension of the compression algorithm
+func outName(sba *SqliteBackupArgs) string {
+	outName := compression.TrimExtension(utils.DefaultValue(sba.Storage.OutName, utils.DefaultBackupOutName()))
+	if filepath.Ext(outName) == "" {
+		outName += ".sqlite"
+	}
+
+	return compression.AppendExtension(outName, sba.Storage.Compression)
+}
+
+// scriptName returns the name of the SQL script stored next to the copy
+func scriptName(copyName, algorithm string) string {
+	base := compression.TrimExtension(copyName)
+	return compression.AppendExtension(strings.TrimSuffix(base, filepath.Ext(base))+".sql", algorithm)
+}
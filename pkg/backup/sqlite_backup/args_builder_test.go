@@ -0,0 +1,91 @@
+package sqlite_backup
+
+import (
+	"github.com/denisakp/sentinel/internal/storage"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArgsBuilder(t *testing.T) {
+	database := filepath.Join(t.TempDir(), "app.db")
+	if err := os.WriteFile(database, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    *SqliteBackupArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Required args missing",
+			args:    &SqliteBackupArgs{},
+			wantErr: true,
+		},
+		{
+			name:    "Missing database file",
+			args:    &SqliteBackupArgs{Database: database + ".missing"},
+			wantErr: true,
+		},
+		{
+			name:    "Database path read as an option",
+			args:    &SqliteBackupArgs{Database: "-version"},
+			wantErr: true,
+		},
+		{
+			name: "Backup API",
+			args: &SqliteBackupArgs{Database: database},
+			want: []string{"-bail", database, ".backup '/tmp/copy.sqlite'"},
+		},
+		{
+			name: "Vacuum into",
+			args: &SqliteBackupArgs{Database: database, Vacuum: true},
+			want: []string{"-bail", database, "VACUUM INTO '/tmp/copy.sqlite'"},
+		},
+		{
+			name: "Additional Arguments",
+			args: &SqliteBackupArgs{Database: database, AdditionalArgs: "-readonly -bail"},
+			want: []string{"-bail", "-readonly", database, ".backup '/tmp/copy.sqlite'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := argsBuilder(tt.args, "/tmp/copy.sqlite")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("argsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutName(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     *storage.Params
+		want       string
+		wantScript string
+	}{
+		{name: "Default extension", params: &storage.Params{OutName: "app"}, want: "app.sqlite", wantScript: "app.sql"},
+		{name: "Name with extension", params: &storage.Params{OutName: "app.db"}, want: "app.db", wantScript: "app.sql"},
+		{name: "Compressed", params: &storage.Params{OutName: "app", Compression: "zstd"}, want: "app.sqlite.zst", wantScript: "app.sql.zst"},
+		{name: "Name with compression extension", params: &storage.Params{OutName: "app.gz", Compression: "gzip"}, want: "app.sqlite.gz", wantScript: "app.sql.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := outName(&SqliteBackupArgs{Storage: tt.params})
+			if got != tt.want {
+				t.Errorf("outName() got = %v, want %v", got, tt.want)
+			}
+			if script := scriptName(got, tt.params.Compression); script != tt.wantScript {
+				t.Errorf("scriptName() got = %v, want %v", script, tt.wantScript)
+			}
+		})
+	}
+}
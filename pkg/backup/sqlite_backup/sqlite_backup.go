@@ -0,0 +1,135 @@
+package sqlite_backup
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Backup backs up a SQLite database with the sqlite3 command. The database is copied
+// with the backup API or VACUUM INTO, which are consistent while the database is
+// written to, unlike a copy of the file.
+func Backup(sba *SqliteBackupArgs) error {
+	// the copy is written to a temporary directory before being compressed
+	tempDir, err := os.MkdirTemp("", "sentinel-sqlite-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory - %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	copyPath := filepath.Join(tempDir, "backup.sqlite")
+
+	args, err := argsBuilder(sba, copyPath)
+	if err != nil {
+		return fmt.Errorf("failed to build sqlite3 args - %w", err)
+	}
+
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(sba.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(sba.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	// avoid collisions with existing backups
+	fullPath, err := storage.ResolveCollision(storageHandler, utils.FullPath(backupPath, outName(sba)), sba.Storage.OnCollision)
+	if err != nil {
+		return err
+	}
+	sba.Storage.OutName = filepath.Base(fullPath)
+
+	// copy the database
+	if err := run(args, nil); err != nil {
+		return err
+	}
+
+	// write the compressed copy to storage
+	if err := writeFile(storageHandler, sba.Storage, copyPath, fullPath); err != nil {
+		return err
+	}
+
+	// dump the copy as a SQL script, rather than the database which may have changed since
+	if sba.Dump {
+		scriptPath := filepath.Join(tempDir, "backup.sql")
+
+		script, err := os.Create(scriptPath)
+		if err != nil {
+			return fmt.Errorf("failed to create SQL script - %w", err)
+		}
+		err = run(dumpArgsBuilder(copyPath), script)
+		_ = script.Close()
+		if err != nil {
+			return err
+		}
+
+		scriptFullPath, err := storage.ResolveCollision(storageHandler, utils.FullPath(backupPath, scriptName(sba.Storage.OutName, sba.Storage.Compression)), sba.Storage.OnCollision)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFile(storageHandler, sba.Storage, scriptPath, scriptFullPath); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Backup complete !\n")
+
+	return nil
+}
+
+// run runs the sqlite3 command, writing its output to stdout when set
+func run(args []string, stdout io.Writer) error {
+	cmd := exec.Command("sqlite3", args...)
+
+	// capture the command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+	cmd.Stdout = stdout
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute sqlite3 command - %w, %s", err, stdErr.String())
+	}
+
+	return nil
+}
+
+// writeFile compresses the file and writes it to the storage
+func writeFile(storageHandler storage.Storage, params *storage.Params, path, resource string) error {
+	input, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup - %w", err)
+	}
+	defer input.Close()
+
+	var data bytes.Buffer
+	out, err := compression.NewWriter(&data, params.Compression, params.CompressionLevel)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, input); err != nil {
+		return fmt.Errorf("failed to compress backup - %w", err)
+	}
+
+	// flush the compressed stream
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup - %w", err)
+	}
+
+	if err := storageHandler.WriteBackup(data.Bytes(), resource); err != nil {
+		return fmt.Errorf("failed to write backup to storage - %w", err)
+	}
+
+	return nil
+}
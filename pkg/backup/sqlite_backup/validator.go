@@ -0,0 +1,26 @@
+package sqlite_backup
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/utils"
+	"strings"
+)
+
+// validateRequiredArgs validates required arguments for SQLite backup
+func validateRequiredArgs(sba *SqliteBackupArgs) error {
+	if sba.Database == "" {
+		return fmt.Errorf("database path is missing")
+	}
+
+	// sqlite3 would read the path as an option
+	if strings.HasPrefix(sba.Database, "-") {
+		return fmt.Errorf("invalid database path: %s", sba.Database)
+	}
+
+	// sqlite3 creates the databases which do not exist
+	if !utils.PathExists(sba.Database) || utils.IsDirectory(sba.Database) {
+		return fmt.Errorf("database file %s does not exist", sba.Database)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package wal_push
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/encryption"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type WalPushArgs struct {
+	Path          string          // path of the WAL file to archive, %p of the archive_command
+	EncryptionKey []byte          // AES-256 key the WAL file is encrypted with before it leaves the host, nil to store it as is
+	Storage       *storage.Params // Storage parameters
+}
+
+// Push archives a WAL file of a PostgresSQL cluster into the storage, compressed with the
+// algorithm of the storage parameters and encrypted with the key when one is provided.
+// It is meant to be used as the archive_command.
+// Archiving the same file again succeeds, as PostgresSQL may retry after a crash, while a
+// different file already archived under the same name is an error.
+func Push(wpa *WalPushArgs) error {
+	name := filepath.Base(wpa.Path)
+	if err := validateRequiredArgs(wpa, name); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(wpa.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read WAL file - %w", err)
+	}
+
+	// compress the WAL file
+	var compressed bytes.Buffer
+	out, err := compression.NewWriter(&compressed, wpa.Storage.Compression, wpa.Storage.CompressionLevel)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(data); err != nil {
+		return fmt.Errorf("failed to compress WAL file - %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to compress WAL file - %w", err)
+	}
+
+	// encrypt the compressed WAL file
+	archive := compressed.Bytes()
+	if wpa.EncryptionKey != nil {
+		if archive, err = encryption.Encrypt(archive, wpa.EncryptionKey); err != nil {
+			return fmt.Errorf("failed to encrypt WAL file - %w", err)
+		}
+	}
+
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(wpa.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(wpa.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	resource := utils.FullPath(backupPath, backup.WalArchiveName(name, wpa.Storage.Compression, wpa.EncryptionKey != nil))
+
+	// the WAL file may have been archived before PostgresSQL recorded it
+	exists, err := storageHandler.Exists(resource)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return checkArchived(storageHandler, resource, data, wpa.Storage.Compression, wpa.EncryptionKey)
+	}
+
+	// write the WAL file to the storage
+	if err := storageHandler.WriteBackup(archive, resource); err != nil {
+		return fmt.Errorf("failed to write WAL file to storage - %w", err)
+	}
+
+	return nil
+}
+
+// checkArchived compares the WAL file with the one already archived under the same name.
+// The archived file is encrypted with a random nonce, so the decrypted content is compared.
+//
+// Returns an error if the archived file differs, or cannot be read.
+func checkArchived(storageHandler storage.Storage, resource string, data []byte, algorithm string, key []byte) error {
+	archived, err := storageHandler.ReadBackup(resource)
+	if err != nil {
+		return err
+	}
+
+	if key != nil {
+		if archived, err = encryption.Decrypt(archived, key); err != nil {
+			return fmt.Errorf("failed to decrypt archived WAL file - %w", err)
+		}
+	}
+
+	reader, err := compression.NewReader(bytes.NewReader(archived), algorithm)
+	if err != nil {
+		return fmt.Errorf("failed to decompress archived WAL file - %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to decompress archived WAL file - %w", err)
+	}
+
+	if !bytes.Equal(content, data) {
+		return fmt.Errorf("WAL file %s is already archived with a different content", filepath.Base(resource))
+	}
+
+	fmt.Printf("WAL file %s is already archived\n", filepath.Base(resource))
+
+	return nil
+}
+
+func validateRequiredArgs(wpa *WalPushArgs, name string) error {
+	if err := backup.ValidateWalFileName(name); err != nil {
+		return err
+	}
+
+	if storage.IsDatedLayout(wpa.Storage.Layout) {
+		return fmt.Errorf("the WAL cannot be archived in a layout depending on the date: %s", wpa.Storage.Layout)
+	}
+
+	return nil
+}
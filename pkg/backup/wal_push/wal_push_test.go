@@ -0,0 +1,85 @@
+package wal_push
+
+import (
+	"bytes"
+	"github.com/denisakp/sentinel/internal/encryption"
+	"github.com/denisakp/sentinel/internal/storage"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPush(t *testing.T) {
+	walDir, archiveDir := t.TempDir(), t.TempDir()
+	segment := filepath.Join(walDir, "000000010000000000000001")
+	params := &storage.Params{StorageType: "local", LocalPath: archiveDir, Compression: "gzip"}
+
+	write := func(data string) {
+		if err := os.WriteFile(segment, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("segment")
+	if err := Push(&WalPushArgs{Path: segment, Storage: params}); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(archiveDir, "000000010000000000000001.gz")); err != nil {
+		t.Fatalf("Push() did not archive the segment - %v", err)
+	}
+
+	// PostgresSQL retries the segments it did not record as archived
+	if err := Push(&WalPushArgs{Path: segment, Storage: params}); err != nil {
+		t.Errorf("Push() of an archived segment error = %v", err)
+	}
+
+	write("another segment")
+	if err := Push(&WalPushArgs{Path: segment, Storage: params}); err == nil {
+		t.Errorf("Push() of a different segment with the same name, error expected")
+	}
+
+	if err := Push(&WalPushArgs{Path: filepath.Join(walDir, "archive_status"), Storage: params}); err == nil {
+		t.Errorf("Push() of an invalid WAL file name, error expected")
+	}
+
+	dated := &storage.Params{StorageType: "local", LocalPath: archiveDir, Layout: "wal/{yyyy}"}
+	if err := Push(&WalPushArgs{Path: segment, Storage: dated}); err == nil {
+		t.Errorf("Push() in a dated layout, error expected")
+	}
+}
+
+func TestPush_encrypted(t *testing.T) {
+	walDir, archiveDir := t.TempDir(), t.TempDir()
+	segment := filepath.Join(walDir, "000000010000000000000001")
+	params := &storage.Params{StorageType: "local", LocalPath: archiveDir, Compression: "gzip"}
+	key := bytes.Repeat([]byte{7}, 32)
+
+	if err := os.WriteFile(segment, []byte("segment"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Push(&WalPushArgs{Path: segment, EncryptionKey: key, Storage: params}); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	archived, err := os.ReadFile(filepath.Join(archiveDir, "000000010000000000000001.gz.enc"))
+	if err != nil {
+		t.Fatalf("Push() did not archive the segment - %v", err)
+	}
+	if _, err := encryption.Decrypt(archived, key); err != nil {
+		t.Errorf("Push() archived a segment not encrypted with the key - %v", err)
+	}
+
+	// encrypted again with another nonce, the content is the same
+	if err := Push(&WalPushArgs{Path: segment, EncryptionKey: key, Storage: params}); err != nil {
+		t.Errorf("Push() of an archived segment error = %v", err)
+	}
+
+	entries, err := os.ReadDir(archiveDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Push() left %d files in the archive directory, want 1", len(entries))
+	}
+}
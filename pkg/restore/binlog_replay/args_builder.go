@@ -0,0 +1,118 @@
+package binlog_replay
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type BinlogReplayArgs struct {
+	Type         string                 // database type, mysql or mariadb
+	Host         string                 // server host
+	Port         string                 // server port
+	Username     string                 // server user
+	Password     string                 // server user password
+	TLS          backup.TLSOptions      // TLS options
+	Database     string                 // database of the dump, the events of the other databases are skipped, every database when empty
+	Target       string                 // database the dump was restored as, the events are rewritten to it when it differs
+	Dir          string                 // directory holding the archived binary logs
+	Position     *backup.BinlogPosition // binary log position the restored dump is consistent with
+	StopDatetime string                 // replay the events until this time, all the events when empty
+}
+
+// binlogTools maps the database types to their binary log tool and their client
+var binlogTools = map[string][2]string{
+	"mysql":   {"mysqlbinlog", "mysql"},
+	"mariadb": {"mariadb-binlog", "mariadb"},
+}
+
+// binlogArgsBuilder builds the arguments of the mysqlbinlog command decoding the archived
+// binary logs of the database of the dump, from the position of the dump on and until the stop time
+func binlogArgsBuilder(bra *BinlogReplayArgs) ([]string, error) {
+	if err := validateRequiredArgs(bra); err != nil {
+		return nil, err
+	}
+
+	files, err := binlogFiles(bra.Dir, bra.Position.File)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{fmt.Sprintf("--start-position=%d", bra.Position.Position)} // only applies to the first binary log
+
+	// only replay the events of the restored database, the rewrite being applied before the filter
+	if bra.Database != "" {
+		database := bra.Database
+		if bra.Target != "" && bra.Target != bra.Database {
+			args = append(args, fmt.Sprintf("--rewrite-db=%s->%s", bra.Database, bra.Target))
+			database = bra.Target
+		}
+		args = append(args, fmt.Sprintf("--database=%s", database))
+	}
+
+	if bra.StopDatetime != "" {
+		stop, err := parseStopDatetime(bra.StopDatetime)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, fmt.Sprintf("--stop-datetime=%s", stop))
+	} // stop at the recovery target
+
+	return append(args, files...), nil
+}
+
+// clientArgsBuilder builds the arguments of the client applying the decoded events
+func clientArgsBuilder(bra *BinlogReplayArgs) []string {
+	bra.Host = utils.DefaultValue(bra.Host, "127.0.0.1") // set the default host to 127.0.0.1 if not provided
+	bra.Port = utils.DefaultValue(bra.Port, "3306")      // set the default port to 3306 if not provided
+
+	args := backup.MySQLHostArgs(bra.Host, bra.Port) // connect through the host and port, or the unix socket
+	args = append(args, fmt.Sprintf("--user=%s", bra.Username))
+
+	if bra.Type == "mariadb" {
+		args = append(args, bra.TLS.MariaDBArgs()...)
+	} else {
+		args = append(args, bra.TLS.MySQLArgs()...)
+
+		if bra.Password == "" {
+			args = append(args, "--skip-password")
+		} // skip password prompt if password is not provided
+	} // add the TLS arguments if provided
+
+	return args
+}
+
+// binlogFiles lists the archived binary logs of the directory from the start file on.
+// The binary logs of the same server share the base name, their numbers being zero-padded.
+//
+// Returns the paths of the binary logs in order, or an error if the start file is missing.
+func binlogFiles(dir, start string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binlog directory - %w", err)
+	}
+
+	base := strings.TrimSuffix(start, filepath.Ext(start))
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.TrimSuffix(name, filepath.Ext(name)) != base || name < start {
+			continue
+		}
+		if _, err := strconv.ParseUint(strings.TrimPrefix(filepath.Ext(name), "."), 10, 64); err != nil {
+			continue
+		} // skip the index file
+		files = append(files, filepath.Join(dir, name))
+	}
+
+	if len(files) == 0 || filepath.Base(files[0]) != start {
+		return nil, fmt.Errorf("binary log %s of the dump is missing from %s", start, dir)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,123 @@
+package binlog_replay
+
+import (
+	"github.com/denisakp/sentinel/internal/backup"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBinlogArgsBuilder(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"binlog.000001", "binlog.000002", "binlog.000003", "other.000002", "binlog.index"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("binlog"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	position := &backup.BinlogPosition{File: "binlog.000002", Position: 157}
+
+	tests := []struct {
+		name    string
+		args    *BinlogReplayArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Unsupported type",
+			args:    &BinlogReplayArgs{Type: "postgres", Username: "root", Dir: dir, Position: position},
+			wantErr: true,
+		},
+		{
+			name:    "Missing binlog position",
+			args:    &BinlogReplayArgs{Type: "mysql", Username: "root", Dir: dir},
+			wantErr: true,
+		},
+		{
+			name:    "Missing start binlog",
+			args:    &BinlogReplayArgs{Type: "mysql", Username: "root", Dir: dir, Position: &backup.BinlogPosition{File: "binlog.000009", Position: 4}},
+			wantErr: true,
+		},
+		{
+			name: "Binlogs from the dump position",
+			args: &BinlogReplayArgs{Type: "mysql", Username: "root", Dir: dir, Position: position},
+			want: []string{"--start-position=157", filepath.Join(dir, "binlog.000002"), filepath.Join(dir, "binlog.000003")},
+		},
+		{
+			name: "Stop datetime",
+			args: &BinlogReplayArgs{Type: "mariadb", Username: "root", Dir: dir, Position: position, StopDatetime: "2024-05-01 10:30:00"},
+			want: []string{"--start-position=157", "--stop-datetime=2024-05-01 10:30:00", filepath.Join(dir, "binlog.000002"), filepath.Join(dir, "binlog.000003")},
+		},
+		{
+			name: "Events of the database of the dump",
+			args: &BinlogReplayArgs{Type: "mysql", Username: "root", Dir: dir, Position: position, Database: "shop", Target: "shop"},
+			want: []string{"--start-position=157", "--database=shop", filepath.Join(dir, "binlog.000002"), filepath.Join(dir, "binlog.000003")},
+		},
+		{
+			name: "Events rewritten to the restored database",
+			args: &BinlogReplayArgs{Type: "mysql", Username: "root", Dir: dir, Position: position, Database: "shop", Target: "shop_copy"},
+			want: []string{"--start-position=157", "--rewrite-db=shop->shop_copy", "--database=shop_copy", filepath.Join(dir, "binlog.000002"), filepath.Join(dir, "binlog.000003")},
+		},
+		{
+			name:    "Invalid stop datetime",
+			args:    &BinlogReplayArgs{Type: "mysql", Username: "root", Dir: dir, Position: position, StopDatetime: "yesterday"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := binlogArgsBuilder(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("binlogArgsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("binlogArgsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientArgsBuilder(t *testing.T) {
+	tests := []struct {
+		name string
+		args *BinlogReplayArgs
+		want []string
+	}{
+		{
+			name: "Default Host and Port",
+			args: &BinlogReplayArgs{Type: "mysql", Username: "root", Password: "root"},
+			want: []string{"--host=127.0.0.1", "--port=3306", "--user=root"},
+		},
+		{
+			name: "Skip password",
+			args: &BinlogReplayArgs{Type: "mysql", Username: "root", Host: "db", Port: "3307"},
+			want: []string{"--host=db", "--port=3307", "--user=root", "--skip-password"},
+		},
+		{
+			name: "MariaDB",
+			args: &BinlogReplayArgs{Type: "mariadb", Username: "root", Host: "db"},
+			want: []string{"--host=db", "--port=3306", "--user=root"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientArgsBuilder(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clientArgsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStopDatetime(t *testing.T) {
+	target := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	got, err := parseStopDatetime(target.Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("parseStopDatetime() error = %v", err)
+	}
+	if want := target.Local().Format(backup.RecoveryTargetLayout); got != want {
+		t.Errorf("parseStopDatetime() got = %v, want %v", got, want)
+	}
+}
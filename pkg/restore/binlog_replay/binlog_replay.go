@@ -0,0 +1,74 @@
+package binlog_replay
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"os/exec"
+)
+
+// Replay replays the archived binary logs of a MySQL or MariaDB server on top of a restored
+// dump, from the binary log position of the dump until the stop time, for a point-in-time recovery
+func Replay(bra *BinlogReplayArgs) error {
+	binlogArgs, err := binlogArgsBuilder(bra)
+	if err != nil {
+		return fmt.Errorf("failed to build binlog replay args - %w", err)
+	}
+	clientArgs := clientArgsBuilder(bra)
+
+	// check database connectivity
+	if ok, err := sql.CheckConnectivity(bra.Type, &sql.Connection{
+		Host:     bra.Host,
+		Port:     bra.Port,
+		User:     bra.Username,
+		Password: bra.Password,
+		TLS:      &bra.TLS,
+	}); !ok {
+		return err
+	}
+
+	// pass the password through a temporary option file
+	if bra.Password != "" {
+		optionFile, cleanup, err := credentials.MySQLOptionFile(bra.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		clientArgs = append([]string{"--defaults-extra-file=" + optionFile}, clientArgs...) // must be the first argument
+	}
+
+	tools := binlogTools[bra.Type]
+	decode := exec.Command(tools[0], binlogArgs...)
+	apply := exec.Command(tools[1], clientArgs...)
+
+	// capture the commands error
+	var decodeErr, applyErr bytes.Buffer
+	decode.Stderr = &decodeErr
+	apply.Stderr = &applyErr
+
+	// pipe the decoded events to the client
+	events, err := decode.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to pipe %s output - %w", tools[0], err)
+	}
+	apply.Stdin = events
+
+	if err := decode.Start(); err != nil {
+		return fmt.Errorf("failed to start %s command - %w", tools[0], err)
+	}
+
+	if err := apply.Run(); err != nil {
+		_ = decode.Process.Kill()
+		_ = decode.Wait()
+		return fmt.Errorf("failed to execute %s command - %w, %s", tools[1], err, applyErr.String())
+	}
+
+	if err := decode.Wait(); err != nil {
+		return fmt.Errorf("failed to execute %s command - %w, %s", tools[0], err, decodeErr.String())
+	}
+
+	fmt.Printf("Binlog replay complete !\n")
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package binlog_replay
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/utils"
+)
+
+// validateRequiredArgs validates required arguments for the binary log replay
+func validateRequiredArgs(bra *BinlogReplayArgs) error {
+	if _, ok := binlogTools[bra.Type]; !ok {
+		return fmt.Errorf("binary log replay is only supported for mysql and mariadb, got: %s", bra.Type)
+	}
+
+	if bra.Username == "" {
+		return fmt.Errorf("username is missing")
+	}
+
+	if bra.Position == nil {
+		return fmt.Errorf("the backup metadata holds no binary log position, it was taken without the binary log enabled")
+	}
+
+	if !utils.IsDirectory(bra.Dir) {
+		return fmt.Errorf("binlog directory %s does not exist", bra.Dir)
+	}
+
+	return nil
+}
+
+// parseStopDatetime parses the recovery target into the mysqlbinlog layout,
+// mysqlbinlog reading it in the local time zone
+func parseStopDatetime(value string) (string, error) {
+	t, err := backup.ParseRecoveryTarget(value)
+	if err != nil {
+		return "", err
+	}
+
+	return t.Local().Format(backup.RecoveryTargetLayout), nil
+}
@@ -0,0 +1,65 @@
+package mariadb_restore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArgsBuilder(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "backup.sql.gz")
+	if err := os.WriteFile(file, []byte("dump"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    *MariaDBRestoreArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Required args missing",
+			args:    &MariaDBRestoreArgs{},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Missing backup file",
+			args:    &MariaDBRestoreArgs{Username: "root", Database: "test", File: file + ".missing"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Default Host and Port",
+			args:    &MariaDBRestoreArgs{Username: "root", Password: "root", Database: "test", File: file},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Skip password",
+			args:    &MariaDBRestoreArgs{Username: "root", Database: "test", Host: "db", Port: "3307", File: file},
+			want:    []string{"--host=db", "--port=3307", "--user=root", "--skip-password", "test"},
+			wantErr: false,
+		},
+		{
+			name:    "Additional Arguments",
+			args:    &MariaDBRestoreArgs{Username: "root", Password: "root", Database: "test", File: file, AdditionalArgs: "--force --port=3306"},
+			want:    []string{"--host=127.0.0.1", "--port=3306", "--user=root", "--force", "test"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := argsBuilder(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("argsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
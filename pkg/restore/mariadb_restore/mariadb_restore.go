@@ -0,0 +1,63 @@
+package mariadb_restore
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"os/exec"
+)
+
+// Restore restores a MariaDB database from a backup made by Sentinel,
+// decompressing it on the fly when it was compressed by Sentinel
+func Restore(mra *MariaDBRestoreArgs) error {
+	args, err := argsBuilder(mra)
+	if err != nil {
+		return fmt.Errorf("failed to build mariadb args - %w", err)
+	}
+
+	// check database connectivity
+	if ok, err := sql.CheckConnectivity("mysql", &sql.Connection{
+		Host:     mra.Host,
+		Port:     mra.Port,
+		User:     mra.Username,
+		Password: mra.Password,
+		Database: mra.Database,
+		TLS:      &mra.TLS,
+	}); !ok {
+		return err
+	}
+
+	// stream the backup file to the mariadb client
+	input, err := compression.OpenFile(mra.File)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	// pass the password through a temporary option file
+	if mra.Password != "" {
+		optionFile, cleanup, err := credentials.MySQLOptionFile(mra.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		args = append([]string{"--defaults-extra-file=" + optionFile}, args...) // must be the first argument
+	}
+
+	cmd := exec.Command("mariadb", args...)
+	cmd.Stdin = input
+
+	// capture command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute mariadb command - %w, %s", err, stdErr.String())
+	}
+
+	fmt.Printf("Restore complete !\n")
+
+	return nil
+}
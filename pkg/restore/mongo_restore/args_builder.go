@@ -0,0 +1,110 @@
+package mongo_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"github.com/denisakp/sentinel/internal/utils"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type MongoRestoreArgs struct {
+	Uri            string            // MongoDB URI
+	TLS            backup.TLSOptions // TLS options
+	File           string            // Backup directory, or archive, to restore
+	OplogDir       string            // directory of the oplog slices replayed after the dump
+	StopDatetime   string            // replay the oplog slices until this time, all the operations when empty
+	AdditionalArgs string            // Additional arguments for the mongorestore command
+}
+
+func argsBuilder(ra *MongoRestoreArgs) ([]string, error) {
+	if err := validateRequiredArgs(ra); err != nil {
+		return nil, err
+	}
+
+	// set default values
+	ra.Uri = utils.DefaultValue(ra.Uri, "mongodb://localhost:27017")
+
+	// archives are read from the standard input, decompressed by Sentinel when it compressed them
+	input := fmt.Sprintf("--dir=%s", ra.File)
+	if isArchive(ra.File) {
+		input = "--archive"
+	}
+
+	args := []string{
+		fmt.Sprintf("--uri=%s", ra.Uri),
+		input,
+		"--quiet",
+	}
+	args = append(args, ra.TLS.MongoArgs()...) // add the TLS arguments if provided
+
+	if isArchive(ra.File) {
+		// archives made with --compress hold gzip collections
+		if mongo.IsGzipArchive(ra.File) {
+			args = append(args, "--gzip")
+		}
+
+		// archives of replica sets hold the oplog captured during the dump, as recorded in their metadata
+		if archiveHasOplog(ra.File) {
+			args = append(args, "--oplogReplay")
+		}
+	} else {
+		// dumps made with --compress hold gzip files
+		if hasGzipFiles(ra.File) {
+			args = append(args, "--gzip")
+		}
+
+		// dumps of replica sets hold the oplog captured during the dump
+		if utils.PathExists(filepath.Join(ra.File, "oplog.bson")) || utils.PathExists(filepath.Join(ra.File, "oplog.bson.gz")) {
+			args = append(args, "--oplogReplay")
+		}
+	}
+
+	if ra.AdditionalArgs != "" {
+		additionalArgs := backup.ParseAdditionalArgs(ra.AdditionalArgs)
+		args = append(args, additionalArgs...)
+	}
+
+	args = backup.RemoveArgsDuplicate(args) // remove duplicate arguments
+
+	return args, nil
+}
+
+// isArchive reports whether the backup is an archive made in the archive mode, rather than a directory
+func isArchive(file string) bool {
+	info, err := os.Stat(file)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// archiveHasOplog reports whether the metadata stored next to the archive records an oplog position
+func archiveHasOplog(file string) bool {
+	data, err := os.ReadFile(file + backup.MetadataSuffix)
+	if err != nil {
+		return false
+	}
+
+	metadata, err := backup.ParseMetadata(data)
+	return err == nil && metadata.Oplog != nil
+}
+
+// hasGzipFiles checks if the dump directory holds files compressed by mongodump --gzip
+func hasGzipFiles(dir string) bool {
+	found := false
+
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || found {
+			return fs.SkipAll
+		}
+
+		if !entry.IsDir() && filepath.Ext(path) == ".gz" {
+			found = true
+			return fs.SkipAll
+		}
+
+		return nil
+	})
+
+	return found
+}
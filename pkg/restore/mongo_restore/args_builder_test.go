@@ -0,0 +1,131 @@
+package mongo_restore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_argsBuilder(t *testing.T) {
+	plain := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(plain, "app"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(plain, "app", "users.bson"), []byte("bson"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	gzipped := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(gzipped, "app"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gzipped, "app", "users.bson.gz"), []byte("bson"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	withOplog := t.TempDir()
+	if err := os.WriteFile(filepath.Join(withOplog, "oplog.bson"), []byte("bson"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "test.archive.gz")
+	if err := os.WriteFile(archive, []byte("archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	gzipArchive := filepath.Join(t.TempDir(), "test.gz.archive")
+	if err := os.WriteFile(gzipArchive, []byte("\x6d\xe2\x99\x81archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	olderGzipArchive := filepath.Join(t.TempDir(), "test.archive.gz")
+	if err := os.WriteFile(olderGzipArchive, []byte("\x6d\xe2\x99\x81archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	archiveWithOplog := filepath.Join(t.TempDir(), "test.archive")
+	if err := os.WriteFile(archiveWithOplog, []byte("archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archiveWithOplog+".meta.json", []byte(`{"oplog":{"t":1714521600,"i":1}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    *MongoRestoreArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Missing directory - error expected",
+			args:    &MongoRestoreArgs{File: filepath.Join(plain, "missing")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Default URI",
+			args:    &MongoRestoreArgs{File: plain},
+			want:    []string{"--uri=mongodb://localhost:27017", "--dir=" + plain, "--quiet"},
+			wantErr: false,
+		},
+		{
+			name:    "Compressed dump",
+			args:    &MongoRestoreArgs{Uri: "mongodb://db:27017", File: gzipped},
+			want:    []string{"--uri=mongodb://db:27017", "--dir=" + gzipped, "--quiet", "--gzip"},
+			wantErr: false,
+		},
+		{
+			name:    "Dump with its oplog",
+			args:    &MongoRestoreArgs{File: withOplog},
+			want:    []string{"--uri=mongodb://localhost:27017", "--dir=" + withOplog, "--quiet", "--oplogReplay"},
+			wantErr: false,
+		},
+		{
+			name:    "Archive",
+			args:    &MongoRestoreArgs{File: archive},
+			want:    []string{"--uri=mongodb://localhost:27017", "--archive", "--quiet"},
+			wantErr: false,
+		},
+		{
+			name:    "Archive compressed by mongodump",
+			args:    &MongoRestoreArgs{File: gzipArchive},
+			want:    []string{"--uri=mongodb://localhost:27017", "--archive", "--quiet", "--gzip"},
+			wantErr: false,
+		},
+		{
+			name:    "Archive compressed by mongodump, named like a gzip file",
+			args:    &MongoRestoreArgs{File: olderGzipArchive},
+			want:    []string{"--uri=mongodb://localhost:27017", "--archive", "--quiet", "--gzip"},
+			wantErr: false,
+		},
+		{
+			name:    "Archive with its oplog",
+			args:    &MongoRestoreArgs{File: archiveWithOplog},
+			want:    []string{"--uri=mongodb://localhost:27017", "--archive", "--quiet", "--oplogReplay"},
+			wantErr: false,
+		},
+		{
+			name:    "Missing oplog directory - error expected",
+			args:    &MongoRestoreArgs{File: plain, OplogDir: filepath.Join(plain, "missing")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Additional arguments",
+			args:    &MongoRestoreArgs{File: plain, AdditionalArgs: "--drop --quiet"},
+			want:    []string{"--uri=mongodb://localhost:27017", "--dir=" + plain, "--quiet", "--drop"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := argsBuilder(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("argsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
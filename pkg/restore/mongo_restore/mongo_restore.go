@@ -0,0 +1,50 @@
+package mongo_restore
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup/mongo"
+	"os/exec"
+)
+
+// Restore restores a MongoDB database from a backup made by Sentinel using mongorestore
+func Restore(ra *MongoRestoreArgs) error {
+	args, err := argsBuilder(ra) // build mongorestore arguments
+	if err != nil {
+		return fmt.Errorf("failed to build mongorestore arguments: %w", err)
+	}
+
+	// check connectivity
+	if _, err := mongo.CheckConnectivity(ra.Uri, &ra.TLS); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("mongorestore", args...) // run mongorestore command
+
+	// capture the command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	// stream the archive, decompressed on the fly when Sentinel compressed it
+	if isArchive(ra.File) {
+		archive, err := mongo.OpenArchive(ra.File)
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		cmd.Stdin = archive
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run mongorestore: %w, %s", err, stdErr.String())
+	}
+
+	fmt.Printf("Restore complete !\n")
+
+	// replay the oplog slices archived since the dump, up to the recovery target
+	if ra.OplogDir != "" {
+		return replayOplog(ra)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package mongo_restore
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// oplogReplayArgs builds the arguments of the mongorestore command replaying the oplog
+// slice written as oplog.bson in the directory, up to the recovery target if provided
+func oplogReplayArgs(ra *MongoRestoreArgs, dir string) ([]string, error) {
+	args := []string{
+		fmt.Sprintf("--uri=%s", ra.Uri),
+		fmt.Sprintf("--dir=%s", dir),
+		"--quiet",
+		"--oplogReplay",
+	}
+	args = append(args, ra.TLS.MongoArgs()...) // add the TLS arguments if provided
+
+	if ra.StopDatetime != "" {
+		stop, err := backup.ParseRecoveryTarget(ra.StopDatetime)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, fmt.Sprintf("--oplogLimit=%d", stop.Unix()))
+	} // replay the operations until the recovery target
+
+	return args, nil
+}
+
+// oplogSlices lists the oplog slices of the directory, in order
+func oplogSlices(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oplog directory: %w", err)
+	}
+
+	var slices []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasPrefix(entry.Name(), backup.OplogSlicePrefix) &&
+			filepath.Ext(compression.TrimExtension(entry.Name())) == ".bson" {
+			slices = append(slices, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(slices) // the slice names start with the zero-padded position of their first entry
+
+	if len(slices) == 0 {
+		return nil, fmt.Errorf("no oplog slice found in %s", dir)
+	}
+
+	return slices, nil
+}
+
+// replayOplog replays the oplog slices archived since the dump with mongorestore --oplogReplay,
+// each slice being decompressed as the oplog.bson file of an otherwise empty dump directory
+func replayOplog(ra *MongoRestoreArgs) error {
+	slices, err := oplogSlices(ra.OplogDir)
+	if err != nil {
+		return err
+	}
+
+	for _, slice := range slices {
+		if err := replayOplogSlice(ra, slice); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Oplog replay complete !\n")
+
+	return nil
+}
+
+func replayOplogSlice(ra *MongoRestoreArgs, slice string) error {
+	dir, err := os.MkdirTemp("", "sentinel-oplog-*")
+	if err != nil {
+		return fmt.Errorf("failed to create oplog directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := decompressFile(slice, filepath.Join(dir, "oplog.bson")); err != nil {
+		return err
+	}
+
+	args, err := oplogReplayArgs(ra, dir)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("mongorestore", args...) // run mongorestore command
+
+	// capture the command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to replay oplog slice %s: %w, %s", filepath.Base(slice), err, stdErr.String())
+	}
+
+	return nil
+}
+
+// decompressFile decompresses the file to the destination, based on its extension
+func decompressFile(source, destination string) error {
+	reader, err := compression.OpenFile(source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if _, err := io.Copy(file, reader); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to decompress %s: %w", filepath.Base(source), err)
+	}
+
+	return file.Close()
+}
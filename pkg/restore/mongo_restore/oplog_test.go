@@ -0,0 +1,79 @@
+package mongo_restore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_oplogSlices(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"oplog_1714521660-0000000001_1714521720-0000000003.bson.gz",
+		"oplog_1714521600-0000000001_1714521660-0000000000.bson.gz",
+		"oplog_1714521600-0000000001_1714521660-0000000000.bson.gz.meta.json",
+		"SENTINEL_2024-05-01T00-00-00",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("bson"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := oplogSlices(dir)
+	if err != nil {
+		t.Fatalf("oplogSlices() error = %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "oplog_1714521600-0000000001_1714521660-0000000000.bson.gz"),
+		filepath.Join(dir, "oplog_1714521660-0000000001_1714521720-0000000003.bson.gz"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("oplogSlices() got = %v, want %v", got, want)
+	}
+
+	if _, err := oplogSlices(t.TempDir()); err == nil {
+		t.Errorf("oplogSlices() of an empty directory, error expected")
+	}
+}
+
+func Test_oplogReplayArgs(t *testing.T) {
+	target := time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		args    *MongoRestoreArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "All the operations",
+			args: &MongoRestoreArgs{Uri: "mongodb://db:27017"},
+			want: []string{"--uri=mongodb://db:27017", "--dir=/tmp/slice", "--quiet", "--oplogReplay"},
+		},
+		{
+			name: "Until the recovery target",
+			args: &MongoRestoreArgs{Uri: "mongodb://db:27017", StopDatetime: target.Format(time.RFC3339)},
+			want: []string{"--uri=mongodb://db:27017", "--dir=/tmp/slice", "--quiet", "--oplogReplay", "--oplogLimit=1714573800"},
+		},
+		{
+			name:    "Invalid recovery target - error expected",
+			args:    &MongoRestoreArgs{Uri: "mongodb://db:27017", StopDatetime: "yesterday"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oplogReplayArgs(tt.args, "/tmp/slice")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("oplogReplayArgs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("oplogReplayArgs() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
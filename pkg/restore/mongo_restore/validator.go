@@ -0,0 +1,22 @@
+package mongo_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/utils"
+)
+
+func validateRequiredArgs(ra *MongoRestoreArgs) error {
+	if ra.File == "" {
+		return fmt.Errorf("backup directory or archive is missing")
+	}
+
+	if !utils.PathExists(ra.File) {
+		return fmt.Errorf("backup directory or archive %s does not exist", ra.File)
+	}
+
+	if ra.OplogDir != "" && !utils.IsDirectory(ra.OplogDir) {
+		return fmt.Errorf("oplog directory %s does not exist", ra.OplogDir)
+	}
+
+	return nil
+}
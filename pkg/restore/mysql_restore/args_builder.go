@@ -0,0 +1,46 @@
+package mysql_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/utils"
+)
+
+type MySqlRestoreArgs struct {
+	Host           string            // MySQL host
+	Port           string            // MySQL port
+	Username       string            // MySQL username
+	Password       string            // MySQL password
+	Database       string            // MySQL database name
+	TLS            backup.TLSOptions // TLS options
+	File           string            // Backup file to restore
+	AdditionalArgs string            // Additional arguments for the mysql command
+}
+
+// argsBuilder builds the arguments for the mysql command
+func argsBuilder(mra *MySqlRestoreArgs) ([]string, error) {
+	if err := validateRequiredArgs(mra); err != nil {
+		return nil, err
+	}
+
+	mra.Host = utils.DefaultValue(mra.Host, "127.0.0.1") // set the default host to 127.0.0.1 if not provided
+	mra.Port = utils.DefaultValue(mra.Port, "3306")      // set the default port to 3306 if not provided
+
+	args := backup.MySQLHostArgs(mra.Host, mra.Port) // connect through the host and port, or the unix socket
+	args = append(args, fmt.Sprintf("--user=%s", mra.Username))
+	args = append(args, mra.TLS.MySQLArgs()...) // add the TLS arguments if provided
+
+	if mra.Password == "" {
+		args = append(args, "--skip-password")
+	} // skip password prompt if password is not provided
+
+	if mra.AdditionalArgs != "" {
+		additionalArgs := backup.ParseAdditionalArgs(mra.AdditionalArgs)
+		args = append(args, additionalArgs...)
+	} // handle additional arguments
+
+	args = backup.RemoveArgsDuplicate(args) // remove duplicate arguments
+	args = append(args, mra.Database)       // add database name
+
+	return args, nil
+}
@@ -0,0 +1,27 @@
+package mysql_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/utils"
+)
+
+// validateRequiredArgs validates required arguments for MySQL restore
+func validateRequiredArgs(mra *MySqlRestoreArgs) error {
+	if mra.Database == "" {
+		return fmt.Errorf("database name is missing")
+	}
+
+	if mra.Username == "" {
+		return fmt.Errorf("username is missing")
+	}
+
+	if mra.File == "" {
+		return fmt.Errorf("backup file is missing")
+	}
+
+	if !utils.PathExists(mra.File) || utils.IsDirectory(mra.File) {
+		return fmt.Errorf("backup file %s does not exist", mra.File)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package pg_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/utils"
+	"path/filepath"
+)
+
+type PgRestoreArgs struct {
+	Host           string            // PostgresSQL host
+	Port           string            // PostgresSQL port
+	Username       string            // PostgresSQL username
+	Password       string            // PostgresSQL password
+	Database       string            // PostgresSQL database name
+	TLS            backup.TLSOptions // TLS options
+	File           string            // Backup file or directory to restore
+	GlobalsFile    string            // Globals backup restored before the database, optional
+	AdditionalArgs string            // Additional arguments for the psql or pg_restore command
+	CrdbBackupURI  string            // CockroachDB backup collection, when its credentials were redacted from the metadata
+}
+
+// dumpFormat returns the pg_dump format of the backup, based on its extension
+// once the Sentinel compression extension is removed
+func dumpFormat(file string) (string, error) {
+	if utils.IsDirectory(file) {
+		return "d", nil
+	}
+
+	switch filepath.Ext(compression.TrimExtension(file)) {
+	case ".sql":
+		return "p", nil
+	case ".backup", ".dump":
+		return "c", nil
+	case ".tar":
+		return "t", nil
+	default:
+		return "", fmt.Errorf("unable to detect the format of %s", filepath.Base(file))
+	}
+}
+
+// argsBuilder builds the arguments of the restore command. Plain dumps are
+// replayed with psql, the other formats are restored with pg_restore.
+//
+// Returns the command to run and its arguments.
+func argsBuilder(pra *PgRestoreArgs) (string, []string, error) {
+	if err := validateRequiredArgs(pra); err != nil {
+		return "", nil, err
+	}
+
+	pra.Host = utils.DefaultValue(pra.Host, "127.0.0.1")
+	pra.Port = utils.DefaultValue(pra.Port, "5432")
+
+	format, err := dumpFormat(pra.File)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := []string{
+		fmt.Sprintf("--host=%s", pra.TLS.PgHost(pra.Host)),
+		fmt.Sprintf("--port=%s", pra.Port),
+		fmt.Sprintf("--username=%s", pra.Username),
+		fmt.Sprintf("--dbname=%s", pra.TLS.PgConnString(pra.Database, pra.Host)),
+	}
+
+	command := "pg_restore"
+	if format == "p" {
+		command = "psql"
+		args = append(args, "--set=ON_ERROR_STOP=1", "--quiet")
+	} else {
+		args = append(args, fmt.Sprintf("--format=%s", format))
+	}
+
+	// handle additional arguments
+	if pra.AdditionalArgs != "" {
+		args = append(args, backup.ParseAdditionalArgs(pra.AdditionalArgs)...)
+	}
+
+	args = backup.RemoveArgsDuplicate(args) // remove duplicated arguments
+
+	// directory dumps are read by pg_restore itself, other formats are streamed on stdin
+	if format == "d" {
+		args = append(args, pra.File)
+	}
+
+	return command, args, nil
+}
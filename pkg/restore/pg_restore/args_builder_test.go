@@ -0,0 +1,126 @@
+package pg_restore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArgsBuilder(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"plain.sql", "plain.sql.gz", "custom.backup", "archive.tar.zst", "unknown.bin"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("dump"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "directory"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		args        *PgRestoreArgs
+		wantCommand string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:    "Missing file - error expected",
+			args:    &PgRestoreArgs{Username: "test", Database: "test", File: filepath.Join(dir, "missing.sql")},
+			wantErr: true,
+		},
+		{
+			name:        "Plain dump with psql",
+			args:        &PgRestoreArgs{Username: "test", Database: "test", File: filepath.Join(dir, "plain.sql")},
+			wantCommand: "psql",
+			want:        []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--set=ON_ERROR_STOP=1", "--quiet"},
+		},
+		{
+			name:        "Compressed plain dump with psql",
+			args:        &PgRestoreArgs{Host: "db", Port: "5433", Username: "test", Database: "test", File: filepath.Join(dir, "plain.sql.gz")},
+			wantCommand: "psql",
+			want:        []string{"--host=db", "--port=5433", "--username=test", "--dbname=test", "--set=ON_ERROR_STOP=1", "--quiet"},
+		},
+		{
+			name:        "Custom dump with pg_restore",
+			args:        &PgRestoreArgs{Username: "test", Database: "test", File: filepath.Join(dir, "custom.backup"), AdditionalArgs: "--clean --if-exists"},
+			wantCommand: "pg_restore",
+			want:        []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=c", "--clean", "--if-exists"},
+		},
+		{
+			name:        "Compressed tar dump with pg_restore",
+			args:        &PgRestoreArgs{Username: "test", Database: "test", File: filepath.Join(dir, "archive.tar.zst")},
+			wantCommand: "pg_restore",
+			want:        []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=t"},
+		},
+		{
+			name:        "Directory dump with pg_restore",
+			args:        &PgRestoreArgs{Username: "test", Database: "test", File: filepath.Join(dir, "directory")},
+			wantCommand: "pg_restore",
+			want:        []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=test", "--format=d", filepath.Join(dir, "directory")},
+		},
+		{
+			name:    "Unknown format - error expected",
+			args:    &PgRestoreArgs{Username: "test", Database: "test", File: filepath.Join(dir, "unknown.bin")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, got, err := argsBuilder(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if command != tt.wantCommand {
+				t.Errorf("argsBuilder() command = %v, want %v", command, tt.wantCommand)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("argsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobalsArgsBuilder(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "globals.sql.gz"), []byte("dump"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    *PgRestoreArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Globals replayed on the maintenance database",
+			args: &PgRestoreArgs{Username: "test", Database: "test", GlobalsFile: filepath.Join(dir, "globals.sql.gz")},
+			want: []string{"--host=127.0.0.1", "--port=5432", "--username=test", "--dbname=postgres", "--quiet"},
+		},
+		{
+			name:    "Missing globals file - error expected",
+			args:    &PgRestoreArgs{Username: "test", Database: "test", GlobalsFile: filepath.Join(dir, "missing.sql")},
+			wantErr: true,
+		},
+		{
+			name:    "Directory globals file - error expected",
+			args:    &PgRestoreArgs{Username: "test", Database: "test", GlobalsFile: dir},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := globalsArgsBuilder(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("globalsArgsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("globalsArgsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
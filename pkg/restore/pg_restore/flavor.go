@@ -0,0 +1,61 @@
+package pg_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
+	"strings"
+)
+
+// readMetadata reads the metadata of the backup: the file itself when it is a metadata
+// file, as written for CockroachDB backups, or the metadata file stored next to it.
+//
+// Returns nil when the backup has no metadata, e.g. when it was not downloaded with it.
+func readMetadata(file string) (*backup.Metadata, error) {
+	path := file
+	if !strings.HasSuffix(file, backup.MetadataSuffix) {
+		path = file + backup.MetadataSuffix
+		if !utils.PathExists(path) {
+			return nil, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup metadata - %w", err)
+	}
+
+	return backup.ParseMetadata(data)
+}
+
+// restoreCockroach restores a CockroachDB backup from its backup collection with RESTORE,
+// the database restored being renamed when it differs from the backed up database
+func restoreCockroach(pra *PgRestoreArgs, metadata *backup.Metadata) error {
+	if err := validateRequiredArgs(pra); err != nil {
+		return fmt.Errorf("failed to build restore args - %w", err)
+	}
+
+	// the credentials of the collection were redacted from the metadata
+	collection := utils.DefaultValue(pra.CrdbBackupURI, metadata.Cockroach.Collection)
+	if strings.Contains(collection, "redacted") || strings.Contains(collection, "xxxxx") {
+		return fmt.Errorf("the credentials of the backup collection %s were redacted, provide its URI", collection)
+	}
+
+	err := sql.CockroachRestore(&sql.Connection{
+		Host:     pra.Host,
+		Port:     pra.Port,
+		User:     pra.Username,
+		Password: pra.Password,
+		Database: pra.Database,
+		TLS:      &pra.TLS,
+	}, metadata.Database, collection, metadata.Cockroach.Path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restore complete !\n")
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package pg_restore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadMetadata(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"dump.backup":             "dump",
+		"dump.backup.meta.json":   `{"engine":"postgres","flavor":"timescaledb","database":"app","backup":"dump.backup"}`,
+		"bare.backup":             "dump",
+		"crdb.meta.json":          `{"engine":"postgres","flavor":"cockroachdb","database":"app","backup":"/2024/05/01-020000.00","cockroach":{"collection":"s3://backups/crdb","path":"/2024/05/01-020000.00"}}`,
+		"broken.backup":           "dump",
+		"broken.backup.meta.json": "{",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		file          string
+		wantFlavor    string
+		wantCockroach bool
+		wantNil       bool
+		wantErr       bool
+	}{
+		{name: "Metadata next to the dump", file: "dump.backup", wantFlavor: "timescaledb"},
+		{name: "Dump without metadata", file: "bare.backup", wantNil: true},
+		{name: "CockroachDB metadata file", file: "crdb.meta.json", wantFlavor: "cockroachdb", wantCockroach: true},
+		{name: "Invalid metadata - error expected", file: "broken.backup", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readMetadata(filepath.Join(dir, tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("readMetadata() got = %v, wantNil %v", got, tt.wantNil)
+			}
+			if got == nil {
+				return
+			}
+			if got.Flavor != tt.wantFlavor {
+				t.Errorf("readMetadata() flavor = %v, want %v", got.Flavor, tt.wantFlavor)
+			}
+			if (got.Cockroach != nil) != tt.wantCockroach {
+				t.Errorf("readMetadata() cockroach = %v, want %v", got.Cockroach, tt.wantCockroach)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package pg_restore
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os"
+	"os/exec"
+)
+
+// globalsArgsBuilder builds the psql arguments replaying the globals backup on the maintenance
+// database. Errors do not stop the replay, as existing roles such as the restoring user
+// are reported as errors by the CREATE ROLE statements.
+func globalsArgsBuilder(pra *PgRestoreArgs) ([]string, error) {
+	if !utils.PathExists(pra.GlobalsFile) {
+		return nil, fmt.Errorf("globals file %s does not exist", pra.GlobalsFile)
+	}
+
+	if utils.IsDirectory(pra.GlobalsFile) {
+		return nil, fmt.Errorf("globals file %s must be a plain SQL file", pra.GlobalsFile)
+	}
+
+	return []string{
+		fmt.Sprintf("--host=%s", pra.TLS.PgHost(utils.DefaultValue(pra.Host, "127.0.0.1"))),
+		fmt.Sprintf("--port=%s", utils.DefaultValue(pra.Port, "5432")),
+		fmt.Sprintf("--username=%s", pra.Username),
+		fmt.Sprintf("--dbname=%s", pra.TLS.PgConnString("postgres", utils.DefaultValue(pra.Host, "127.0.0.1"))),
+		"--quiet",
+	}, nil
+}
+
+// restoreGlobals replays the roles and tablespaces of the globals backup with psql,
+// the errors being printed as warnings.
+func restoreGlobals(pra *PgRestoreArgs, env []string) error {
+	args, err := globalsArgsBuilder(pra)
+	if err != nil {
+		return err
+	}
+
+	input, err := compression.OpenFile(pra.GlobalsFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	cmd := exec.Command("psql", args...)
+	cmd.Stdin = input
+	cmd.Env = env
+
+	// capture the command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute psql command - %w, %s", err, stdErr.String())
+	}
+
+	if stdErr.Len() > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: globals restored with errors:\n%s", stdErr.String())
+	}
+
+	fmt.Printf("Globals restore complete !\n")
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package pg_restore
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/backup/sql"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/credentials"
+	"github.com/denisakp/sentinel/internal/utils"
+	"os/exec"
+)
+
+// Restore restores a PostgresSQL database from a backup made by Sentinel,
+// decompressing it on the fly when it was compressed by Sentinel. CockroachDB
+// backups are restored from their backup collection, and TimescaleDB databases
+// are restored between the restore hooks of the extension.
+func Restore(pra *PgRestoreArgs) error {
+	// the metadata of the backup tells its flavor
+	metadata, err := readMetadata(pra.File)
+	if err != nil {
+		return err
+	}
+
+	if metadata != nil && metadata.Cockroach != nil {
+		return restoreCockroach(pra, metadata)
+	}
+
+	command, args, err := argsBuilder(pra)
+	if err != nil {
+		return fmt.Errorf("failed to build restore args - %w", err)
+	}
+
+	conn := &sql.Connection{
+		Host:     pra.Host,
+		Port:     pra.Port,
+		User:     pra.Username,
+		Password: pra.Password,
+		Database: pra.Database,
+		TLS:      &pra.TLS,
+	}
+
+	// check connectivity and detect the flavor of the server
+	server, err := sql.InspectPostgres(conn)
+	if err != nil {
+		return err
+	}
+	timescale := server.Flavor == backup.FlavorTimescaleDB || (metadata != nil && metadata.Flavor == backup.FlavorTimescaleDB)
+
+	// pass the password through a temporary password file, keeping the inherited environment
+	env := credentials.Environ()
+	if pra.Password != "" {
+		passFile, cleanup, err := credentials.PgPassFile(pra.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		env = credentials.Environ("PGPASSFILE=" + passFile)
+	}
+
+	// the roles and tablespaces must exist before the database objects referencing them
+	if pra.GlobalsFile != "" {
+		if err := restoreGlobals(pra, env); err != nil {
+			return err
+		}
+	}
+
+	// TimescaleDB must be put in restoring mode, its catalog being restored like any table
+	if timescale {
+		if err := sql.ExecPostgres(conn, "CREATE EXTENSION IF NOT EXISTS timescaledb", "SELECT timescaledb_pre_restore()"); err != nil {
+			return err
+		}
+	}
+
+	cmd := exec.Command(command, args...)
+	cmd.Env = env
+
+	// stream the backup file to the command, directory dumps are read by pg_restore itself
+	if !utils.IsDirectory(pra.File) {
+		input, err := compression.OpenFile(pra.File)
+		if err != nil {
+			return err
+		}
+		defer input.Close()
+		cmd.Stdin = input
+	}
+
+	// capture the command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	err = cmd.Run()
+
+	// leave the restoring mode, even when the restore failed
+	if timescale {
+		if postErr := sql.ExecPostgres(conn, "SELECT timescaledb_post_restore()"); postErr != nil && err == nil {
+			return postErr
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to execute %s command - %w, %s", command, err, stdErr.String())
+	}
+
+	fmt.Printf("Restore complete !\n")
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package pg_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/utils"
+)
+
+func validateRequiredArgs(pra *PgRestoreArgs) error {
+	if pra.Database == "" {
+		return fmt.Errorf("database name is required")
+	}
+
+	if pra.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+
+	if pra.File == "" {
+		return fmt.Errorf("backup file is required")
+	}
+
+	if !utils.PathExists(pra.File) {
+		return fmt.Errorf("backup file %s does not exist", pra.File)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package pg_restore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_validateRequiredArgs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "backup.sql")
+	if err := os.WriteFile(file, []byte("dump"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    *PgRestoreArgs
+		wantErr bool
+	}{
+		{
+			name:    "Missing required args",
+			args:    &PgRestoreArgs{},
+			wantErr: true,
+		},
+		{
+			name:    "Missing database name",
+			args:    &PgRestoreArgs{Username: "test", File: file},
+			wantErr: true,
+		},
+		{
+			name:    "Missing username",
+			args:    &PgRestoreArgs{Database: "test", File: file},
+			wantErr: true,
+		},
+		{
+			name:    "Missing file",
+			args:    &PgRestoreArgs{Username: "test", Database: "test"},
+			wantErr: true,
+		},
+		{
+			name:    "Valid args",
+			args:    &PgRestoreArgs{Username: "test", Database: "test", File: file},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRequiredArgs(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("validateRequiredArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
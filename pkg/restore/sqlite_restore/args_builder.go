@@ -0,0 +1,44 @@
+package sqlite_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"path/filepath"
+)
+
+type SqliteRestoreArgs struct {
+	Database       string // path of the SQLite database restored, created when it does not exist
+	File           string // Backup file to restore, a copy of the database or a SQL script
+	AdditionalArgs string // Additional arguments for the sqlite3 command
+}
+
+// argsBuilder builds the arguments of the sqlite3 command restoring the database.
+// SQL scripts are read from the standard input, copies are restored with the backup API
+// from the copy path.
+func argsBuilder(sra *SqliteRestoreArgs, copyPath string) ([]string, error) {
+	if err := validateRequiredArgs(sra); err != nil {
+		return nil, err
+	}
+
+	args := []string{"-bail"}
+
+	// handle additional arguments, the sqlite3 options come before the database
+	if sra.AdditionalArgs != "" {
+		args = append(args, backup.ParseAdditionalArgs(sra.AdditionalArgs)...)
+	}
+
+	args = backup.RemoveArgsDuplicate(args)
+	args = append(args, sra.Database)
+
+	if !isScript(sra.File) {
+		args = append(args, fmt.Sprintf(".restore '%s'", copyPath))
+	}
+
+	return args, nil
+}
+
+// isScript reports whether the backup is a SQL script rather than a copy of the database
+func isScript(file string) bool {
+	return filepath.Ext(compression.TrimExtension(file)) == ".sql"
+}
@@ -0,0 +1,59 @@
+package sqlite_restore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArgsBuilder(t *testing.T) {
+	dir := t.TempDir()
+	copyFile := filepath.Join(dir, "app.sqlite.gz")
+	script := filepath.Join(dir, "app.sql.zst")
+	for _, file := range []string{copyFile, script} {
+		if err := os.WriteFile(file, []byte("backup"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		args    *SqliteRestoreArgs
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Required args missing",
+			args:    &SqliteRestoreArgs{},
+			wantErr: true,
+		},
+		{
+			name:    "Missing backup file",
+			args:    &SqliteRestoreArgs{Database: "app.db", File: copyFile + ".missing"},
+			wantErr: true,
+		},
+		{
+			name: "Copy of the database",
+			args: &SqliteRestoreArgs{Database: "app.db", File: copyFile},
+			want: []string{"-bail", "app.db", ".restore '/tmp/copy.sqlite'"},
+		},
+		{
+			name: "SQL script",
+			args: &SqliteRestoreArgs{Database: "app.db", File: script, AdditionalArgs: "-echo"},
+			want: []string{"-bail", "-echo", "app.db"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := argsBuilder(tt.args, "/tmp/copy.sqlite")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("argsBuilder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("argsBuilder() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
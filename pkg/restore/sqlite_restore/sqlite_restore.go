@@ -0,0 +1,72 @@
+package sqlite_restore
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/compression"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Restore restores a SQLite database from a backup made by Sentinel, decompressing it
+// when it was compressed by Sentinel. Copies of the database replace its content,
+// SQL scripts are run against it.
+func Restore(sra *SqliteRestoreArgs) error {
+	// the backup API reads the copy from a file, decompressed to a temporary directory
+	tempDir, err := os.MkdirTemp("", "sentinel-sqlite-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory - %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	copyPath := filepath.Join(tempDir, "backup.sqlite")
+
+	args, err := argsBuilder(sra, copyPath)
+	if err != nil {
+		return fmt.Errorf("failed to build sqlite3 args - %w", err)
+	}
+
+	// stream the backup file, the SQL script to the standard input or the copy to the temporary file
+	input, err := compression.OpenFile(sra.File)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	cmd := exec.Command("sqlite3", args...)
+
+	if isScript(sra.File) {
+		cmd.Stdin = input
+	} else if err := writeCopy(input, copyPath); err != nil {
+		return err
+	}
+
+	// capture the command error
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute sqlite3 command - %w, %s", err, stdErr.String())
+	}
+
+	fmt.Printf("Restore complete !\n")
+
+	return nil
+}
+
+// writeCopy writes the decompressed copy of the database to the path
+func writeCopy(input io.Reader, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file - %w", err)
+	}
+
+	if _, err := io.Copy(file, input); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to decompress backup - %w", err)
+	}
+
+	return file.Close()
+}
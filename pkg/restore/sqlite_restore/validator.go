@@ -0,0 +1,29 @@
+package sqlite_restore
+
+import (
+	"fmt"
+	"github.com/denisakp/sentinel/internal/utils"
+	"strings"
+)
+
+// validateRequiredArgs validates required arguments for SQLite restore
+func validateRequiredArgs(sra *SqliteRestoreArgs) error {
+	if sra.Database == "" {
+		return fmt.Errorf("database path is missing")
+	}
+
+	// sqlite3 would read the path as an option
+	if strings.HasPrefix(sra.Database, "-") {
+		return fmt.Errorf("invalid database path: %s", sra.Database)
+	}
+
+	if sra.File == "" {
+		return fmt.Errorf("backup file is missing")
+	}
+
+	if !utils.PathExists(sra.File) || utils.IsDirectory(sra.File) {
+		return fmt.Errorf("backup file %s does not exist", sra.File)
+	}
+
+	return nil
+}
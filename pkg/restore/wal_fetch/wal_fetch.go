@@ -0,0 +1,112 @@
+package wal_fetch
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/denisakp/sentinel/internal/backup"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/encryption"
+	"github.com/denisakp/sentinel/internal/storage"
+	"github.com/denisakp/sentinel/internal/utils"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type WalFetchArgs struct {
+	Name          string          // name of the WAL file to fetch, %f of the restore_command
+	Destination   string          // path the WAL file is written to, %p of the restore_command
+	EncryptionKey []byte          // AES-256 key the WAL file was encrypted with by wal-push, nil when it was not
+	Storage       *storage.Params // Storage parameters
+}
+
+// Fetch fetches a WAL file archived by wal-push from the storage, decrypts and decompresses it
+// to the destination. It is meant to be used as the restore_command, which must fail when
+// the file is not archived: PostgresSQL then considers the end of the archive reached.
+func Fetch(wfa *WalFetchArgs) error {
+	if err := validateRequiredArgs(wfa); err != nil {
+		return err
+	}
+
+	// get the storage handler
+	storageHandler, err := storage.NewStorage(wfa.Storage)
+	if err != nil {
+		return err
+	}
+
+	// get the backup path
+	backupPath, err := storageHandler.GetBackupPath(wfa.Storage.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	resource := utils.FullPath(backupPath, backup.WalArchiveName(wfa.Name, wfa.Storage.Compression, wfa.EncryptionKey != nil))
+
+	exists, err := storageHandler.Exists(resource)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("WAL file %s is not archived", wfa.Name)
+	}
+
+	archived, err := storageHandler.ReadBackup(resource)
+	if err != nil {
+		return err
+	}
+
+	if wfa.EncryptionKey != nil {
+		if archived, err = encryption.Decrypt(archived, wfa.EncryptionKey); err != nil {
+			return fmt.Errorf("failed to decrypt WAL file - %w", err)
+		}
+	}
+
+	reader, err := compression.NewReader(bytes.NewReader(archived), wfa.Storage.Compression)
+	if err != nil {
+		return fmt.Errorf("failed to decompress WAL file - %w", err)
+	}
+	defer reader.Close()
+
+	return writeFile(wfa.Destination, reader)
+}
+
+// writeFile writes the WAL file next to the destination and renames it once complete,
+// so that PostgresSQL never reads a partially written file
+func writeFile(destination string, reader io.Reader) error {
+	file, err := os.CreateTemp(filepath.Dir(destination), ".sentinel-wal-*")
+	if err != nil {
+		return fmt.Errorf("failed to create WAL file - %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, reader); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write WAL file - %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write WAL file - %w", err)
+	}
+
+	if err := os.Rename(file.Name(), destination); err != nil {
+		return fmt.Errorf("failed to write WAL file - %w", err)
+	}
+
+	return nil
+}
+
+func validateRequiredArgs(wfa *WalFetchArgs) error {
+	if err := backup.ValidateWalFileName(wfa.Name); err != nil {
+		return err
+	}
+
+	if wfa.Destination == "" {
+		return fmt.Errorf("destination path is required")
+	}
+
+	if storage.IsDatedLayout(wfa.Storage.Layout) {
+		return fmt.Errorf("the WAL cannot be fetched from a layout depending on the date: %s", wfa.Storage.Layout)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package wal_fetch
+
+import (
+	"bytes"
+	"github.com/denisakp/sentinel/internal/compression"
+	"github.com/denisakp/sentinel/internal/encryption"
+	"github.com/denisakp/sentinel/internal/storage"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	archiveDir, walDir := t.TempDir(), t.TempDir()
+	params := &storage.Params{StorageType: "local", LocalPath: archiveDir, Compression: "zstd"}
+
+	// archive a segment the way wal-push does
+	file, err := os.Create(filepath.Join(archiveDir, "000000010000000000000001.zst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := compression.NewWriter(file, "zstd", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Write([]byte("segment")); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	destination := filepath.Join(walDir, "RECOVERYXLOG")
+	if err := Fetch(&WalFetchArgs{Name: "000000010000000000000001", Destination: destination, Storage: params}); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	got, err := os.ReadFile(destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "segment" {
+		t.Errorf("Fetch() got = %q, want %q", got, "segment")
+	}
+
+	// the end of the archive
+	if err := Fetch(&WalFetchArgs{Name: "000000010000000000000002", Destination: destination, Storage: params}); err == nil {
+		t.Errorf("Fetch() of a segment not archived, error expected")
+	}
+
+	entries, err := os.ReadDir(walDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Fetch() left %d files in the destination directory, want 1", len(entries))
+	}
+}
+
+func TestFetch_encrypted(t *testing.T) {
+	archiveDir, walDir := t.TempDir(), t.TempDir()
+	params := &storage.Params{StorageType: "local", LocalPath: archiveDir, Compression: "none"}
+	key := bytes.Repeat([]byte{7}, 32)
+
+	// archive a segment the way wal-push does
+	encrypted, err := encryption.Encrypt([]byte("segment"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(archiveDir, "000000010000000000000001.enc"), encrypted, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	destination := filepath.Join(walDir, "RECOVERYXLOG")
+	if err := Fetch(&WalFetchArgs{Name: "000000010000000000000001", Destination: destination, EncryptionKey: key, Storage: params}); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	got, err := os.ReadFile(destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "segment" {
+		t.Errorf("Fetch() got = %q, want %q", got, "segment")
+	}
+
+	if err := Fetch(&WalFetchArgs{Name: "000000010000000000000001", Destination: destination, EncryptionKey: bytes.Repeat([]byte{8}, 32), Storage: params}); err == nil {
+		t.Errorf("Fetch() with another key, error expected")
+	}
+}